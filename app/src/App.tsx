@@ -9,6 +9,7 @@ import { useEffect } from "react";
 import { Button } from "./components/Button";
 import { isLoggedIn } from "./storage/UserStorage";
 import { LoginJSONResponse } from "./types/User";
+import { ErrorCodes } from "./util/errorCodes";
 
 interface JWTPayload {
   email: string;
@@ -56,20 +57,20 @@ const Login: React.FC = () => {
     const fJson: LoginJSONResponse = await f.json();
 
     //TODO: test
-    if (fJson.data.error === "too many failed login attempts") {
+    if (fJson.code === ErrorCodes.ERROR_TOO_MANY_LOGIN_ATTEMPTS) {
       swal({
         icon: "error",
         title: "Login failed",
         text: "You have too many failed login attempts! Please wait 10 Minutes",
       });
-    } else if (fJson.message === "fail") {
+    } else if (fJson.code !== ErrorCodes.SUCCESS) {
       setError({ email: true, password: true });
-    } else if (fJson.message === "ok" && fJson.data.otp) {
+    } else if (fJson.data.otp) {
       history.push({
         pathname: "/twofactorauthentication",
         state: { email: email },
       });
-    } else if (fJson.message === "ok" && !fJson.data.otp) {
+    } else {
       const secretId = getSecretIdByJwtToken(fJson.data.token);
       if (secretId == null) {
         swal({
@@ -90,8 +91,6 @@ const Login: React.FC = () => {
       setError({ email: false, password: false });
       history.push("/dashboard");
       localStorage.setItem("authenticated", "true");
-    } else {
-      setError({ email: true, password: true });
     }
     //update loading state
     setLoading(false);
@@ -16,7 +16,7 @@ const BackupCodeLogin: React.FC = () => {
   const [loading, setLoading] = useState<boolean>(false);
   const [canReset, setCanReset] = useState({
     canReset: false,
-    code: "",
+    token: "",
     owner: "",
   });
 
@@ -37,7 +37,7 @@ const BackupCodeLogin: React.FC = () => {
   const verifyBackupCode = async (event: any) => {
     event.preventDefault();
     setLoading(true);
-    setCanReset({ canReset: false, code: "", owner: "" });
+    setCanReset({ canReset: false, token: "", owner: "" });
 
     const response = await fetch(`${API_URL}/backupcodes`, {
       method: "POST",
@@ -67,7 +67,7 @@ const BackupCodeLogin: React.FC = () => {
     }
     setCanReset({
       canReset: true,
-      code: backupCode,
+      token: fJson.data.token,
       owner: email,
     });
     swal({
@@ -99,7 +99,7 @@ const BackupCodeLogin: React.FC = () => {
       cache: "no-cache",
       credentials: "include",
       body: JSON.stringify({
-        code: canReset.code,
+        token: canReset.token,
         owner: canReset.owner,
         password: password,
       }),
@@ -118,11 +118,6 @@ const BackupCodeLogin: React.FC = () => {
       });
       return;
     }
-    setCanReset({
-      canReset: true,
-      code: backupCode,
-      owner: email,
-    });
     swal({
       icon: "success",
       title: "Successfully reset your password!",
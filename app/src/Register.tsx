@@ -5,6 +5,7 @@ import swal from "sweetalert";
 import clsx from "clsx";
 import { useHistory } from "react-router-dom";
 import { Button } from "./components/Button";
+import { ErrorCodes } from "./util/errorCodes";
 
 interface DataResponse {
   created: "true" | "false";
@@ -15,7 +16,7 @@ interface DataResponse {
 }
 
 interface LoginJSONResponse {
-  code: string;
+  code: number;
   message: string;
   data: DataResponse;
 }
@@ -60,24 +61,19 @@ const Register: React.FC = () => {
       }),
     });
     const fJson: LoginJSONResponse = await f.json();
-    if (fJson.data.error === "email is already being used") {
+    if (fJson.code === ErrorCodes.ERROR_EMAIL_ALREADY_TAKEN) {
       swal({
         icon: "error",
         title: "Email is already being used!",
         text: "Please try another email!",
       });
-    } else if (
-      fJson.data.error != null &&
-      fJson.data.error.includes("32 characters")
-    ) {
+    } else if (fJson.code === ErrorCodes.ERROR_USERNAME_TOO_LONG) {
       swal({
         icon: "error",
         title: "Username has to be shorter!",
         text: "Username can't be longer than 32 characters!",
       });
-    } else if (fJson.message === "fail") {
-      setError({ email: true, password: true, username: true });
-    } else if (fJson.message === "ok") {
+    } else if (fJson.code === ErrorCodes.SUCCESS) {
       //display modal
       swal({
         icon: "success",
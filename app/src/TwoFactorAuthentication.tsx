@@ -9,6 +9,7 @@ import { useEffect } from "react";
 import { Button } from "./components/Button";
 import { isLoggedIn } from "./storage/UserStorage";
 import { TOTPJSONResponse } from "./types/TwoFactorAuthentication";
+import { ErrorCodes } from "./util/errorCodes";
 
 interface JWTPayload {
   email: string;
@@ -60,16 +61,14 @@ const TwoFactorAuthentication: React.FC = () => {
         otp: otp,
         login_after: true,
         email: location.state.email,
-        enable_after: false,
       }),
     });
     const fJson: TOTPJSONResponse = await f.json();
     console.log(fJson.data);
 
-    if (fJson.message === "fail") {
+    if (fJson.code !== ErrorCodes.SUCCESS) {
       setError(true);
     } else if (
-      fJson.message === "ok" &&
       fJson.data.verified === "true" &&
       fJson.data.success === "true"
     ) {
@@ -4,6 +4,7 @@ import swal from "sweetalert";
 import useHasUnreadNotifications from "../hooks/useHasUnreadNotifications";
 import { LogoutResponse } from "../types/User";
 import { API_URL } from "../util/constants";
+import { ErrorCodes } from "../util/errorCodes";
 
 //TODO: Fix Mobile Sidebar
 //TODO: Fix Sidebar when the page is scrollable
@@ -24,7 +25,7 @@ export const Sidebar: React.FC = ({}) => {
       body: JSON.stringify({ logout_everyone: false }),
     });
     const fJson: LogoutResponse = await response.json();
-    if (fJson.message !== "fail" && fJson.data.success === "true") {
+    if (fJson.code === ErrorCodes.SUCCESS && fJson.data.success === "true") {
       swal({
         icon: "success",
         title: "Successfully logged out!",
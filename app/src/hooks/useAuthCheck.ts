@@ -1,6 +1,7 @@
 import { useState, useEffect } from "react";
 import { AuthCheckResponse } from "../types/User";
 import { API_URL } from "../util/constants";
+import { ErrorCodes } from "../util/errorCodes";
 
 const useAuthCheck = () => {
   const [status, setStatus] = useState<"success" | "fail" | "pending">(
@@ -20,11 +21,7 @@ const useAuthCheck = () => {
       });
       const fJson: AuthCheckResponse = await response.json();
 
-      if (
-        fJson.data.success !== "true" ||
-        fJson.message === "fail" ||
-        fJson.message === "not authorized to access this route"
-      ) {
+      if (fJson.code !== ErrorCodes.SUCCESS || fJson.data.success !== "true") {
         return setStatus("fail");
       } else {
         return setStatus("success");
@@ -7,6 +7,7 @@ import { Sidebar } from "../../components/Sidebar";
 import useAuthCheck from "../../hooks/useAuthCheck";
 import { GenerateBackupCodesResponse } from "../../types/BackupCodes";
 import { API_URL } from "../../util/constants";
+import { ErrorCodes } from "../../util/errorCodes";
 import useLoadBackupCodes from "./hooks/useLoadBackupCodes";
 
 const BackupCodes: React.FC = () => {
@@ -19,6 +20,7 @@ const BackupCodes: React.FC = () => {
     setBackupCodes,
     setLoadingBackupCodes,
     has,
+    remaining,
   } = useLoadBackupCodes(refreshCodes);
 
   if (loadingBackupCodes) return <Loading withSidebar />;
@@ -48,6 +50,8 @@ const BackupCodes: React.FC = () => {
       const codesToArray = c.split(",");
       setBackupCodes({ codes: codesToArray });
       if (regenerate) setRefreshCodes(true);
+    } else if (fJson.code === ErrorCodes.ERROR_BACKUP_CODES_ALREADY_EXIST) {
+      setRefreshCodes(true);
     }
     setLoadingBackupCodes(false);
   };
@@ -83,7 +87,17 @@ const BackupCodes: React.FC = () => {
       <div className="container mx-auto py-10 md:w-4/5 w-11/12">
         <div className="bg-white px-4 md:px-10 pt-5 md:pt-7 pb-5 overflow-y-auto">
           <h1 className="text-4xl text-center">Backup Codes</h1>
-          {backupCodes.codes.length <= 0 && !loadingBackupCodes && (
+          {has && backupCodes.codes.length <= 0 && (
+            <p className="text-center mt-4">
+              You have {remaining} unused Backup Codes left.
+            </p>
+          )}
+          {backupCodes.codes.length > 0 && (
+            <p className="text-center mt-4">
+              Save these codes now, they won't be shown again.
+            </p>
+          )}
+          {!has && backupCodes.codes.length <= 0 && !loadingBackupCodes && (
             <Button
               text="Generate Backup Codes"
               loadingText="Generating Backup Codes..."
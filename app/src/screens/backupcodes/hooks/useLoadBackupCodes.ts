@@ -13,6 +13,7 @@ const useLoadBackupCodes = (condition: boolean) => {
     codes: [],
   });
   const [has, setHas] = useState<boolean>(false);
+  const [remaining, setRemaining] = useState<number>(0);
 
   const loadBackupCodes = async () => {
     const response = await fetch(`${API_URL}/backupcodes`, {
@@ -27,15 +28,12 @@ const useLoadBackupCodes = (condition: boolean) => {
     if (fJson.data.has && fJson.data.has === "false") {
       setHas(false);
     } else if (fJson.data.success === "true") {
-      const c = fJson.data.codes.replace("{", "").replace("}", "");
-      const codesToArray = c.split(",");
-      console.log(codesToArray);
-      setBackupCodes({ codes: codesToArray });
+      // codes are only shown once after generating them
+      setRemaining(parseInt(fJson.data.remaining));
       setHas(true);
     } else {
       setHas(false);
     }
-    console.log(fJson.data.codes);
     setLoadingBackupCodes(false);
   };
 
@@ -53,6 +51,7 @@ const useLoadBackupCodes = (condition: boolean) => {
     setBackupCodes,
     setLoadingBackupCodes,
     has,
+    remaining,
   };
 };
 
@@ -7,9 +7,8 @@ import { Button } from "../../components/Button";
 import { Loading } from "../../components/Loading";
 import { Sidebar } from "../../components/Sidebar";
 import useAuthCheck from "../../hooks/useAuthCheck";
-import { getUser } from "../../storage/UserStorage";
 import { VerifyResponse } from "../../types/TwoFactorAuthentication";
-import { API_URL, TOTP_API_URL } from "../../util/constants";
+import { API_URL } from "../../util/constants";
 import { useLoadQRCode } from "./hooks/useLoadQRCode";
 
 interface Props {
@@ -43,23 +42,20 @@ const UpdateTwoFactorAuthentication: React.FC = () => {
     e.preventDefault();
     if (!status) return; //maybe display error message
     setLoading(true);
-    const user = await getUser();
-    const email = user.email;
-
-    const response = await fetch(TOTP_API_URL, {
-      method: "POST",
-      headers: {
-        "Content-Type": "application/json",
-        Accept: "application/json",
-      },
-      credentials: "include",
-      body: JSON.stringify({
-        email: email,
-        otp: otp,
-        login_after: false,
-        enable_after: true,
-      }),
-    });
+    const response = await fetch(
+      `${API_URL}/twofactorauthentication/confirm`,
+      {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/json",
+          Accept: "application/json",
+        },
+        credentials: "include",
+        body: JSON.stringify({
+          otp: otp,
+        }),
+      }
+    );
     const fJson: VerifyResponse = await response.json();
     if (
       fJson.code !== 200 ||
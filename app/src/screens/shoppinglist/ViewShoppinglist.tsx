@@ -279,7 +279,7 @@ const Viewdata: React.FC = ({}) => {
               : "Participants"}
           </h1>
           <br />
-          {!data?.is_participant && (
+          {!data?.data.is_participant && (
             <Button
               text="Delete Shoppinglist"
               loadingText="Deleting Shoppinglist..."
@@ -296,14 +296,14 @@ const Viewdata: React.FC = ({}) => {
             className="inline-flex sm:ml-3 mt-4 sm:mt-0 items-start justify-start px-6 py-3 bg-green-600 hover:bg-green-500 text-white focus:outline-none rounded"
             loading={creatingItem}
           />
-          {!data?.is_participant && (
+          {!data?.data.is_participant && (
             <Button
               text="Participants"
               onClick={() => history.push(`/list/participants/${id}`)}
               className="inline-flex sm:ml-3 mt-4 sm:mt-0 items-start justify-start px-6 py-3 bg-green-600 hover:bg-green-500 text-white focus:outline-none rounded"
             />
           )}
-          {data?.is_participant && (
+          {data?.data.is_participant && (
             <Button
               text="Leave"
               onClick={() => leaveShoppinglist()}
@@ -10,6 +10,7 @@ import {
 import { useLoadParticipants } from "./hooks/useLoadParticipants";
 import swal from "sweetalert";
 import { API_URL } from "../../../util/constants";
+import { ErrorCodes } from "../../../util/errorCodes";
 
 export interface Params {
   id: string;
@@ -74,10 +75,7 @@ const Participants: React.FC = () => {
         credentials: "include",
       });
       const fJson: AddParticipantResponse = await response.json();
-      if (
-        fJson.data &&
-        fJson.data.error === "participant is already included"
-      ) {
+      if (fJson.code === ErrorCodes.ERROR_PARTICIPANT_ALREADY_INCLUDED) {
         swal.close!();
         swal({
           icon: "error",
@@ -86,6 +84,16 @@ const Participants: React.FC = () => {
         setInvitingParticipant(false);
         return;
       }
+      if (fJson.code === ErrorCodes.ERROR_USER_NOT_FOUND) {
+        swal.close!();
+        swal({
+          icon: "error",
+          title: "There is no account with this email",
+          text: "Only users with an account can be invited",
+        });
+        setInvitingParticipant(false);
+        return;
+      }
       setRefresh(true);
       setInvitingParticipant(false);
       setTimeout(() => setRefresh(false), 1000);
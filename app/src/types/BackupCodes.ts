@@ -5,13 +5,13 @@ export interface BackupCodesResponse {
 }
 
 interface BackupCodesResponseData {
-  codes: string;
+  remaining: string;
   success: "true" | "false";
   has: "true" | "false";
 }
 
 export interface GenerateBackupCodesResponse {
-  code: string;
+  code: number;
   message: string;
   data: GenerateBackupCodesResponseData;
 }
@@ -31,6 +31,7 @@ interface VerifyBackupCodeData {
   success: "true" | "false";
   ok: "true" | "false";
   error: string;
+  token: string;
 }
 
 export interface ResetPasswordBackupCode {
@@ -17,13 +17,13 @@ export interface ListResponseData {
   items: Item[];
   owner: string;
   participants: Participant[];
+  is_participant: boolean;
 }
 
 export interface ListResponse {
   message: string;
   data: ListResponseData;
   code: number;
-  is_participant: boolean;
 }
 
 export interface Item {
@@ -42,7 +42,7 @@ export interface TOTPDataResponse {
 }
 
 export interface TOTPJSONResponse {
-  code: string;
-  message: "fail" | "ok";
+  code: number;
+  message: string;
   data: TOTPDataResponse;
 }
@@ -29,8 +29,8 @@ export interface UpdateUserResponse {
 }
 
 export interface LoginJSONResponse {
-  code: string;
-  message: "fail" | "ok";
+  code: number;
+  message: string;
   data: DataResponse;
 }
 
@@ -47,7 +47,7 @@ export interface AuthDataResponse {
 }
 
 export interface AuthCheckResponse {
-  code: string;
+  code: number;
   message: string;
   data: AuthDataResponse;
 }
@@ -59,6 +59,6 @@ export interface LogoutDataResponse {
 
 export interface LogoutResponse {
   message: string;
-  code: string;
+  code: number;
   data: LogoutDataResponse;
 }
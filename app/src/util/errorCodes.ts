@@ -3,17 +3,6 @@ export enum ErrorCodes {
   ERROR = 500,
   INVALID_PARAMS = 400,
 
-  ERROR_EXIST_TAG = 10001,
-  ERROR_EXIST_TAG_FAIL = 10002,
-  ERROR_NOT_EXIST_TAG = 10003,
-  ERROR_GET_TAGS_FAIL = 10004,
-  ERROR_COUNT_TAG_FAIL = 10005,
-  ERROR_ADD_TAG_FAIL = 10006,
-  ERROR_EDIT_TAG_FAIL = 10007,
-  ERROR_DELETE_TAG_FAIL = 10008,
-  ERROR_EXPORT_TAG_FAIL = 10009,
-  ERROR_IMPORT_TAG_FAIL = 10010,
-
   ERROR_LIST_DOES_NOT_EXIST = 10011,
   ERROR_CHECK_EXIST_LIST_FAIL = 10012,
   ERROR_ADD_LIST_FAIL = 10013,
@@ -29,18 +18,95 @@ export enum ErrorCodes {
   ERROR_AUTH_TOKEN = 20003,
   ERROR_AUTH = 20004,
   ERROR_CREATING_ACCOUNT = 20006,
+  ERROR_SECRETID_IS_NOT_CORRECT = 20007,
+  ERROR_UPDATING_USER = 20008,
+  ERROR_ENCRYPTING_PASSWORD = 20009,
+  ERROR_WRONG_OLD_PASSWORD = 20010,
+
+  ERROR_RATE_LIMITER = 20011,
+  ERROR_RATELIMIT_TRY_LATER = 20012,
+
+  ERROR_GETTING_EMAIL_BY_JWT = 20013,
+  ERROR_RETRIEVING_USER_DATA = 20014,
+  ERROR_TOKEN_INVALID = 20015,
+  ERROR_WHILE_INVALIDATING_TOKEN = 20016,
+
+  ERROR_LIST_DOES_NOT_BELONG_TO_TOKEN = 20017,
+  ERROR_ID_IS_INVALID = 20018,
+
+  ERROR_NOT_AUTHORIZED = 20019,
+  ERROR_BINDING_JSON_DATA = 20020,
+
+  ERROR_SENDING_RESET_PASSWORD_EMAIL = 20021,
+  ERROR_VERIFYING_VERIFICATION_ID = 20022,
+
+  ERROR_GETTING_IP = 20023,
+  ERROR_GETTING_HTTPONLY_COOKIE = 20024,
+  ERROR_SETTING_SESSION_TOKEN = 20025,
+
+  ERROR_USERNAME_TOO_LONG = 20026,
+
+  ERROR_CHANING_TWOFACTORAUTHENTICATION_STATUS = 20027,
+  ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE = 20028,
+  ERROR_INVALIDATING_JWT_TOKENS = 20029,
+  ERROR_JWT_TOKEN_DOES_NOT_BELONG_TO_EMAIL = 20030,
+  ERROR_CONVERTING_KEY_TO_IMG = 20031,
+  ERROR_VERIFYING_OTP = 20032,
+  ERROR_CHECKING_IF_TOTP_IS_ENABLED = 20033,
+
+  ERROR_CHECKING_HAS_UNREAD_NOTIFICATIONS = 20034,
+
+  ERROR_DEACTIVATING_ACCOUNT = 20035,
+  ERROR_REAUTHENTICATION_FAILED = 20036,
+
+  ERROR_PASSWORD_TOO_SHORT = 20037,
+  ERROR_PASSWORD_MISSING_CHARACTER_CLASSES = 20038,
+  ERROR_PASSWORD_CONTAINS_EMAIL = 20039,
+  ERROR_PASSWORD_BREACHED = 20040,
+
+  ERROR_GETTING_HASH_REPORT = 20041,
+  ERROR_GETTING_LOGIN_EVENTS = 20042,
+
+  ERROR_TOO_MANY_LOGIN_ATTEMPTS = 20043,
+  ERROR_SENDING_UNLOCK_EMAIL = 20044,
+  ERROR_UNLOCK_TOKEN_INVALID = 20045,
+
+  ERROR_BEGINNING_WEBAUTHN_REGISTRATION = 20046,
+  ERROR_FINISHING_WEBAUTHN_REGISTRATION = 20047,
+  ERROR_BEGINNING_WEBAUTHN_LOGIN = 20048,
+  ERROR_VERIFYING_WEBAUTHN_ASSERTION = 20049,
+  ERROR_GETTING_WEBAUTHN_CREDENTIALS = 20050,
+  ERROR_DELETING_WEBAUTHN_CREDENTIAL = 20051,
+  ERROR_CHECKING_IF_WEBAUTHN_IS_ENABLED = 20052,
+
+  ERROR_SENDING_EMAIL_OTP = 20053,
+  ERROR_TOO_MANY_OTP_ATTEMPTS = 20054,
 
-  ERROR_RATE_LIMITER = 20007,
-  ERROR_RATELIMIT_TRY_LATER = 20008,
+  ERROR_REAUTHENTICATION_REQUIRED = 20055,
 
-  ERROR_GETTING_EMAIL_BY_JWT = 20009,
-  ERROR_RETRIEVING_USER_DATA = 20010,
-  ERROR_TOKEN_INVALID = 20011,
-  ERROR_WHILE_INVALIDATING_TOKEN = 20012,
+  ERROR_REQUESTING_EMAIL_CHANGE = 20056,
+  ERROR_EMAIL_ALREADY_TAKEN = 20057,
+  ERROR_EMAIL_CHANGE_TOKEN_INVALID = 20058,
+  ERROR_CHANGING_EMAIL = 20059,
 
-  ERROR_LIST_DOES_NOT_BELONG_TO_TOKEN = 20013,
-  ERROR_ID_IS_INVALID = 20014,
+  ERROR_USER_NOT_FOUND = 20060,
+  ERROR_EMAIL_INVALID = 20061,
+  ERROR_WRONG_PASSWORD = 20062,
+  ERROR_OFFSET_INVALID = 20063,
+  ERROR_WEBAUTHN_CREDENTIAL_NOT_FOUND = 20064,
 
-  ERROR_NOT_AUTHORIZED = 20015,
-  ERROR_BINDING_JSON_DATA = 20016,
+  ERROR_GETTING_NOTIFICATIONS = 20065,
+  ERROR_DELETING_NOTIFICATION = 20066,
+  ERROR_MARKING_NOTIFICATIONS_AS_READ = 20067,
+  ERROR_PARTICIPANT_ALREADY_INCLUDED = 20068,
+  ERROR_ADDING_PARTICIPANT = 20069,
+  ERROR_EDITING_ITEM = 20070,
+  ERROR_GENERATING_BACKUP_CODES = 20071,
+  ERROR_GETTING_BACKUP_CODES = 20072,
+  ERROR_VERIFYING_BACKUP_CODE = 20073,
+  ERROR_GETTING_PARTICIPANTS = 20074,
+  ERROR_UPDATING_PARTICIPANT = 20075,
+  ERROR_ADDING_ITEM = 20076,
+  ERROR_DELETING_ITEM = 20077,
+  ERROR_BACKUP_CODES_ALREADY_EXIST = 20078,
 }
@@ -0,0 +1,35 @@
+// errorcodes writes every error code of pkg/e with its name and message as JSON, for the translations of the
+// frontend.
+//
+//	errorcodes              prints the registry
+//	errorcodes -o <file>    writes it to file
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/urento/shoppinglist/pkg/e"
+)
+
+func main() {
+	output := flag.String("o", "", "file to write the registry to, defaults to stdout")
+	flag.Parse()
+
+	registry, err := e.RegistryJSON()
+	if err != nil {
+		log.Fatalf("Error while encoding the registry: %s", err)
+	}
+	registry = append(registry, '\n')
+
+	if *output == "" {
+		_, err = os.Stdout.Write(registry)
+	} else {
+		err = ioutil.WriteFile(*output, registry, 0644)
+	}
+	if err != nil {
+		log.Fatalf("Error while writing the registry: %s", err)
+	}
+}
@@ -0,0 +1,77 @@
+// migrate applies or reverts the versioned database migrations.
+//
+//	migrate up          applies all pending migrations
+//	migrate down [n]    reverts the last n migrations (default 1)
+//	migrate status      lists all migrations and when they were applied
+//
+// Every command holds the migration lock, so it's safe to run while instances of the server are starting.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	// the arguments are the command, so the config can only be set with CONFIG_FILE and the environment
+	cfg, err := config.Load(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	models.Connect(cfg)
+
+	switch os.Args[1] {
+	case "up":
+		count, err := models.MigrateUp()
+		if err != nil {
+			log.Fatalf("Error while applying migrations: %s", err)
+		}
+		log.Printf("Applied %d migrations", count)
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			n, err := strconv.Atoi(os.Args[2])
+			if err != nil || n <= 0 {
+				usage()
+			}
+			steps = n
+		}
+
+		count, err := models.MigrateDown(steps)
+		if err != nil {
+			log.Fatalf("Error while reverting migrations: %s", err)
+		}
+		log.Printf("Reverted %d migrations", count)
+	case "status":
+		status, err := models.GetMigrationStatus()
+		if err != nil {
+			log.Fatalf("Error while reading the migration status: %s", err)
+		}
+
+		for _, migration := range status {
+			applied := "pending"
+			if migration.AppliedAt > 0 {
+				applied = time.Unix(migration.AppliedAt, 0).Format(time.RFC3339)
+			}
+			fmt.Printf("%4d  %-32s %s\n", migration.Version, migration.Name, applied)
+		}
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate up | down [steps] | status")
+	os.Exit(2)
+}
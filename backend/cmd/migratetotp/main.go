@@ -0,0 +1,31 @@
+// migratetotp copies the totp secrets that are only stored in redis to postgres.
+// Secrets that are already persisted are left untouched, so it's safe to run it multiple times. The server does the
+// same on start, this is for importing without a restart.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	models.Setup(cfg)
+	cache.Setup(cfg)
+
+	migrated, total, err := models.ImportCachedTOTPSecrets(context.Background())
+	if err != nil {
+		log.Fatalf("Error while migrating the totp secrets: %s", err)
+	}
+
+	log.Printf("Migrated %d of %d totp secrets", migrated, total)
+}
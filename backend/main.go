@@ -1,21 +1,56 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/urento/shoppinglist/middleware/ratelimiter"
+	"github.com/urento/shoppinglist/middleware/reauth"
 	"github.com/urento/shoppinglist/models"
 	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/emailotp"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"github.com/urento/shoppinglist/pkg/mailer"
+	"github.com/urento/shoppinglist/pkg/metrics"
+	"github.com/urento/shoppinglist/pkg/totp"
+	"github.com/urento/shoppinglist/pkg/tracing"
 	"github.com/urento/shoppinglist/pkg/util"
+	"github.com/urento/shoppinglist/pkg/webauthn"
 	routers "github.com/urento/shoppinglist/router"
+	"go.uber.org/zap"
 )
 
-func init() {
-	models.Setup()
-	util.Setup()
-	ratelimiter.Setup()
-	cache.Setup(false)
+func setup(cfg *config.Config) {
+	models.Setup(cfg)
+	util.Setup(cfg)
+	reauth.Setup(cfg)
+	cache.Setup(cfg)
+	totp.Setup(cfg)
+	emailotp.Setup(cfg)
+	err := mailer.Setup(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	err = webauthn.Setup(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	// secrets that are only in redis would let their users skip the second factor
+	migrated, total, err := models.ImportCachedTOTPSecrets(context.Background())
+	if err != nil {
+		logging.L().Fatal("error while importing the totp secrets from redis", zap.Error(err))
+	}
+	if migrated > 0 {
+		logging.L().Info("imported totp secrets from redis", zap.Int("imported", migrated), zap.Int("total", total))
+	}
 }
 
 //TODO: Check JWT stuff
@@ -25,16 +60,155 @@ func init() {
 //TODO: Revalidate JWT Token when invalid
 
 func main() {
-	routersInit := routers.InitRouter()
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = logging.Setup(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer logging.Sync()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		logging.L().Fatal("error while setting up tracing", zap.Error(err))
+	}
+
+	setup(cfg)
+
+	routersInit := routers.InitRouter(cfg, models.NewGormStores())
 	maxHeaderBytes := 1 << 20
 
 	server := &http.Server{
-		Addr:           ":8080",
+		Addr:           cfg.ListenAddr,
 		Handler:        routersInit,
 		MaxHeaderBytes: maxHeaderBytes,
+		ReadTimeout:    cfg.Server.ReadTimeout(),
+		WriteTimeout:   cfg.Server.WriteTimeout(),
+		IdleTimeout:    cfg.Server.IdleTimeout(),
 	}
 
-	log.Printf("Listening on port: %s", ":8080")
+	metricsServer := &http.Server{
+		Addr:         cfg.Metrics.ListenAddr,
+		Handler:      routers.InitMetricsRouter(),
+		ReadTimeout:  cfg.Server.ReadTimeout(),
+		WriteTimeout: cfg.Server.WriteTimeout(),
+		IdleTimeout:  cfg.Server.IdleTimeout(),
+	}
+
+	// cancelled on shutdown, so the background jobs stop together with their queries
+	jobs, stopJobs := context.WithCancel(context.Background())
+	go purgeDeactivatedAccounts(jobs)
+	go refreshMetrics(jobs, cfg.Metrics.RefreshInterval())
+
+	serverErr := make(chan error, 2)
+	for _, srv := range []*http.Server{server, metricsServer} {
+		go func(srv *http.Server) {
+			logging.L().Info("listening", zap.String("addr", srv.Addr))
+			serverErr <- fmt.Errorf("%s: %w", srv.Addr, srv.ListenAndServe())
+		}(srv)
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErr:
+		logging.L().Fatal("error while listening", zap.Error(err))
+	case sig := <-quit:
+		logging.L().Info("shutting down", zap.String("signal", sig.String()))
+	}
+
+	routers.Drain()
+
+	stopJobs()
+	shutdown(server, metricsServer, shutdownTracing, cfg.Server.ShutdownTimeout())
+}
+
+// shutdown stops accepting connections, waits up to timeout for the in-flight requests and closes the database and
+// redis connections afterwards. The metrics server stops after the drain, so the last requests can still be scraped.
+// The buffered spans are flushed last, so the spans of the drained requests are exported too
+func shutdown(server, metricsServer *http.Server, shutdownTracing func(context.Context) error, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		logging.L().Error("error while draining connections", zap.Error(err))
+	}
+
+	if err := metricsServer.Shutdown(ctx); err != nil {
+		logging.L().Error("error while stopping the metrics server", zap.Error(err))
+	}
+
+	if err := models.Close(); err != nil {
+		logging.L().Error("error while closing the database", zap.Error(err))
+	}
+
+	if err := cache.Close(); err != nil {
+		logging.L().Error("error while closing redis", zap.Error(err))
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logging.L().Error("error while flushing spans", zap.Error(err))
+	}
+
+	logging.L().Info("server stopped")
+}
+
+func purgeDeactivatedAccounts(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		purged, err := models.PurgeDeactivatedAccounts(ctx)
+		if err != nil {
+			logging.L().Error("error while purging deactivated accounts", zap.Error(err))
+			continue
+		}
+
+		if purged > 0 {
+			logging.L().Info("deleted deactivated accounts", zap.Int("count", purged))
+		}
+	}
+}
+
+// refreshMetrics counts the users and shoppinglists for the gauges on /metrics
+func refreshMetrics(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := updateTotals(ctx); err != nil {
+			logging.L().Error("error while counting the totals for the metrics", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func updateTotals(ctx context.Context) error {
+	lists, err := models.CountShoppinglists(ctx)
+	if err != nil {
+		return err
+	}
+
+	users, err := models.CountUsers(ctx)
+	if err != nil {
+		return err
+	}
 
-	server.ListenAndServe()
+	metrics.SetTotals(lists, users)
+	return nil
 }
@@ -0,0 +1,32 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/pkg/logging"
+)
+
+// Deadline cancels the context of the request after timeout, so the database and redis calls made with
+// c.Request.Context() give up instead of running on. The context is also cancelled when the client disconnects.
+// Requests that ran out of time without writing a response get a 503
+func Deadline(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			logging.FromGin(c).Warn("request deadline exceeded")
+
+			if !c.Writer.Written() {
+				c.AbortWithStatus(http.StatusServiceUnavailable)
+			}
+		}
+	}
+}
@@ -1,20 +1,20 @@
 package jwt
 
 import (
-	"log"
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 	"github.com/urento/shoppinglist/pkg/cache"
 	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/logging"
 	"github.com/urento/shoppinglist/pkg/util"
 )
 
 func JWT() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var code int
+		var email string
 
 		code = e.SUCCESS
 		token, err := util.GetCookie(c)
@@ -25,17 +25,18 @@ func JWT() gin.HandlerFunc {
 		if token == "" {
 			code = e.ERROR_NOT_AUTHORIZED
 		} else {
-			tokenValid, err := cache.IsTokenValid(token)
+			tokenValid, err := cache.IsTokenValid(c.Request.Context(), token)
 			if err != nil || !tokenValid {
-				log.Print(err)
+				logging.Error(c, err)
 				code = e.ERROR_AUTH_CHECK_TOKEN_FAIL
 			}
 
 			if tokenValid {
 				data, parseErr := util.ParseToken(token)
-				ok, err := cache.VerifySecretId(data.Email, data.SecretId)
+				email = data.Email
+				ok, err := cache.VerifySecretId(c.Request.Context(), data.Email, data.SecretId)
 				if err != nil || !ok {
-					log.Print(err)
+					logging.Error(c, err)
 					code = e.ERROR_VERIFYING_VERIFICATION_ID
 				}
 
@@ -70,12 +71,13 @@ func JWT() gin.HandlerFunc {
 			return
 		}
 
+		logging.AddFields(c, logging.User(email))
+
 		c.Next()
 	}
 }
 
 func SetCookie(ctx *gin.Context, token string) error {
-	domain := os.Getenv("DOMAIN")
-	ctx.SetCookie("token", token, 24*60*60, "/", domain, util.IsProd(), true)
+	util.SetCookie(ctx, "token", token, 24*60*60)
 	return nil
 }
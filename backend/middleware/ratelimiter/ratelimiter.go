@@ -3,79 +3,28 @@ package ratelimiter
 import (
 	"context"
 	"errors"
-	"log"
-	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis/v8"
-	"github.com/joho/godotenv"
-	util "github.com/urento/shoppinglist/pkg"
+	"github.com/urento/shoppinglist/pkg/cache"
 )
 
-var rdb *redis.Client
-
-func Setup() {
-	var err error
-	if util.PROD {
-		err = godotenv.Load()
-	} else if util.GITHUB_TESTING {
-		err = nil
-	} else if util.LOCAL_TESTING {
-		err = godotenv.Load("../../.env")
-	} else {
-		err = godotenv.Load()
-	}
+var errLimitReached = errors.New("limit reached")
 
+// int64 = requests in the current window
+func GetAndUpdateLimit(c *gin.Context, limit int64, window time.Duration) (int64, error) {
+	count, err := cache.IncrRateLimit(c.Request.Context(), c.ClientIP(), window)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
 
-	redisPassword := os.Getenv("REDIS_PASSWORD")
-
-	if redisPassword == "testing" {
-		rdb = redis.NewClient(&redis.Options{
-			Addr: os.Getenv("REDIS_ADDR"),
-			DB:   0,
-		})
-	} else {
-		rdb = redis.NewClient(&redis.Options{
-			Addr:     os.Getenv("REDIS_ADDR"),
-			Password: redisPassword,
-			DB:       0,
-		})
-	}
-}
-
-//int64 = remaining
-func GetAndUpdateLimit(c *gin.Context) (int64, error) {
-	ctx := context.Background()
-	var limit int64
-	ip := c.ClientIP()
-
-	val, err := rdb.Get(ctx, "ratelimit:"+ip).Int64()
-	if err != nil || err == redis.Nil {
-		//first time sending a request so create a new entry
-		err = rdb.Set(ctx, "ratelimit:"+ip, 1, 180*time.Second).Err()
-		if err != nil {
-			return 0, err
-		}
-	} else {
-		//is already in the database; just incr the old number
-		if val >= 300 {
-			return 0, errors.New("limit reached")
-		}
-		limit = val + 1
-		err = rdb.Set(ctx, "ratelimit:"+ip, limit, redis.KeepTTL).Err()
-		if err != nil {
-			return 0, err
-		}
+	if count > limit {
+		return limit, errLimitReached
 	}
 
-	return limit, nil
+	return count, nil
 }
 
-func ResetLimit(ip string) error {
-	err := rdb.Del(context.Background(), "ratelimit:"+ip).Err()
-	return err
+func ResetLimit(ctx context.Context, ip string) error {
+	return cache.ResetRateLimit(ctx, ip)
 }
@@ -1,11 +1,14 @@
 package ratelimiter
 
 import (
-	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"github.com/urento/shoppinglist/pkg/metrics"
+	"go.uber.org/zap"
 )
 
 type Response struct {
@@ -13,17 +16,19 @@ type Response struct {
 	Message string `json:"message"`
 }
 
-func Ratelimiter() gin.HandlerFunc {
+// Ratelimiter allows limit requests per ip in every window
+func Ratelimiter(limit int64, window time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		remaining, err := GetAndUpdateLimit(c)
+		requests, err := GetAndUpdateLimit(c, limit, window)
 
 		//TODO: make exceptions for some routes
 
-		c.Header("X-Ratelimit-Remaining", strconv.FormatInt(300-remaining, 10))
-		c.Header("X-Ratelimit-Limit", "300")
+		c.Header("X-Ratelimit-Remaining", strconv.FormatInt(limit-requests, 10))
+		c.Header("X-Ratelimit-Limit", strconv.FormatInt(limit, 10))
 
-		if err != nil && err.Error() == "limit reached" {
-			log.Print(err)
+		if err == errLimitReached {
+			metrics.ObserveRateLimitRejection()
+			logging.FromGin(c).Warn("rate limit reached", zap.String("ip", c.ClientIP()))
 			c.JSON(http.StatusTooManyRequests, Response{
 				Error:   "Ratelimit reached!",
 				Message: "Try again later!",
@@ -33,7 +38,7 @@ func Ratelimiter() gin.HandlerFunc {
 		}
 
 		if err != nil {
-			log.Print(err)
+			logging.Error(c, err)
 			c.JSON(http.StatusInternalServerError, Response{
 				Error:   err.Error(),
 				Message: "We are currently unable to process your requests! Try again later!",
@@ -1,47 +1,52 @@
 package ratelimiter
 
 import (
+	"context"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/pkg/cache"
 )
 
 func TestRatelimitMiddleware(t *testing.T) {
-	Setup()
+	cache.UseStore(cache.NewMemoryStore())
 
 	r := gin.Default()
 
-	r.Use(Ratelimiter())
+	r.Use(Ratelimiter(300, 180*time.Second))
 
 	r.GET("/", func(c *gin.Context) {
 		c.String(200, "OK")
 	})
 
-	go r.Run(":9999")
-	//ip := GetOutboundIP(t)
-	ip := "localhost"
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ip := "192.0.2.1"
+	req.RemoteAddr = ip + ":1234"
 
-	c := &http.Client{}
 	for i := 0; i < 302; i++ {
-		resp, err := c.Get("http://" + ip + ":9999")
-		if err != nil {
-			t.Errorf("An error occurred while making the requests: %s", err)
-		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
 
-		limitHeader := resp.Header.Get("X-Ratelimit-Limit")
+		limitHeader := w.Header().Get("X-Ratelimit-Limit")
 
 		if limitHeader != "300" {
 			t.Error("Ratelimit-Limit header not set!")
 		}
 
-		if i == 301 && resp.StatusCode != 429 {
+		if i < 300 && w.Code != 200 {
+			t.Errorf("Request %d was limited", i)
+		}
+
+		if i == 301 && w.Code != 429 {
 			t.Error("Ratelimit not detected")
 		}
 	}
 
-	err := ResetLimit(ip)
+	err := ResetLimit(context.Background(), ip)
 	if err != nil {
 		t.Errorf("Error while resetting limit: %s", err)
 	}
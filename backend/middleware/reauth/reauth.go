@@ -0,0 +1,103 @@
+package reauth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/pkg/app"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/util"
+)
+
+const CookieName = "reauth"
+
+// defaultRoutes change credentials or the second factor and therefore need a recent login
+var defaultRoutes = []string{
+	"POST /api/v1/auth/resetpassword",
+	"POST /api/v1/auth/update",
+	"POST /api/v1/auth/email",
+	"POST /api/v1/twofactorauthentication",
+	"POST /api/v1/backupcodes/regenerate",
+	"POST /api/v1/backupcodes/generate",
+	"POST /api/v1/auth/webauthn/register/begin",
+	"POST /api/v1/auth/webauthn/register/finish",
+	"DELETE /api/v1/auth/webauthn/credentials/:id",
+}
+
+var (
+	ttl    = 5 * time.Minute
+	routes = parseRoutes(strings.Join(defaultRoutes, ","))
+)
+
+// Setup applies the configured ttl and replaces the default routes if others are configured
+func Setup(cfg *config.Config) {
+	ttl = cfg.Reauth.TTL()
+
+	if len(cfg.Reauth.Routes) > 0 {
+		routes = parseRoutes(strings.Join(cfg.Reauth.Routes, ","))
+	}
+}
+
+func TTL() time.Duration {
+	return ttl
+}
+
+func parseRoutes(list string) map[string]bool {
+	parsed := make(map[string]bool)
+	for _, route := range strings.Split(list, ",") {
+		fields := strings.Fields(route)
+		if len(fields) != 2 {
+			continue
+		}
+		parsed[strings.ToUpper(fields[0])+" "+fields[1]] = true
+	}
+	return parsed
+}
+
+// IsProtected reports if the route needs a recent re-authentication; path is the registered path, e.g. "/api/v1/item/:id"
+func IsProtected(method, path string) bool {
+	return routes[method+" "+path]
+}
+
+// RecentAuth rejects requests to the protected routes unless the reauth cookie belongs to the current session.
+// It has to run after the JWT middleware
+func RecentAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsProtected(c.Request.Method, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		err := verify(c)
+		if err != nil {
+			appGin := app.Gin{C: c}
+			appGin.Error(e.New(http.StatusForbidden, e.ERROR_REAUTHENTICATION_REQUIRED).WithDetail("reauth", "true"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func verify(c *gin.Context) error {
+	session, err := util.GetCookie(c)
+	if err != nil {
+		return err
+	}
+
+	email, err := cache.GetEmailByJWT(c.Request.Context(), session)
+	if err != nil {
+		return err
+	}
+
+	token, err := c.Cookie(CookieName)
+	if err != nil {
+		return err
+	}
+
+	return util.VerifyReauthToken(token, email, session)
+}
@@ -0,0 +1,48 @@
+package reauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/app"
+)
+
+func TestParseRoutes(t *testing.T) {
+	parsed := parseRoutes("post /api/v1/auth/update, DELETE /api/v1/item/:id,invalid")
+
+	Equal(t, 2, len(parsed))
+	True(t, parsed["POST /api/v1/auth/update"])
+	True(t, parsed["DELETE /api/v1/item/:id"])
+}
+
+func TestRecentAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(app.Errors())
+	r.Use(RecentAuth())
+	r.POST("/api/v1/auth/update", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+	r.GET("/api/v1/auth/user", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	t.Run("Unprotected Route", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/user", nil))
+
+		Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Protected Route without Reauth Cookie", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/update", nil))
+
+		Equal(t, http.StatusForbidden, w.Code)
+		Contains(t, w.Body.String(), `"reauth":"true"`)
+	})
+}
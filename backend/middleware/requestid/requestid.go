@@ -0,0 +1,52 @@
+package requestid
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	Header = "X-Request-ID"
+
+	contextKey = "request_id"
+	maxLength  = 128
+)
+
+// RequestID keeps the X-Request-ID of the proxy in front of the server or generates one, returns it in the response
+// and adds it to the logger of the request
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if !isValid(id) {
+			id = uuid.New().String()
+		}
+
+		c.Set(contextKey, id)
+		c.Header(Header, id)
+
+		logging.AddFields(c, zap.String("request_id", id))
+
+		c.Next()
+	}
+}
+
+// Get returns the id of the request, it's empty without the middleware
+func Get(c *gin.Context) string {
+	return c.GetString(contextKey)
+}
+
+// isValid only accepts printable ascii, so a client can't inject anything into the logs
+func isValid(id string) bool {
+	if len(id) <= 0 || len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
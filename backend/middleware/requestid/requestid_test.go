@@ -0,0 +1,56 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, Get(c))
+	})
+
+	request := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if len(id) > 0 {
+			req.Header.Set(Header, id)
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("TestPropagate", func(t *testing.T) {
+		w := request("lb-1234")
+
+		Equal(t, "lb-1234", w.Header().Get(Header))
+		Equal(t, "lb-1234", w.Body.String())
+	})
+
+	t.Run("TestGenerate", func(t *testing.T) {
+		first := request("").Header().Get(Header)
+		second := request("").Header().Get(Header)
+
+		Equal(t, 36, len(first))
+		NotEqual(t, first, second)
+	})
+
+	t.Run("TestInvalid", func(t *testing.T) {
+		for _, id := range []string{"id with spaces", "id\nwith\nnewlines", strings.Repeat("a", maxLength+1)} {
+			w := request(id)
+
+			NotEqual(t, id, w.Header().Get(Header))
+			Equal(t, 36, len(w.Header().Get(Header)))
+		}
+	})
+}
@@ -3,11 +3,16 @@ package models
 import (
 	"context"
 	"errors"
-	"log"
+	"net/http"
 	"net/mail"
+	"time"
 
 	"github.com/alexedwards/argon2id"
 	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/logging"
+	pwd "github.com/urento/shoppinglist/pkg/password"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +20,14 @@ import (
 * TODO: Cache Users and get user data from cache
  */
 
+var (
+	ErrUserNotFound     = e.New(http.StatusNotFound, e.ERROR_USER_NOT_FOUND)
+	ErrInvalidEmail     = e.New(http.StatusBadRequest, e.ERROR_EMAIL_INVALID)
+	ErrUsernameTooLong  = e.New(http.StatusBadRequest, e.ERROR_USERNAME_TOO_LONG)
+	ErrWrongPassword    = e.New(http.StatusUnauthorized, e.ERROR_WRONG_PASSWORD)
+	ErrWrongOldPassword = e.New(http.StatusBadRequest, e.ERROR_WRONG_OLD_PASSWORD)
+)
+
 type Auth struct {
 	Model
 
@@ -25,30 +38,55 @@ type Auth struct {
 	Password                string          `json:"password"`
 	Rank                    string          `json:"rank"` //admin or default
 	TwoFactorAuthentication bool            `json:"two_factor_authentication"`
+	TwoFactorMethod         string          `json:"two_factor_method" gorm:"default:totp"`
 	IPAddress               string          `json:"ip_address"`
 	Disabled                bool            `json:"disabled" gorm:"default:false"`
+	DeactivatedOn           int64           `json:"deactivated_on" gorm:"default:0"`
 	Notifications           []*Notification `json:"notifications" gorm:"foreignKey:UserID;"`
 }
 
-func GetPasswordHash(email string) (string, error) {
+const (
+	TwoFactorMethodTOTP  = "totp"
+	TwoFactorMethodEmail = "email"
+)
+
+func GetPasswordHash(ctx context.Context, email string) (string, error) {
 	var password string
-	err := db.Model(&Auth{}).Select("password").Where("e_mail = ?", email).First(&password).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Select("password").Where("e_mail = ?", email).First(&password).Error
 	return password, err
 }
 
-func CheckAuth(email, password, ip string) (bool, error) {
-	exists, err := Exists(email)
-	if err != nil || !exists {
-		return false, nil
+// LockedError is returned by CheckAuth while the account or the ip is locked
+type LockedError struct {
+	Remaining time.Duration
+}
+
+func (err *LockedError) Error() string {
+	return "too many failed login attempts"
+}
+
+func CheckAuth(ctx context.Context, email, password, ip string) (bool, error) {
+
+	remaining, err := cache.GetLockout(ctx, email, ip)
+	if err != nil {
+		logging.FromContext(ctx).Error("error while checking the lockout", zap.Error(err))
+		return false, errors.New("error while checking the lockout")
+	}
+
+	if remaining > 0 {
+		return false, &LockedError{Remaining: remaining}
 	}
 
-	attempts, err := cache.GetFailedLoginAttempts(context.Background(), email)
-	if err != nil || attempts >= 10 {
-		log.Print(err)
-		return false, errors.New("too many failed login attempts")
+	exists, err := Exists(ctx, email)
+	if err != nil || !exists {
+		//still count the attempt against the ip
+		if err := cache.RegisterFailedLogin(ctx, "", ip); err != nil {
+			logging.FromContext(ctx).Error("error while registering a failed login", zap.Error(err))
+		}
+		return false, nil
 	}
 
-	pwdHash, err1 := GetPasswordHash(email)
+	pwdHash, err1 := GetPasswordHash(ctx, email)
 	if err1 != nil {
 		return false, nil
 	}
@@ -59,10 +97,13 @@ func CheckAuth(email, password, ip string) (bool, error) {
 	}
 
 	var auth Auth
-	err = db.Transaction(func(tx *gorm.DB) error {
-		err = tx.Model(&Auth{}).Select("id").Where("e_mail = ?", email).First(&auth).Error
-		err = tx.Model(&Auth{}).Where("e_mail = ?", email).Update("ip_address", ip).Error
-		return err
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Auth{}).Select("id, deactivated_on, disabled").Where("e_mail = ?", email).First(&auth).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&Auth{}).Where("e_mail = ?", email).Update("ip_address", ip).Error
 	})
 
 	if err != nil {
@@ -72,7 +113,7 @@ func CheckAuth(email, password, ip string) (bool, error) {
 	if auth.Disabled {
 		if auth.ID > 0 && match {
 			//Reactive the account
-			err := ActivateAccount(email)
+			err := ActivateAccount(ctx, email)
 			if err != nil {
 				return false, err
 			}
@@ -80,40 +121,117 @@ func CheckAuth(email, password, ip string) (bool, error) {
 		return false, nil
 	}
 
+	if auth.DeactivatedOn > 0 && auth.ID > 0 && match {
+		//Logging in during the grace period cancels the deactivation
+		err := ReactivateAccount(ctx, email, auth.ID)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	if auth.ID > 0 && match {
-		err = cache.ClearFailedLoginAttempts(context.Background(), email)
+		//a failed upgrade shouldn't block the login; it's retried on the next one
+		if err := rehashIfNeeded(ctx, email, password, pwdHash); err != nil {
+			logging.FromContext(ctx).Error("error while rehashing the password", logging.User(email), zap.Error(err))
+		}
+
+		err = cache.ClearFailedLoginAttempts(ctx, email)
 		if err != nil {
 			return true, err
 		}
 		return true, nil
 	}
 
-	err = cache.UpdateFailedLoginAttempts(context.Background(), email)
+	err = cache.RegisterFailedLogin(ctx, email, ip)
 	if err != nil {
-		log.Print(err)
+		logging.FromContext(ctx).Error("error while registering a failed login", zap.Error(err))
 		return false, errors.New("error while updating failed login attempts")
 	}
 
 	return false, nil
 }
 
-func GetUser(email string) (*Auth, error) {
+// rehashIfNeeded upgrades the stored hash when it was created with weaker argon2id parameters
+func rehashIfNeeded(ctx context.Context, email, password, pwdHash string) error {
+	needsRehash, err := pwd.NeedsRehash(pwdHash)
+	if err != nil || !needsRehash {
+		return err
+	}
+
+	passwordHash, err := pwd.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	//only replace the hash if it didn't change in the meantime
+	err = db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Where("password = ?", pwdHash).Update("password", passwordHash).Error
+	return err
+}
+
+type HashParamsReport struct {
+	Current  string           `json:"current"`
+	Total    int64            `json:"total"`
+	Outdated int64            `json:"outdated"`
+	Params   map[string]int64 `json:"params"`
+}
+
+// GetHashParamsReport counts how many accounts still use outdated argon2id parameters
+func GetHashParamsReport(ctx context.Context) (*HashParamsReport, error) {
+	var rows []struct {
+		Sample string
+		Count  int64
+	}
+
+	err := db.WithContext(ctx).Model(&Auth{}).
+		Select("MIN(password) AS sample, COUNT(*) AS count").
+		Group("split_part(password, '$', 4), length(password)").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	current := pwd.GetHashParams()
+	report := &HashParamsReport{
+		Current: pwd.FormatParams(&current),
+		Params:  map[string]int64{},
+	}
+
+	for _, row := range rows {
+		report.Total += row.Count
+
+		params, _, _, err := argon2id.DecodeHash(row.Sample)
+		if err != nil {
+			report.Outdated += row.Count
+			report.Params["invalid"] += row.Count
+			continue
+		}
+
+		report.Params[pwd.FormatParams(params)] += row.Count
+		if pwd.IsWeaker(params) {
+			report.Outdated += row.Count
+		}
+	}
+
+	return report, nil
+}
+
+func GetUser(ctx context.Context, email string) (*Auth, error) {
 	var user Auth
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Select("id, e_mail, email_verified, username, rank, two_factor_authentication, created_on, modified_on, deleted_at").First(&user).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Select("id, e_mail, email_verified, username, rank, two_factor_authentication, two_factor_method, created_on, modified_on, deleted_at").First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (auth *Auth) UpdateUser(email string) error {
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Updates(auth).Error
+func (auth *Auth) UpdateUser(ctx context.Context, email string) error {
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Updates(auth).Error
 	return err
 }
 
-func CheckPassword(email, pwd string) (bool, error) {
+func CheckPassword(ctx context.Context, email, pwd string) (bool, error) {
 	var password string
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Select("password").First(&password).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Select("password").First(&password).Error
 	if err != nil {
 		return false, err
 	}
@@ -126,41 +244,207 @@ func CheckPassword(email, pwd string) (bool, error) {
 	return true, nil
 }
 
-func DisableAccount(email string) error {
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Update("disabled", true).Error
+func DisableAccount(ctx context.Context, email string) error {
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Update("disabled", true).Error
 	return err
 }
 
-func ActivateAccount(email string) error {
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Update("disabled", false).Error
+func ActivateAccount(ctx context.Context, email string) error {
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Update("disabled", false).Error
 	return err
 }
 
-func IsDisabled(email string) (bool, error) {
+// DeactivateAccount disables the account and marks it for deletion after the grace period
+func DeactivateAccount(ctx context.Context, email string) error {
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Updates(map[string]interface{}{
+		"disabled":       true,
+		"deactivated_on": time.Now().Unix(),
+	}).Error
+	return err
+}
+
+func ReactivateAccount(ctx context.Context, email string, userId int) error {
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Updates(map[string]interface{}{
+		"disabled":       false,
+		"deactivated_on": 0,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	notification := Notification{
+		UserID:           userId,
+		Title:            "Account reactivated",
+		Text:             "Your account was reactivated and will no longer be deleted",
+		NotificationType: "account_reactivated",
+		Date:             time.Now().Format("02.01.2006"),
+	}
+
+	return CreateNotification(ctx, notification)
+}
+
+func IsDeactivated(ctx context.Context, email string) (bool, error) {
+	var deactivatedOn int64
+	err := db.WithContext(ctx).Model(&Auth{}).Select("deactivated_on").Where("e_mail = ?", email).Find(&deactivatedOn).Error
+	if err != nil {
+		return false, err
+	}
+	return deactivatedOn > 0, nil
+}
+
+func GetDeletionDate(ctx context.Context, email string) (time.Time, error) {
+	var deactivatedOn int64
+	err := db.WithContext(ctx).Model(&Auth{}).Select("deactivated_on").Where("e_mail = ?", email).Find(&deactivatedOn).Error
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if deactivatedOn <= 0 {
+		return time.Time{}, errors.New("account is not deactivated")
+	}
+
+	return time.Unix(deactivatedOn, 0).Add(accountDeletionGracePeriod), nil
+}
+
+// CountUsers is the number of all accounts including deactivated ones, for the metrics
+func CountUsers(ctx context.Context) (int64, error) {
+	var count int64
+	err := db.WithContext(ctx).Model(&Auth{}).Count(&count).Error
+	return count, err
+}
+
+// PurgeDeactivatedAccounts permanently deletes every account whose grace period is over and returns how many were
+// deleted. An account that can't be deleted is logged and retried with the next run
+func PurgeDeactivatedAccounts(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-accountDeletionGracePeriod).Unix()
+
+	var emails []string
+	err := db.WithContext(ctx).Model(&Auth{}).Where("deactivated_on > ?", 0).Where("deactivated_on <= ?", cutoff).Pluck("e_mail", &emails).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, email := range emails {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+
+		if err := purgeAccount(ctx, email); err != nil {
+			logging.FromContext(ctx).Error("error while purging a deactivated account", logging.User(email), zap.Error(err))
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+func purgeAccount(ctx context.Context, email string) error {
+	userId, err := GetUserIDByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var listIds []int
+		if err := tx.Model(&Shoppinglist{}).Where("owner_id = ?", userId).Pluck("id", &listIds).Error; err != nil {
+			return err
+		}
+
+		if len(listIds) > 0 {
+			if err := tx.Unscoped().Where("parent_list_id IN ?", listIds).Delete(&Item{}).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Unscoped().Where("parent_list_id IN ?", listIds).Delete(&Participant{}).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Unscoped().Where("id IN ?", listIds).Delete(&Shoppinglist{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&Participant{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&BackupCode{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&ResetPassword{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&EmailChange{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&Notification{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&TOTPSecret{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&WebAuthnCredential{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&LoginEvent{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Where("e_mail = ?", email).Delete(&Auth{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := cache.InvalidateAllJWTTokens(ctx, email); err != nil {
+		return err
+	}
+
+	if err := cache.DeleteTOTPSecret(ctx, email); err != nil {
+		return err
+	}
+
+	return cache.DeleteUser(ctx, email)
+}
+
+func IsDisabled(ctx context.Context, email string) (bool, error) {
 	var disabled bool
-	err := db.Model(&Auth{}).Select("disabled").Where("e_mail = ?", email).Find(&disabled).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Select("disabled").Where("e_mail = ?", email).Find(&disabled).Error
 	if err != nil {
 		return false, err
 	}
 	return disabled, nil
 }
 
-func CreateAccount(email, username, password, ip string) error {
+func CreateAccount(ctx context.Context, email, username, password, ip string) error {
 	validEmail := validateEmail(email)
 	if !validEmail {
-		return errors.New("email is not valid")
+		return ErrInvalidEmail
 	}
 
-	exists, err := Exists(email)
+	exists, err := Exists(ctx, email)
 	if err != nil {
 		return err
 	}
 
 	if exists {
-		return errors.New("email is already being used")
+		return ErrEmailTaken
+	}
+
+	err = pwd.Validate(email, password)
+	if err != nil {
+		return err
 	}
 
-	passwordHash, err := argon2id.CreateHash(password, argon2id.DefaultParams)
+	passwordHash, err := pwd.Hash(password)
 	if err != nil {
 		return err
 	}
@@ -175,7 +459,7 @@ func CreateAccount(email, username, password, ip string) error {
 		IPAddress:               ip,
 	}
 
-	err = db.Create(&authObj).Error
+	err = db.WithContext(ctx).Create(&authObj).Error
 	if err != nil {
 		return err
 	}
@@ -183,23 +467,23 @@ func CreateAccount(email, username, password, ip string) error {
 	return nil
 }
 
-func SetUsername(email, username string) error {
+func SetUsername(ctx context.Context, email, username string) error {
 	if len(username) > 32 {
-		return errors.New("username can only be a maximum of 32 characters long")
+		return ErrUsernameTooLong
 	}
 
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Update("username", username).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Update("username", username).Error
 	return err
 }
 
-func GetUsername(email string) (string, error) {
+func GetUsername(ctx context.Context, email string) (string, error) {
 	var username string
-	err := db.Model(&Auth{}).Select("username").Where("e_mail = ?", email).Find(&username).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Select("username").Where("e_mail = ?", email).Find(&username).Error
 	return username, err
 }
 
-func DeleteAccount(email, password string) error {
-	pwdHash, err := GetPasswordHash(email)
+func DeleteAccount(ctx context.Context, email, password string) error {
+	pwdHash, err := GetPasswordHash(ctx, email)
 	if err != nil {
 		return err
 	}
@@ -210,10 +494,10 @@ func DeleteAccount(email, password string) error {
 	}
 
 	if !match {
-		return errors.New("wrong password")
+		return ErrWrongPassword
 	}
 
-	err = db.Where("e_mail = ?", email).Delete(&Auth{}).Error
+	err = db.WithContext(ctx).Where("e_mail = ?", email).Delete(&Auth{}).Error
 	if err != nil {
 		return err
 	}
@@ -221,8 +505,8 @@ func DeleteAccount(email, password string) error {
 	return nil
 }
 
-func Logout(email, token string) (bool, error) {
-	ok, err := cache.DeleteTokenByEmail(email, token)
+func Logout(ctx context.Context, email, token string) (bool, error) {
+	ok, err := cache.DeleteTokenByEmail(ctx, email, token)
 	if err != nil || !ok {
 		return false, err
 	}
@@ -230,19 +514,19 @@ func Logout(email, token string) (bool, error) {
 	return true, nil
 }
 
-func GetRank(email string) (string, error) {
+func GetRank(ctx context.Context, email string) (string, error) {
 	var rank string
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Select("rank").First(&rank).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Select("rank").First(&rank).Error
 	return rank, err
 }
 
-func SetRank(email, rank string) error {
+func SetRank(ctx context.Context, email, rank string) error {
 	rankCheck := rankExists(rank)
 	if !rankCheck {
 		return errors.New("rank does not exist")
 	}
 
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Update("rank", rank).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Update("rank", rank).Error
 
 	return err
 }
@@ -251,29 +535,29 @@ func rankExists(rank string) bool {
 	return rank == "default" || rank == "admin"
 }
 
-func Exists(email string) (bool, error) {
+func Exists(ctx context.Context, email string) (bool, error) {
 	var Found bool
-	err := db.Raw("SELECT EXISTS(SELECT id FROM auths WHERE e_mail = ?) AS found", email).Scan(&Found).Error
+	err := db.WithContext(ctx).Raw("SELECT EXISTS(SELECT id FROM auths WHERE e_mail = ?) AS found", email).Scan(&Found).Error
 	return Found, err
 }
 
-func ExistsUserID(userId int) (bool, error) {
+func ExistsUserID(ctx context.Context, userId int) (bool, error) {
 	var Found bool
-	err := db.Raw("SELECT EXISTS(SELECT id FROM auths WHERE id = ?) AS found", userId).Scan(&Found).Error
+	err := db.WithContext(ctx).Raw("SELECT EXISTS(SELECT id FROM auths WHERE id = ?) AS found", userId).Scan(&Found).Error
 	return Found, err
 }
 
-func IsEmailVerified(email string) (bool, error) {
+func IsEmailVerified(ctx context.Context, email string) (bool, error) {
 	var verified bool
-	err := db.Model(&Auth{}).Select("email_verified").Where("e_mail = ?", email).First(&verified).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Select("email_verified").Where("e_mail = ?", email).First(&verified).Error
 	if err != nil {
 		return false, err
 	}
 	return verified, nil
 }
 
-func VerifyEmail(email string) error {
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Update("email_verified", true).Error
+func VerifyEmail(ctx context.Context, email string) error {
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Update("email_verified", true).Error
 	return err
 }
 
@@ -282,14 +566,14 @@ func SendVerifyEmail(email string) error {
 	return errors.New("not implemented yet")
 }
 
-func SetTwoFactorAuthentication(email string, status bool) error {
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Update("two_factor_authentication", status).Error
+func SetTwoFactorAuthentication(ctx context.Context, email string, status bool) error {
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Update("two_factor_authentication", status).Error
 	return err
 }
 
-func IsTwoFactorEnabled(email string) (bool, error) {
+func IsTwoFactorEnabled(ctx context.Context, email string) (bool, error) {
 	var status bool
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Select("two_factor_authentication").First(&status).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Select("two_factor_authentication").First(&status).Error
 	if err != nil {
 		return false, err
 	}
@@ -297,20 +581,35 @@ func IsTwoFactorEnabled(email string) (bool, error) {
 	return status, nil
 }
 
-func UpdateIP(email, ip string) error {
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Update("ip_address", ip).Error
+func SetTwoFactorMethod(ctx context.Context, email, method string) error {
+	if method != TwoFactorMethodTOTP && method != TwoFactorMethodEmail {
+		return errors.New("unknown two factor method")
+	}
+
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Update("two_factor_method", method).Error
+	return err
+}
+
+func GetTwoFactorMethod(ctx context.Context, email string) (string, error) {
+	var method string
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Select("two_factor_method").First(&method).Error
+	return method, err
+}
+
+func UpdateIP(ctx context.Context, email, ip string) error {
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Update("ip_address", ip).Error
 	return err
 }
 
-func GetIP(email string) (string, error) {
+func GetIP(ctx context.Context, email string) (string, error) {
 	var ip string
-	err := db.Model(&Auth{}).Select("ip_address").Where("e_mail = ?", email).Find(&ip).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Select("ip_address").Where("e_mail = ?", email).Find(&ip).Error
 	return ip, err
 }
 
-func Count(email string) (int64, error) {
+func Count(ctx context.Context, email string) (int64, error) {
 	count := int64(0)
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Count(&count).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Count(&count).Error
 	if err != nil {
 		return 1000, err
 	}
@@ -322,16 +621,16 @@ func validateEmail(email string) bool {
 	return err == nil
 }
 
-func GetUserIDByEmail(email string) (int, error) {
+func GetUserIDByEmail(ctx context.Context, email string) (int, error) {
 	var UserId int
-	err := db.Model(&Auth{}).Where("e_mail = ?", email).Select("id").Find(&UserId).Error
+	err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Select("id").Find(&UserId).Error
 	return UserId, err
 }
 
-func ResetPasswordFromUser(email string, password string, oldPassword string, withOldPassword bool) error {
+func ResetPasswordFromUser(ctx context.Context, email string, password string, oldPassword string, withOldPassword bool) error {
 	if withOldPassword {
 		var CurrentPassword string
-		err := db.Model(&Auth{}).Where("e_mail = ?", email).Select("password").Find(&CurrentPassword).Error
+		err := db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Select("password").Find(&CurrentPassword).Error
 		if err != nil {
 			return err
 		}
@@ -342,19 +641,29 @@ func ResetPasswordFromUser(email string, password string, oldPassword string, wi
 		}
 
 		if !verify {
-			return errors.New("password is not correct")
+			return ErrWrongOldPassword
 		}
 	}
 
-	passwordHash, err := argon2id.CreateHash(password, argon2id.DefaultParams)
+	err := pwd.Validate(email, password)
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := pwd.Hash(password)
 	if err != nil {
 		return err
 	}
 
-	err = db.Model(&Auth{}).Where("e_mail = ?", email).Update("password", passwordHash).Error
+	err = db.WithContext(ctx).Model(&Auth{}).Where("e_mail = ?", email).Update("password", passwordHash).Error
 	if err != nil {
 		return err
 	}
 
+	//whoever knew the old password or stole a session has to log in again after a reset
+	if !withOldPassword {
+		return cache.InvalidateAllJWTTokens(ctx, email)
+	}
+
 	return nil
 }
@@ -1,21 +1,29 @@
 package models
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/alexedwards/argon2id"
 	. "github.com/stretchr/testify/assert"
 	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/password"
 	"github.com/urento/shoppinglist/pkg/util"
 )
 
 func SetupTestAuth() {
-	Setup()
-	util.Setup()
-	cache.Setup(true)
+	cfg := config.MustLoad(nil)
+	Setup(cfg)
+	util.Setup(cfg)
+	cache.Setup(cfg)
 }
 
 func TestUpdateUser(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	user, err := CreateUser()
@@ -29,12 +37,12 @@ func TestUpdateUser(t *testing.T) {
 		Username: newUsername,
 	}
 
-	err = auth.UpdateUser(user.EMail)
+	err = auth.UpdateUser(ctx, user.EMail)
 	if err != nil {
 		t.Errorf("Error while updating user: %s", err)
 	}
 
-	u, err := GetUser(user.EMail)
+	u, err := GetUser(ctx, user.EMail)
 	if err != nil {
 		t.Errorf("Error while getting user: %s", err)
 	}
@@ -44,6 +52,8 @@ func TestUpdateUser(t *testing.T) {
 }
 
 func TestDisableAccount(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	user, err := CreateUser()
@@ -51,12 +61,12 @@ func TestDisableAccount(t *testing.T) {
 		t.Errorf("Error while creating user: %s", err)
 	}
 
-	err = DisableAccount(user.EMail)
+	err = DisableAccount(ctx, user.EMail)
 	if err != nil {
 		t.Errorf("Error while disabling account: %s", err)
 	}
 
-	disabled, err := IsDisabled(user.EMail)
+	disabled, err := IsDisabled(ctx, user.EMail)
 	if err != nil {
 		t.Errorf("Error while checking if account is disabled: %s", err)
 	}
@@ -66,6 +76,8 @@ func TestDisableAccount(t *testing.T) {
 }
 
 func TestActivateAccount(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	user, err := CreateUser()
@@ -73,22 +85,22 @@ func TestActivateAccount(t *testing.T) {
 		t.Errorf("Error while creating user: %s", err)
 	}
 
-	err = DisableAccount(user.EMail)
+	err = DisableAccount(ctx, user.EMail)
 	if err != nil {
 		t.Errorf("Error while disabling account: %s", err)
 	}
 
-	disabledBefore, err := IsDisabled(user.EMail)
+	disabledBefore, err := IsDisabled(ctx, user.EMail)
 	if err != nil {
 		t.Errorf("Error while checking if account is disabled: %s", err)
 	}
 
-	err = ActivateAccount(user.EMail)
+	err = ActivateAccount(ctx, user.EMail)
 	if err != nil {
 		t.Errorf("Error while activating account: %s", err)
 	}
 
-	disabledAfter, err := IsDisabled(user.EMail)
+	disabledAfter, err := IsDisabled(ctx, user.EMail)
 	if err != nil {
 		t.Errorf("Error while checking if account is disabled: %s", err)
 	}
@@ -98,7 +110,137 @@ func TestActivateAccount(t *testing.T) {
 	Equal(t, nil, err)
 }
 
+func TestDeactivateAccount(t *testing.T) {
+	ctx := context.Background()
+
+	SetupTestAuth()
+
+	email := util.RandomEmail()
+	username := util.StringWithCharset(20)
+	pwd := util.StringWithCharset(20)
+	ip := util.RandomIPAddress()
+
+	err := CreateAccount(ctx, email, username, pwd, ip)
+	if err != nil {
+		t.Errorf("Error while creating account: %s", err)
+	}
+
+	t.Run("Deactivate Account", func(t *testing.T) {
+		err := DeactivateAccount(ctx, email)
+		if err != nil {
+			t.Errorf("Error while deactivating account: %s", err)
+		}
+
+		deactivated, err := IsDeactivated(ctx, email)
+		if err != nil {
+			t.Errorf("Error while checking if account is deactivated: %s", err)
+		}
+
+		disabled, err := IsDisabled(ctx, email)
+		if err != nil {
+			t.Errorf("Error while checking if account is disabled: %s", err)
+		}
+
+		deletionDate, err := GetDeletionDate(ctx, email)
+		if err != nil {
+			t.Errorf("Error while getting deletion date: %s", err)
+		}
+
+		True(t, deactivated)
+		True(t, disabled)
+		True(t, deletionDate.After(time.Now()))
+	})
+
+	t.Run("Hide Lists from Participants", func(t *testing.T) {
+		id := util.RandomIntWithLength(90000)
+		participantEmail, _ := createTestUser(t)
+
+		userId, err := GetUserIDByEmail(ctx, email)
+		if err != nil {
+			t.Errorf("Error while getting user id: %s", err)
+		}
+
+		err = CreateList(ctx, Shoppinglist{ID: id, Title: util.StringWithCharset(20)}, userId, false)
+		if err != nil {
+			t.Errorf("Error while creating shoppinglist: %s", err)
+		}
+
+		_, err = AddParticipant(ctx, Participant{ParentListID: id, Email: participantEmail, Status: "accepted", RequestFrom: email})
+		if err != nil {
+			t.Errorf("Error while adding participant: %s", err)
+		}
+
+		lists, err := GetListsByParticipant(ctx, participantEmail)
+		if err != nil {
+			t.Errorf("Error while getting lists by participant: %s", err)
+		}
+
+		_, err = GetListWithoutOwner(ctx, id)
+
+		Equal(t, 0, len(lists))
+		NotNil(t, err)
+	})
+
+	t.Run("Reactivate Account on Login", func(t *testing.T) {
+		ok, err := CheckAuth(ctx, email, pwd, ip)
+		if err != nil {
+			t.Errorf("Error while checking auth: %s", err)
+		}
+
+		deactivated, err := IsDeactivated(ctx, email)
+		if err != nil {
+			t.Errorf("Error while checking if account is deactivated: %s", err)
+		}
+
+		disabled, err := IsDisabled(ctx, email)
+		if err != nil {
+			t.Errorf("Error while checking if account is disabled: %s", err)
+		}
+
+		True(t, ok)
+		False(t, deactivated)
+		False(t, disabled)
+	})
+}
+
+func TestPurgeDeactivatedAccounts(t *testing.T) {
+	ctx := context.Background()
+
+	SetupTestAuth()
+
+	user, err := CreateUser()
+	if err != nil {
+		t.Errorf("Error while creating user: %s", err)
+	}
+
+	err = DeactivateAccount(ctx, user.EMail)
+	if err != nil {
+		t.Errorf("Error while deactivating account: %s", err)
+	}
+
+	expired := time.Now().Add(-accountDeletionGracePeriod - time.Hour).Unix()
+	err = db.Model(&Auth{}).Where("e_mail = ?", user.EMail).Update("deactivated_on", expired).Error
+	if err != nil {
+		t.Errorf("Error while updating deactivation date: %s", err)
+	}
+
+	purged, err := PurgeDeactivatedAccounts(ctx)
+	if err != nil {
+		t.Errorf("Error while purging deactivated accounts: %s", err)
+	}
+
+	exists, err := Exists(ctx, user.EMail)
+	if err != nil {
+		t.Errorf("Error while checking if the user exists: %s", err)
+	}
+
+	True(t, purged >= 1)
+	False(t, exists)
+}
+
 func TestExistsUserId(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	t.Run("Exists User Id", func(t *testing.T) {
@@ -107,12 +249,12 @@ func TestExistsUserId(t *testing.T) {
 			t.Errorf("Error while creating user: %s", err)
 		}
 
-		u, err := GetUser(user.EMail)
+		u, err := GetUser(ctx, user.EMail)
 		if err != nil {
 			t.Errorf("Error while getting user: %s", err)
 		}
 
-		exists, err := ExistsUserID(u.ID)
+		exists, err := ExistsUserID(ctx, u.ID)
 		if err != nil {
 			t.Errorf("Error while checking if the userid exists: %s", err)
 		}
@@ -121,7 +263,7 @@ func TestExistsUserId(t *testing.T) {
 	})
 
 	t.Run("Exists User Id when the user doesn't exist", func(t *testing.T) {
-		exists, _ := ExistsUserID(999999999999999999)
+		exists, _ := ExistsUserID(ctx, 999999999999999999)
 		Equal(t, false, exists)
 	})
 }
@@ -134,7 +276,7 @@ func TestGetUserIDByEmail(t *testing.T) {
 		t.Errorf("Error while creating user: %s", err)
 	}
 
-	userId, err := GetUserIDByEmail(user.EMail)
+	userId, err := GetUserIDByEmail(context.Background(), user.EMail)
 	if err != nil {
 		t.Errorf("Error while getting userid by email: %s", err)
 	}
@@ -143,6 +285,8 @@ func TestGetUserIDByEmail(t *testing.T) {
 }
 
 func TestResetPasswordFromUser(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	t.Run("Reset Password", func(t *testing.T) {
@@ -151,13 +295,13 @@ func TestResetPasswordFromUser(t *testing.T) {
 		password := util.StringWithCharset(5000)
 		ip := util.RandomIPAddress()
 
-		err := CreateAccount(email, username, password, ip)
+		err := CreateAccount(ctx, email, username, password, ip)
 		if err != nil {
 			t.Errorf("Error while creating account: %s", err)
 		}
 
 		newPassword := util.StringWithCharset(50000)
-		err = ResetPasswordFromUser(email, newPassword, password, true)
+		err = ResetPasswordFromUser(ctx, email, newPassword, password, true)
 		if err != nil {
 			t.Errorf("Error while resetting password from user: %s", err)
 		}
@@ -171,13 +315,13 @@ func TestResetPasswordFromUser(t *testing.T) {
 		password := util.StringWithCharset(5000)
 		ip := util.RandomIPAddress()
 
-		err := CreateAccount(email, username, password, ip)
+		err := CreateAccount(ctx, email, username, password, ip)
 		if err != nil {
 			t.Errorf("Error while creating account: %s", err)
 		}
 
 		newPassword := util.StringWithCharset(50000)
-		err = ResetPasswordFromUser(email, newPassword, "dfbgjhdfbgdjfhbgdfg", true)
+		err = ResetPasswordFromUser(ctx, email, newPassword, "dfbgjhdfbgdjfhbgdfg", true)
 
 		Equal(t, "password is not correct", err.Error())
 	})
@@ -188,13 +332,13 @@ func TestResetPasswordFromUser(t *testing.T) {
 		password := util.StringWithCharset(5000)
 		ip := util.RandomIPAddress()
 
-		err := CreateAccount(email, username, password, ip)
+		err := CreateAccount(ctx, email, username, password, ip)
 		if err != nil {
 			t.Errorf("Error while creating account: %s", err)
 		}
 
 		newPassword := util.StringWithCharset(50000)
-		err = ResetPasswordFromUser(email, newPassword, "", false)
+		err = ResetPasswordFromUser(ctx, email, newPassword, "", false)
 		if err != nil {
 			t.Errorf("Error while resetting password from user: %s", err)
 		}
@@ -204,6 +348,8 @@ func TestResetPasswordFromUser(t *testing.T) {
 }
 
 func TestCreateAccountEmail(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	pwd := util.StringWithCharset(2000)
@@ -213,12 +359,12 @@ func TestCreateAccountEmail(t *testing.T) {
 		email := util.RandomEmail()
 		username := util.StringWithCharset(2000)
 
-		err := CreateAccount(email, username, pwd, "")
+		err := CreateAccount(ctx, email, username, pwd, "")
 		if err != nil {
 			t.Errorf("Error while creating user %s", err.Error())
 		}
 
-		check, err := CheckAuth(email, pwd, "")
+		check, err := CheckAuth(ctx, email, pwd, "")
 		if err != nil {
 			t.Errorf("Error while checking user %s", err.Error())
 		}
@@ -235,14 +381,14 @@ func TestCreateAccountEmail(t *testing.T) {
 		email := util.RandomEmail()
 		username := util.StringWithCharset(2000)
 
-		err := CreateAccount(email, username, pwd, ip)
+		err := CreateAccount(ctx, email, username, pwd, ip)
 		if err != nil {
 			t.Errorf("Error while creating user %s", err.Error())
 		}
 
 		ip2 := util.RandomIPAddress()
 
-		check, err := CheckAuth(email, pwd, ip2)
+		check, err := CheckAuth(ctx, email, pwd, ip2)
 		if err != nil {
 			t.Errorf("Error while checking user %s", err.Error())
 		}
@@ -259,29 +405,26 @@ func TestCreateAccountEmail(t *testing.T) {
 		email := util.RandomEmail()
 		username := util.StringWithCharset(10)
 
-		err := CreateAccount(email, username, pwd, ip)
+		err := CreateAccount(ctx, email, username, pwd, ip)
 		if err != nil {
 			t.Errorf("Error while creating account: %s", err)
 		}
 
-		err = CreateAccount(email, username, pwd, ip)
+		err = CreateAccount(ctx, email, username, pwd, ip)
 		if err == nil {
 			t.Errorf("No Duplication error thrown")
 		}
 
-		containsError := strings.Contains(err.Error(), "email is already being used")
-
-		Equal(t, true, containsError)
-		NotEqual(t, nil, err)
+		Equal(t, ErrEmailTaken, err)
 	})
 
 	t.Run("Create Account with invalid email", func(t *testing.T) {
 		email := util.StringWithCharset(1000)
 		username := util.StringWithCharset(10)
 
-		_ = CreateAccount(email, username, pwd, ip)
+		_ = CreateAccount(ctx, email, username, pwd, ip)
 
-		err := CreateAccount(email, username, pwd, ip)
+		err := CreateAccount(ctx, email, username, pwd, ip)
 		if err == nil {
 			t.Errorf("No Invalid Email error thrown")
 		}
@@ -294,6 +437,8 @@ func TestCreateAccountEmail(t *testing.T) {
 }
 
 func TestCheckAuth(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	email := util.RandomEmail()
@@ -301,50 +446,51 @@ func TestCheckAuth(t *testing.T) {
 	pwd := util.StringWithCharset(20)
 	ip := util.RandomIPAddress()
 
-	err := CreateAccount(email, username, pwd, ip)
+	err := CreateAccount(ctx, email, username, pwd, ip)
 	if err != nil {
 		t.Errorf("Error while creating account: %s", err)
 	}
 
 	t.Run("When Account is Disabled", func(t *testing.T) {
-		err = DisableAccount(email)
+		err = DisableAccount(ctx, email)
 		if err != nil {
 			t.Errorf("Error while disabling account: %s", err)
 		}
 
-		disabledBefore, err := IsDisabled(email)
+		disabledBefore, err := IsDisabled(ctx, email)
 		if err != nil {
 			t.Errorf("Error while checking if the account is disabled: %s", err)
 		}
 
-		ok, err := CheckAuth(email, pwd, ip)
+		ok, err := CheckAuth(ctx, email, pwd, ip)
 		if err != nil {
 			t.Errorf("Error while checking auth: %s", err)
 		}
 
-		disabledAfter, err := IsDisabled(email)
+		disabledAfter, err := IsDisabled(ctx, email)
 		if err != nil {
 			t.Errorf("Error while checking if the account is disabled: %s", err)
 		}
 
-		err = ActivateAccount(email)
+		err = ActivateAccount(ctx, email)
 		if err != nil {
 			t.Errorf("Error while activating account: %s", err)
 		}
 
+		//the login with the correct password activates the account again, but has to be repeated
 		Equal(t, true, disabledBefore)
-		Equal(t, true, disabledAfter)
-		Equal(t, true, ok)
+		Equal(t, false, disabledAfter)
+		Equal(t, false, ok)
 		Equal(t, nil, err)
 	})
 
 	t.Run("Normal Login", func(t *testing.T) {
-		ok, err := CheckAuth(email, pwd, ip)
+		ok, err := CheckAuth(ctx, email, pwd, ip)
 		if err != nil {
 			t.Errorf("Error while checking auth: %s", err)
 		}
 
-		disabled, err := IsDisabled(email)
+		disabled, err := IsDisabled(ctx, email)
 		if err != nil {
 			t.Errorf("Error while checking if the account is disabled: %s", err)
 		}
@@ -355,19 +501,72 @@ func TestCheckAuth(t *testing.T) {
 	})
 
 	t.Run("Wrong Password", func(t *testing.T) {
-		ok, _ := CheckAuth(email, "dsjkhfgjkhdsbvsdfg", "")
+		ok, _ := CheckAuth(ctx, email, "dsjkhfgjkhdsbvsdfg", "")
 
 		Equal(t, false, ok)
 	})
 
 	t.Run("Wrong Email and Password", func(t *testing.T) {
-		ok, _ := CheckAuth("kjfdghjdfbgjhdfbg@gmail.com", "khdfbgkjhdfgbhjdfgbdf", "")
+		ok, _ := CheckAuth(ctx, "kjfdghjdfbgjhdfbg@gmail.com", "khdfbgkjhdfgbhjdfgbdf", "")
 
 		Equal(t, false, ok)
 	})
 }
 
+func TestRehashOnLogin(t *testing.T) {
+	ctx := context.Background()
+
+	SetupTestAuth()
+
+	email := util.RandomEmail()
+	pwd := util.StringWithCharset(20)
+
+	err := CreateAccount(ctx, email, util.StringWithCharset(20), pwd, "")
+	if err != nil {
+		t.Errorf("Error while creating account: %s", err)
+	}
+
+	weak := password.GetHashParams()
+	weak.Memory = weak.Memory / 2
+	weakHash, err := argon2id.CreateHash(pwd, &weak)
+	if err != nil {
+		t.Errorf("Error while hashing password: %s", err)
+	}
+
+	err = db.Model(&Auth{}).Where("e_mail = ?", email).Update("password", weakHash).Error
+	if err != nil {
+		t.Errorf("Error while updating password hash: %s", err)
+	}
+
+	report, err := GetHashParamsReport(ctx)
+	if err != nil {
+		t.Errorf("Error while getting hash params report: %s", err)
+	}
+
+	ok, err := CheckAuth(ctx, email, pwd, "")
+	if err != nil {
+		t.Errorf("Error while checking auth: %s", err)
+	}
+
+	hash, err := GetPasswordHash(ctx, email)
+	if err != nil {
+		t.Errorf("Error while getting password hash: %s", err)
+	}
+
+	needsRehash, err := password.NeedsRehash(hash)
+	if err != nil {
+		t.Errorf("Error while checking if the hash needs a rehash: %s", err)
+	}
+
+	True(t, ok)
+	True(t, report.Outdated >= 1)
+	NotEqual(t, weakHash, hash)
+	False(t, needsRehash)
+}
+
 func TestDeleteAccount(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	pwd := util.StringWithCharset(20)
@@ -375,12 +574,12 @@ func TestDeleteAccount(t *testing.T) {
 	username := util.StringWithCharset(10)
 	ip := util.RandomIPAddress()
 
-	err := CreateAccount(email, username, pwd, ip)
+	err := CreateAccount(ctx, email, username, pwd, ip)
 	if err != nil {
 		t.Errorf("Error while creating the account %s", err.Error())
 	}
 
-	err = DeleteAccount(email, pwd)
+	err = DeleteAccount(ctx, email, pwd)
 	if err != nil {
 		t.Errorf("Error while deleting the account %s", err.Error())
 	}
@@ -389,6 +588,8 @@ func TestDeleteAccount(t *testing.T) {
 }
 
 func TestEmailVerified(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	pwd := util.StringWithCharset(20)
@@ -396,12 +597,12 @@ func TestEmailVerified(t *testing.T) {
 	username := util.StringWithCharset(10)
 	ip := util.RandomIPAddress()
 
-	err := CreateAccount(email, username, pwd, ip)
+	err := CreateAccount(ctx, email, username, pwd, ip)
 	if err != nil {
 		t.Errorf("Error while creating account: %s", err.Error())
 	}
 
-	verified, err := IsEmailVerified(email)
+	verified, err := IsEmailVerified(ctx, email)
 	if err != nil {
 		t.Errorf("Error while checking if email is verified: %s", err.Error())
 	}
@@ -411,6 +612,8 @@ func TestEmailVerified(t *testing.T) {
 }
 
 func TestUpdateEmailVerified(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	pwd := util.StringWithCharset(20)
@@ -418,22 +621,22 @@ func TestUpdateEmailVerified(t *testing.T) {
 	username := util.StringWithCharset(10)
 	ip := util.RandomIPAddress()
 
-	err := CreateAccount(email, username, pwd, ip)
+	err := CreateAccount(ctx, email, username, pwd, ip)
 	if err != nil {
 		t.Errorf("Error while creating account: %s", err.Error())
 	}
 
-	verified1, err := IsEmailVerified(email)
+	verified1, err := IsEmailVerified(ctx, email)
 	if err != nil {
 		t.Errorf("Error while checking if email is verified: %s", err.Error())
 	}
 
-	err = VerifyEmail(email)
+	err = VerifyEmail(ctx, email)
 	if err != nil {
 		t.Errorf("Error while verifying email: %s", err.Error())
 	}
 
-	verified2, err := IsEmailVerified(email)
+	verified2, err := IsEmailVerified(ctx, email)
 	if err != nil {
 		t.Errorf("Error while checking if email is verified: %s", err.Error())
 	}
@@ -444,6 +647,8 @@ func TestUpdateEmailVerified(t *testing.T) {
 }
 
 func TestSetAndGetRank(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	pwd := util.StringWithCharset(20)
@@ -453,17 +658,17 @@ func TestSetAndGetRank(t *testing.T) {
 		username := util.StringWithCharset(10)
 		ip := util.RandomIPAddress()
 
-		err := CreateAccount(email, username, pwd, ip)
+		err := CreateAccount(ctx, email, username, pwd, ip)
 		if err != nil {
 			t.Errorf("Error while creating the account with rank: default %s", err.Error())
 		}
 
-		err = SetRank(email, "default")
+		err = SetRank(ctx, email, "default")
 		if err != nil {
 			t.Errorf("Error while updating the rank %s", err.Error())
 		}
 
-		rank, err := GetRank(email)
+		rank, err := GetRank(ctx, email)
 		if err != nil {
 			t.Errorf("Error while getting the default rank %s", err.Error())
 		}
@@ -477,17 +682,17 @@ func TestSetAndGetRank(t *testing.T) {
 		username := util.StringWithCharset(10)
 		ip := util.RandomIPAddress()
 
-		err := CreateAccount(email, username, pwd, ip)
+		err := CreateAccount(ctx, email, username, pwd, ip)
 		if err != nil {
 			t.Errorf("Error while creating the account with rank: admin %s", err.Error())
 		}
 
-		err = SetRank(email, "admin")
+		err = SetRank(ctx, email, "admin")
 		if err != nil {
 			t.Errorf("Error while updating the rank %s", err.Error())
 		}
 
-		rank, err := GetRank(email)
+		rank, err := GetRank(ctx, email)
 		if err != nil {
 			t.Errorf("Error while getting the admin rank %s", err.Error())
 		}
@@ -501,12 +706,12 @@ func TestSetAndGetRank(t *testing.T) {
 		username := util.StringWithCharset(10)
 		ip := util.RandomIPAddress()
 
-		err := CreateAccount(email, username, pwd, ip)
+		err := CreateAccount(ctx, email, username, pwd, ip)
 		if err != nil {
 			t.Errorf("Error while creating the account with rank: dfgsdfgdsrgdfgdfg %s", err.Error())
 		}
 
-		err = SetRank(email, "dkhfgbjhdfbgjhdfg")
+		err = SetRank(ctx, email, "dkhfgbjhdfbgjhdfg")
 
 		containsError := strings.Contains(err.Error(), "rank does not exist")
 
@@ -516,6 +721,8 @@ func TestSetAndGetRank(t *testing.T) {
 }
 
 func TestGetUser(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	pwd := util.StringWithCharset(20)
@@ -525,12 +732,12 @@ func TestGetUser(t *testing.T) {
 		username := util.StringWithCharset(10)
 		ip := util.RandomIPAddress()
 
-		err := CreateAccount(email, username, pwd, ip)
+		err := CreateAccount(ctx, email, username, pwd, ip)
 		if err != nil {
 			t.Errorf("Error while creating the account with rank: default %s", err.Error())
 		}
 
-		user, err := GetUser(email)
+		user, err := GetUser(ctx, email)
 		if err != nil {
 			t.Errorf("Error while getting the user: %s", err.Error())
 		}
@@ -550,7 +757,7 @@ func TestGetUser(t *testing.T) {
 	t.Run("Get User that doesn't exist", func(t *testing.T) {
 		email := util.StringWithCharset(100) + "@gmail.com"
 
-		_, err := GetUser(email)
+		_, err := GetUser(ctx, email)
 		if err == nil && err.Error() != "record not found" {
 			t.Errorf("No error was thrown, even though the user did not get created")
 		}
@@ -560,6 +767,8 @@ func TestGetUser(t *testing.T) {
 }
 
 func TestEnableTwoFactorAuthentication(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	pwd := util.StringWithCharset(20)
@@ -567,17 +776,17 @@ func TestEnableTwoFactorAuthentication(t *testing.T) {
 	username := util.StringWithCharset(10)
 	ip := util.RandomIPAddress()
 
-	err := CreateAccount(email, username, pwd, ip)
+	err := CreateAccount(ctx, email, username, pwd, ip)
 	if err != nil {
 		t.Errorf("Error while creating the account with rank: default %s", err.Error())
 	}
 
-	err = SetTwoFactorAuthentication(email, true)
+	err = SetTwoFactorAuthentication(ctx, email, true)
 	if err != nil {
 		t.Errorf("Error while updating Two Factor Authentication Status %s", err)
 	}
 
-	isEnabled, err := IsTwoFactorEnabled(email)
+	isEnabled, err := IsTwoFactorEnabled(ctx, email)
 	if err != nil {
 		t.Errorf("Error while getting Two Factor Authentication Status %s", err)
 	}
@@ -587,6 +796,8 @@ func TestEnableTwoFactorAuthentication(t *testing.T) {
 }
 
 func TestUpdateIPAndGet(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	pwd := util.StringWithCharset(20)
@@ -594,19 +805,19 @@ func TestUpdateIPAndGet(t *testing.T) {
 	username := util.StringWithCharset(100)
 	ip := util.RandomIPAddress()
 
-	err := CreateAccount(email, username, pwd, ip)
+	err := CreateAccount(ctx, email, username, pwd, ip)
 	if err != nil {
 		t.Errorf("Error while creating the account with rank: default %s", err.Error())
 	}
 
 	ip2 := util.RandomIPAddress()
 
-	err = UpdateIP(email, ip2)
+	err = UpdateIP(ctx, email, ip2)
 	if err != nil {
 		t.Errorf("Error while updating ip: %s", err)
 	}
 
-	newIP, err := GetIP(email)
+	newIP, err := GetIP(ctx, email)
 	if err != nil {
 		t.Errorf("Error while getting ip: %s", err)
 	}
@@ -616,6 +827,8 @@ func TestUpdateIPAndGet(t *testing.T) {
 }
 
 func TestUpdateUsername(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	pwd := util.StringWithCharset(20)
@@ -625,19 +838,19 @@ func TestUpdateUsername(t *testing.T) {
 		username := util.StringWithCharset(100)
 		ip := util.RandomIPAddress()
 
-		err := CreateAccount(email, username, pwd, ip)
+		err := CreateAccount(ctx, email, username, pwd, ip)
 		if err != nil {
 			t.Errorf("Error while creating user: %s", err)
 		}
 
 		username2 := util.StringWithCharset(30)
 
-		err = SetUsername(email, username2)
+		err = SetUsername(ctx, email, username2)
 		if err != nil {
 			t.Errorf("Error while updating username: %s", err)
 		}
 
-		updatedUsername, err := GetUsername(email)
+		updatedUsername, err := GetUsername(ctx, email)
 		if err != nil {
 			t.Errorf("Error while getting username: %s", err)
 		}
@@ -651,14 +864,14 @@ func TestUpdateUsername(t *testing.T) {
 		username := util.StringWithCharset(100)
 		ip := util.RandomIPAddress()
 
-		err := CreateAccount(email, username, pwd, ip)
+		err := CreateAccount(ctx, email, username, pwd, ip)
 		if err != nil {
 			t.Errorf("Error while creating user: %s", err)
 		}
 
 		username2 := util.StringWithCharset(34)
 
-		err = SetUsername(email, username2)
+		err = SetUsername(ctx, email, username2)
 		if err == nil {
 			t.Errorf("No Error thrown even though the username is over 32 characters")
 		}
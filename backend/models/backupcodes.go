@@ -2,70 +2,106 @@ package models
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"time"
 
+	"github.com/alexedwards/argon2id"
 	"github.com/lib/pq"
 	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/logging"
+	pwd "github.com/urento/shoppinglist/pkg/password"
 	"github.com/urento/shoppinglist/pkg/util"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type BackupCodes struct {
+const backupCodeCount = 6
+
+// every attempt compares the code with all argon2id hashes and the routes don't need a session, so the attempts
+// per account are limited
+const (
+	backupCodeMaxAttempts   = 5
+	backupCodeAttemptWindow = 15 * time.Minute
+)
+
+var (
+	ErrTooManyBackupCodeAttempts = e.New(http.StatusTooManyRequests, e.ERROR_TOO_MANY_LOGIN_ATTEMPTS)
+	ErrBackupCodesExist          = e.New(http.StatusConflict, e.ERROR_BACKUP_CODES_ALREADY_EXIST)
+)
+
+// BackupCode stores a single backup code as argon2id hash; the plaintext is only returned once when it's generated
+type BackupCode struct {
 	Model
 
-	Owner string         `json:"owner"`
-	Codes pq.StringArray `gorm:"type:text[]" json:"codes"`
+	ID       int    `gorm:"primaryKey" json:"id"`
+	UserID   int    `gorm:"index;not null" json:"user_id"`
+	CodeHash string `json:"-"`
+	UsedAt   int64  `gorm:"default:0" json:"used_at"`
 }
 
-func GenerateCodes(email string, userId int, regenerate bool, withNotification bool) (pq.StringArray, error) {
-	code1 := util.RandomString(8)
-	code2 := util.RandomString(8)
-	code3 := util.RandomString(8)
-	code4 := util.RandomString(8)
-	code5 := util.RandomString(8)
-	code6 := util.RandomString(8)
-	codes := pq.StringArray{
-		code1,
-		code2,
-		code3,
-		code4,
-		code5,
-		code6,
-	}
+// GenerateCodes creates new backup codes for the user. Unused codes are only replaced with regenerate, otherwise
+// ErrBackupCodesExist is returned
+func GenerateCodes(ctx context.Context, email string, userId int, regenerate bool, withNotification bool) (pq.StringArray, error) {
+	codes := make(pq.StringArray, 0, backupCodeCount)
+	backupCodes := make([]BackupCode, 0, backupCodeCount)
 
-	backupCodes := &BackupCodes{
-		Owner: email,
-		Codes: codes,
-	}
+	for i := 0; i < backupCodeCount; i++ {
+		code := util.RandomString(8)
 
-	if regenerate {
-		has, err := HasCodes(email)
+		hash, err := pwd.Hash(code)
 		if err != nil {
 			return pq.StringArray{}, err
 		}
 
-		if has {
-			err := RemoveCodes(email)
+		codes = append(codes, code)
+		backupCodes = append(backupCodes, BackupCode{UserID: userId, CodeHash: hash})
+	}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		//locking the user serializes concurrent requests, so only one of them can see no unused codes
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", userId).Take(&Auth{}).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !regenerate {
+			var remaining int64
+			err := tx.Model(&BackupCode{}).Where("user_id = ?", userId).Where("used_at = 0").Count(&remaining).Error
 			if err != nil {
-				return pq.StringArray{}, err
+				return err
+			}
+
+			if remaining > 0 {
+				return ErrBackupCodesExist
 			}
 		}
-	}
 
-	if withNotification {
-		notification := Notification{
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&BackupCode{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&backupCodes).Error; err != nil {
+			return err
+		}
+
+		if !withNotification {
+			return nil
+		}
+
+		return tx.Create(&Notification{
 			UserID:           userId,
 			Title:            "New Backup Codes",
 			Text:             "New Backup Codes were generated",
 			NotificationType: "new_backupcodes",
 			Date:             time.Now().Format("02.01.2006"),
-		}
-
-		if err := CreateNotification(notification); err != nil {
-			return pq.StringArray{}, err
-		}
-	}
-
-	err := db.Create(&backupCodes).Error
+		}).Error
+	})
 	if err != nil {
 		return pq.StringArray{}, err
 	}
@@ -73,40 +109,140 @@ func GenerateCodes(email string, userId int, regenerate bool, withNotification b
 	return codes, nil
 }
 
-func GetCodes(email string) (pq.StringArray, error) {
-	var Codes pq.StringArray
-	err := db.Model(&BackupCodes{}).Where("owner = ?", email).Select("codes").Find(&Codes).Error
-	return Codes, err
+// CountRemainingCodes returns how many backup codes haven't been used yet
+func CountRemainingCodes(ctx context.Context, email string) (int64, error) {
+	var remaining int64
+	err := db.WithContext(ctx).Model(&BackupCode{}).Where("user_id = "+userIdByEmail, email).Where("used_at = 0").Count(&remaining).Error
+	return remaining, err
 }
 
-func RemoveCodes(email string) error {
-	err := db.Where("owner = ?", email).Delete(&BackupCodes{Owner: email}).Error
+func RemoveCodes(ctx context.Context, email string) error {
+	err := db.WithContext(ctx).Unscoped().Where("user_id = "+userIdByEmail, email).Delete(&BackupCode{}).Error
 	return err
 }
 
-func HasCodes(email string) (bool, error) {
+func HasCodes(ctx context.Context, email string) (bool, error) {
 	var Has bool
-	err := db.Raw("SELECT EXISTS(SELECT codes FROM backup_codes WHERE owner = ?) AS found", email).Scan(&Has).Error
+	err := db.WithContext(ctx).Raw("SELECT EXISTS(SELECT id FROM backup_codes WHERE user_id = "+userIdByEmail+" AND deleted_at IS NULL) AS found", email).Scan(&Has).Error
 	return Has, err
 }
 
-func VerifyCode(email, code string) (bool, error) {
-	var Codes []string
-	err := db.Model(&BackupCodes{}).Select("codes").Where("owner = ?", email).Find(&Codes).Error
+// VerifyCode checks the code against the unused backup codes and consumes it; every code can only be used once
+func VerifyCode(ctx context.Context, email, code string) (bool, error) {
+	attempts, err := cache.IncrBackupCodeAttempts(ctx, email, backupCodeAttemptWindow)
+	if err != nil {
+		return false, err
+	}
+
+	if attempts > backupCodeMaxAttempts {
+		return false, ErrTooManyBackupCodeAttempts
+	}
+
+	var backupCodes []BackupCode
+	err = db.WithContext(ctx).Model(&BackupCode{}).Where("user_id = "+userIdByEmail, email).Where("used_at = 0").Find(&backupCodes).Error
 	if err != nil {
 		return false, err
 	}
 
-	for idx := range Codes {
-		b := util.StringArrayToArray(Codes, idx)
-		if b == code {
-			err = cache.ActivateResetPassword(context.Background(), email)
+	for _, backupCode := range backupCodes {
+		match, err := argon2id.ComparePasswordAndHash(code, backupCode.CodeHash)
+		if err != nil {
+			return false, err
+		}
+
+		if !match {
+			continue
+		}
+
+		//only one request can flip used_at, a concurrent one doesn't update any row
+		result := db.WithContext(ctx).Model(&BackupCode{}).Where("id = ?", backupCode.ID).Where("used_at = 0").Update("used_at", time.Now().Unix())
+		if result.Error != nil {
+			return false, result.Error
+		}
+
+		if result.RowsAffected != 1 {
+			return false, nil
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// VerifyCodeForReset consumes the backup code and returns a reset token for ResetPasswordWithToken, the token is
+// empty if the code is wrong
+func VerifyCodeForReset(ctx context.Context, email, code string) (string, error) {
+	ok, err := VerifyCode(ctx, email, code)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	return createResetToken(ctx, email)
+}
+
+// migrateLegacyBackupCodes hashes the plaintext codes of the old backup_codes table (one text[] row per user)
+// and recreates the table with one row per code
+func migrateLegacyBackupCodes(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn(&BackupCode{}, "codes") {
+		return nil
+	}
+
+	type legacyBackupCodes struct {
+		Owner string
+		Codes pq.StringArray `gorm:"type:text[]"`
+	}
+
+	var legacy []legacyBackupCodes
+	err := tx.Table("backup_codes").Select("owner, codes").Where("deleted_at IS NULL").Find(&legacy).Error
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().DropTable("backup_codes"); err != nil {
+		return err
+	}
+
+	//the table as it was when this migration was added, later changes to BackupCode need their own migration
+	err = execStatements([]string{
+		`CREATE TABLE backup_codes (
+			id bigserial PRIMARY KEY,
+			created_on bigint,
+			modified_on bigint,
+			deleted_at timestamptz,
+			user_id bigint NOT NULL,
+			code_hash text,
+			used_at bigint DEFAULT 0
+		)`,
+		`CREATE INDEX idx_backup_codes_deleted_at ON backup_codes (deleted_at)`,
+		`CREATE INDEX idx_backup_codes_user_id ON backup_codes (user_id)`,
+	})(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range legacy {
+		var userId int
+		if err := tx.Model(&Auth{}).Where("e_mail = ?", l.Owner).Select("id").Find(&userId).Error; err != nil {
+			return err
+		}
+
+		//the account doesn't exist anymore
+		if userId <= 0 {
+			continue
+		}
+
+		for _, code := range l.Codes {
+			hash, err := pwd.Hash(code)
 			if err != nil {
-				return true, err
+				return err
+			}
+
+			if err := tx.Create(&BackupCode{UserID: userId, CodeHash: hash}).Error; err != nil {
+				return err
 			}
-			return true, nil
 		}
 	}
 
-	return false, nil
+	logging.L().Info("migrated legacy backup codes", zap.Int("users", len(legacy)))
+	return nil
 }
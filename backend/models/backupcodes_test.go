@@ -1,24 +1,28 @@
 package models
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
 	"github.com/urento/shoppinglist/pkg/util"
 )
 
 func TestGenerateCodes(t *testing.T) {
-	Setup()
+	ctx := context.Background()
 
-	email := util.StringWithCharset(10) + "@gmail.com"
+	Setup(config.MustLoad(nil))
+
+	email, userId := createTestUser(t)
 
 	t.Run("normal generate codes", func(t *testing.T) {
-		_, err := GenerateCodes(email, 0, false, false)
+		_, err := GenerateCodes(ctx, email, userId, false, false)
 		if err != nil {
 			t.Errorf("Error while generating codes: %s", err)
 		}
 
-		has, err := HasCodes(email)
+		has, err := HasCodes(ctx, email)
 		if err != nil {
 			t.Errorf("Error while checking if the user already has backup codes: %s", err)
 		}
@@ -27,82 +31,91 @@ func TestGenerateCodes(t *testing.T) {
 		Equal(t, nil, err)
 	})
 
+	t.Run("generate next to unused codes", func(t *testing.T) {
+		_, err := GenerateCodes(ctx, email, userId, false, false)
+
+		Equal(t, ErrBackupCodesExist, err)
+	})
+
 	t.Run("regenerate codes", func(t *testing.T) {
-		has2, err := HasCodes(email)
+		has2, err := HasCodes(ctx, email)
 		if err != nil {
 			t.Errorf("Error while checking if the user already has backup codes: %s", err)
 		}
 
-		_, err = GenerateCodes(email, 0, true, false)
+		_, err = GenerateCodes(ctx, email, userId, true, false)
 		if err != nil {
 			t.Errorf("Error while generating codes: %s", err)
 		}
 
-		has, err := HasCodes(email)
+		has, err := HasCodes(ctx, email)
 		if err != nil {
 			t.Errorf("Error while checking if the user already has backup codes: %s", err)
 		}
 
+		remaining, err := CountRemainingCodes(ctx, email)
+		if err != nil {
+			t.Errorf("Error while counting codes: %s", err)
+		}
+
 		Equal(t, true, has2)
 		Equal(t, true, has)
+		Equal(t, int64(backupCodeCount), remaining)
 		Equal(t, nil, err)
 	})
 }
 
-func TestGetCodes(t *testing.T) {
-	Setup()
+func TestCountRemainingCodes(t *testing.T) {
+	ctx := context.Background()
 
-	email := util.StringWithCharset(10) + "@gmail.com"
+	Setup(config.MustLoad(nil))
 
-	c, err := GenerateCodes(email, 0, false, false)
+	email, userId := createTestUser(t)
+
+	c, err := GenerateCodes(ctx, email, userId, false, false)
 	if err != nil {
 		t.Errorf("Error while generating codes: %s", err)
 	}
 
-	has, err := HasCodes(email)
+	before, err := CountRemainingCodes(ctx, email)
 	if err != nil {
-		t.Errorf("Error while checking if the user already has backup codes: %s", err)
+		t.Errorf("Error while counting codes: %s", err)
 	}
 
-	codes, err := GetCodes(email)
+	_, err = VerifyCode(ctx, email, c[0])
 	if err != nil {
-		t.Errorf("Error while getting codes: %s", err)
+		t.Errorf("Error while verifying the backup code: %s", err)
 	}
 
-	s1 := util.StringArrayToArray(codes, 0)
-	s2 := util.StringArrayToArray(codes, 1)
-	s3 := util.StringArrayToArray(codes, 2)
-	s4 := util.StringArrayToArray(codes, 3)
-	s5 := util.StringArrayToArray(codes, 4)
-	s6 := util.StringArrayToArray(codes, 5)
-
-	Equal(t, c[0], s1)
-	Equal(t, c[1], s2)
-	Equal(t, c[2], s3)
-	Equal(t, c[3], s4)
-	Equal(t, c[4], s5)
-	Equal(t, c[5], s6)
-	Equal(t, true, has)
+	after, err := CountRemainingCodes(ctx, email)
+	if err != nil {
+		t.Errorf("Error while counting codes: %s", err)
+	}
+
+	Equal(t, int64(6), before)
+	Equal(t, int64(5), after)
 	Equal(t, nil, err)
 }
 
 func TestVerifyCode(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
-	email := util.StringWithCharset(10) + "@gmail.com"
+	email, userId := createTestUser(t)
 
-	c, err := GenerateCodes(email, 0, false, false)
+	c, err := GenerateCodes(ctx, email, userId, false, false)
 	if err != nil {
 		t.Errorf("Error while generating codes: %s", err)
 	}
 
-	has, err := HasCodes(email)
+	has, err := HasCodes(ctx, email)
 	if err != nil {
 		t.Errorf("Error while checking if the user already has backup codes: %s", err)
 	}
 
 	s := util.StringArrayToArray(c, 0)
-	ok, err := VerifyCode(email, s)
+	ok, err := VerifyCode(ctx, email, s)
 	if err != nil {
 		t.Errorf("Error while verifying the backup code: %s", err)
 	}
@@ -110,34 +123,101 @@ func TestVerifyCode(t *testing.T) {
 	Equal(t, true, has)
 	Equal(t, true, ok)
 	Equal(t, nil, err)
+
+	t.Run("Use code twice", func(t *testing.T) {
+		ok, err := VerifyCode(ctx, email, s)
+
+		Equal(t, false, ok)
+		Equal(t, nil, err)
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		ok, err := VerifyCode(ctx, email, util.RandomString(8))
+
+		Equal(t, false, ok)
+		Equal(t, nil, err)
+	})
+
+	t.Run("Too many attempts", func(t *testing.T) {
+		email, userId := createTestUser(t)
+
+		c, err := GenerateCodes(ctx, email, userId, false, false)
+		if err != nil {
+			t.Errorf("Error while generating codes: %s", err)
+		}
+
+		for i := 0; i < backupCodeMaxAttempts; i++ {
+			VerifyCode(ctx, email, util.RandomString(8))
+		}
+
+		ok, err := VerifyCode(ctx, email, util.StringArrayToArray(c, 0))
+
+		Equal(t, false, ok)
+		Equal(t, ErrTooManyBackupCodeAttempts, err)
+	})
 }
 
 func TestRemoveCodes(t *testing.T) {
-	Setup()
+	ctx := context.Background()
 
-	email := util.StringWithCharset(30) + "@gmail.com"
+	Setup(config.MustLoad(nil))
 
-	_, err := GenerateCodes(email, 0, false, false)
+	email, userId := createTestUser(t)
+
+	_, err := GenerateCodes(ctx, email, userId, false, false)
 	if err != nil {
 		t.Errorf("Error while generating codes: %s", err)
 	}
 
-	hasBefore, err := HasCodes(email)
+	hasBefore, err := HasCodes(ctx, email)
 	if err != nil {
 		t.Errorf("Error while checking if the user already has backup codes: %s", err)
 	}
 
-	err = RemoveCodes(email)
+	err = RemoveCodes(ctx, email)
 	if err != nil {
 		t.Errorf("Error while deleting codes: %s", err)
 	}
 
-	hasAfter, err := HasCodes(email)
+	hasAfter, err := HasCodes(ctx, email)
 	if err != nil {
 		t.Errorf("Error while checking if the user already has backup codes: %s", err)
 	}
 
 	Equal(t, true, hasBefore)
-	Equal(t, true, hasAfter)
+	Equal(t, false, hasAfter)
 	Equal(t, nil, err)
 }
+
+func TestVerifyCodeForReset(t *testing.T) {
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
+
+	email, userId := createTestUser(t)
+
+	c, err := GenerateCodes(ctx, email, userId, false, false)
+	if err != nil {
+		t.Errorf("Error while generating codes: %s", err)
+	}
+
+	t.Run("Wrong code", func(t *testing.T) {
+		token, err := VerifyCodeForReset(ctx, email, util.RandomString(8))
+
+		Empty(t, token)
+		Nil(t, err)
+	})
+
+	t.Run("Reset with token", func(t *testing.T) {
+		token, err := VerifyCodeForReset(ctx, email, util.StringArrayToArray(c, 0))
+		if err != nil {
+			t.Errorf("Error while verifying the backup code: %s", err)
+		}
+
+		err = ResetPasswordWithToken(ctx, email, token, "Sdfkjhg83jhdfg!")
+		Nil(t, err)
+
+		err = ResetPasswordWithToken(ctx, email, token, "Sdfkjhg83jhdfg!2")
+		Equal(t, ErrInvalidResetToken, err)
+	})
+}
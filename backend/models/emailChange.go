@@ -0,0 +1,182 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/mailer"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidEmailChangeToken = e.New(http.StatusBadRequest, e.ERROR_EMAIL_CHANGE_TOKEN_INVALID)
+	ErrEmailTaken              = e.New(http.StatusConflict, e.ERROR_EMAIL_ALREADY_TAKEN)
+)
+
+// EmailChange is a pending change of the login email; only the sha256 hash of the token is stored
+type EmailChange struct {
+	Model
+
+	ID          int    `gorm:"primaryKey" json:"id"`
+	UserID      int    `gorm:"index;not null" json:"user_id"`
+	NewEmail    string `json:"new_email"`
+	TokenHash   string `gorm:"uniqueIndex" json:"-"`
+	ExpiresAt   int64  `json:"expires_at"`
+	ConfirmedAt int64  `gorm:"default:0" json:"confirmed_at"`
+}
+
+// RequestEmailChange sends a confirmation link to the new address and a notice to the old one.
+// Nothing changes until the link is opened; a new request replaces the pending one
+func RequestEmailChange(ctx context.Context, email, newEmail string) error {
+	if !validateEmail(newEmail) {
+		return ErrInvalidEmail
+	}
+
+	if email == newEmail {
+		return e.New(http.StatusBadRequest, e.ERROR_EMAIL_INVALID).WithDetail("error", "new email is the same as the current one")
+	}
+
+	taken, err := Exists(ctx, newEmail)
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		return ErrEmailTaken
+	}
+
+	token, err := createEmailChangeToken(ctx, email, newEmail)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/confirmemail?token=%s", appURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Use this link to confirm your new email address:\n\n%s\n\nThe link expires in %d minutes.", link, int(emailChangeTTL.Minutes()))
+	err = mailer.Send(newEmail, "Confirm your new email address", body)
+	if err != nil {
+		return err
+	}
+
+	body = fmt.Sprintf("Someone asked to change the email address of your account to %s. It only changes once the link sent to the new address is opened.\n\nIf this wasn't you, change your password right away.", newEmail)
+	return mailer.Send(email, "Your email address is about to change", body)
+}
+
+func createEmailChangeToken(ctx context.Context, email, newEmail string) (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	userId, err := GetUserIDByEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+
+	err = db.WithContext(ctx).Unscoped().Where("user_id = ?", userId).Delete(&EmailChange{}).Error
+	if err != nil {
+		return "", err
+	}
+
+	emailChange := EmailChange{
+		UserID:    userId,
+		NewEmail:  newEmail,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(emailChangeTTL).Unix(),
+	}
+
+	err = db.WithContext(ctx).Create(&emailChange).Error
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ConfirmEmailChange uses up the token and switches the account and the cache keys to the new email.
+// All sessions of the old email are invalidated
+func ConfirmEmailChange(ctx context.Context, token string) (string, error) {
+	var emailChange EmailChange
+	var oldEmail string
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&EmailChange{}).
+			Where("token_hash = ?", hashToken(token)).
+			Where("confirmed_at = 0").Where("expires_at > ?", time.Now().Unix()).
+			First(&emailChange).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidEmailChangeToken
+		}
+		if err != nil {
+			return err
+		}
+
+		//only one request can confirm the change, a concurrent one doesn't update any row
+		result := tx.Model(&EmailChange{}).Where("id = ?", emailChange.ID).Where("confirmed_at = 0").Update("confirmed_at", time.Now().Unix())
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected != 1 {
+			return ErrInvalidEmailChangeToken
+		}
+
+		err = tx.Model(&Auth{}).Where("id = ?", emailChange.UserID).Select("e_mail").First(&oldEmail).Error
+		if err != nil {
+			return err
+		}
+
+		return changeEmail(tx, emailChange.UserID, emailChange.NewEmail)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = cache.ChangeEmail(ctx, oldEmail, emailChange.NewEmail)
+	if err != nil {
+		return emailChange.NewEmail, err
+	}
+
+	notification := Notification{
+		UserID:           emailChange.UserID,
+		Title:            "Email changed",
+		Text:             "Your email address was changed to " + emailChange.NewEmail,
+		NotificationType: "email_changed",
+		Date:             time.Now().Format("02.01.2006"),
+	}
+
+	return emailChange.NewEmail, CreateNotification(ctx, notification)
+}
+
+// changeEmail rewrites the email of the account; everything else references the user id.
+// Pending password resets and email changes are dropped
+func changeEmail(tx *gorm.DB, userId int, newEmail string) error {
+	var taken bool
+	err := tx.Raw("SELECT EXISTS(SELECT id FROM auths WHERE e_mail = ?) AS found", newEmail).Scan(&taken).Error
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		return ErrEmailTaken
+	}
+
+	if err := tx.Model(&Auth{}).Where("id = ?", userId).Update("e_mail", newEmail).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&ResetPassword{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Unscoped().Where("user_id = ?", userId).Where("confirmed_at = 0").Delete(&EmailChange{}).Error
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/util"
+)
+
+func TestConfirmEmailChange(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	Setup(cfg)
+	cache.Setup(cfg)
+
+	email := util.StringWithCharset(10) + "@gmail.com"
+	newEmail := util.StringWithCharset(10) + "@gmail.com"
+	err := CreateAccount(ctx, email, util.StringWithCharset(20), util.StringWithCharset(20), "")
+	if err != nil {
+		t.Errorf("Error while creating account: %s", err)
+	}
+
+	userId, err := GetUserIDByEmail(ctx, email)
+	if err != nil {
+		t.Errorf("Error while getting user id: %s", err)
+	}
+
+	err = CreateList(ctx, Shoppinglist{ID: util.RandomIntWithLength(9000000), Title: util.StringWithCharset(10)}, userId, false)
+	if err != nil {
+		t.Errorf("Error while creating shoppinglist: %s", err)
+	}
+
+	_, err = GenerateCodes(ctx, email, userId, false, false)
+	if err != nil {
+		t.Errorf("Error while generating backup codes: %s", err)
+	}
+
+	token, err := createEmailChangeToken(ctx, email, newEmail)
+	if err != nil {
+		t.Fatalf("Error while creating email change token: %s", err)
+	}
+
+	t.Run("TestConfirmEmailChange", func(t *testing.T) {
+		changedTo, err := ConfirmEmailChange(ctx, token)
+		if err != nil {
+			t.Errorf("Error while confirming email change: %s", err)
+		}
+
+		oldExists, err := Exists(ctx, email)
+		if err != nil {
+			t.Errorf("Error while checking if the old email exists: %s", err)
+		}
+
+		newUserId, err := GetUserIDByEmail(ctx, newEmail)
+		if err != nil {
+			t.Errorf("Error while getting user id: %s", err)
+		}
+
+		lists, err := GetListByEmail(ctx, newEmail, 0)
+		if err != nil {
+			t.Errorf("Error while getting shoppinglists: %s", err)
+		}
+
+		remaining, err := CountRemainingCodes(ctx, newEmail)
+		if err != nil {
+			t.Errorf("Error while counting backup codes: %s", err)
+		}
+
+		Equal(t, newEmail, changedTo)
+		False(t, oldExists)
+		Equal(t, userId, newUserId)
+		Equal(t, 1, len(*lists))
+		Equal(t, int64(backupCodeCount), remaining)
+	})
+
+	t.Run("TestConfirmEmailChangeTwice", func(t *testing.T) {
+		_, err := ConfirmEmailChange(ctx, token)
+
+		Equal(t, ErrInvalidEmailChangeToken, err)
+	})
+
+	t.Run("TestRequestEmailChangeToTakenEmail", func(t *testing.T) {
+		other := util.StringWithCharset(10) + "@gmail.com"
+		err := CreateAccount(ctx, other, util.StringWithCharset(20), util.StringWithCharset(20), "")
+		if err != nil {
+			t.Errorf("Error while creating account: %s", err)
+		}
+
+		err = RequestEmailChange(ctx, newEmail, other)
+
+		Equal(t, ErrEmailTaken, err)
+	})
+}
@@ -1,7 +1,7 @@
 package models
 
 import (
-	"errors"
+	"context"
 )
 
 type Item struct {
@@ -15,43 +15,43 @@ type Item struct {
 	Bought       bool   `json:"bought" gorm:"default:false"`
 }
 
-func AddItem(item Item) (*Item, error) {
-	exists, err := ExistByID(item.ParentListID)
+func AddItem(ctx context.Context, item Item) (*Item, error) {
+	exists, err := ExistByID(ctx, item.ParentListID)
 	if err != nil || !exists {
-		return nil, errors.New("shoppinglist does not exist")
+		return nil, ErrListNotFound
 	}
 
-	err = db.Create(&item).Error
+	err = db.WithContext(ctx).Create(&item).Error
 	return &item, err
 }
 
-func DeleteItem(parentListId, id int) error {
-	exists, err := ExistByID(parentListId)
+func DeleteItem(ctx context.Context, parentListId, id int) error {
+	exists, err := ExistByID(ctx, parentListId)
 	if err != nil || !exists {
-		return errors.New("shoppinglist does not exist")
+		return ErrListNotFound
 	}
 
-	err = db.Model(&Item{}).Where("item_id = ?", id).Where("parent_list_id = ?", parentListId).Delete(&Item{ItemID: id, ParentListID: parentListId}).Error
+	err = db.WithContext(ctx).Model(&Item{}).Where("item_id = ?", id).Where("parent_list_id = ?", parentListId).Delete(&Item{ItemID: id, ParentListID: parentListId}).Error
 	return err
 }
 
-func UpdateItem(item Item) error {
-	exists, err := ExistByID(item.ParentListID)
+func UpdateItem(ctx context.Context, item Item) error {
+	exists, err := ExistByID(ctx, item.ParentListID)
 	if err != nil || !exists {
-		return errors.New("shoppinglist does not exist")
+		return ErrListNotFound
 	}
 
-	err = db.Model(&Item{}).Where("parent_list_id = ?", item.ParentListID).Where("item_id = ?", item.ItemID).Updates(&item).Error
+	err = db.WithContext(ctx).Model(&Item{}).Where("parent_list_id = ?", item.ParentListID).Where("item_id = ?", item.ItemID).Updates(&item).Error
 	return err
 }
 
-func UpdateItems(parentListId int, items []Item) error {
-	exists, err := ExistByID(parentListId)
+func UpdateItems(ctx context.Context, parentListId int, items []Item) error {
+	exists, err := ExistByID(ctx, parentListId)
 	if err != nil || !exists {
-		return errors.New("shoppinglist does not exist")
+		return ErrListNotFound
 	}
 
-	tx := db.Begin()
+	tx := db.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -70,21 +70,21 @@ func UpdateItems(parentListId int, items []Item) error {
 	return err
 }
 
-func GetItem(id, itemID int) (Item, error) {
+func GetItem(ctx context.Context, id, itemID int) (Item, error) {
 	var item Item
-	err := db.Model(&Item{}).Where("parent_list_id = ?", id).Where("item_id = ?", itemID).First(&item).Error
+	err := db.WithContext(ctx).Model(&Item{}).Where("parent_list_id = ?", id).Where("item_id = ?", itemID).First(&item).Error
 	return item, err
 }
 
-func GetItems(id int) ([]Item, error) {
+func GetItems(ctx context.Context, id int) ([]Item, error) {
 	var Items []Item
-	err := db.Model(&Item{}).Where("parent_list_id = ?", id).Find(&Items).Error
+	err := db.WithContext(ctx).Model(&Item{}).Where("parent_list_id = ?", id).Find(&Items).Error
 	return Items, err
 }
 
-func GetLastPosition(id int) (int64, error) {
+func GetLastPosition(ctx context.Context, id int) (int64, error) {
 	var Position int64
-	err := db.Model(&Item{}).Select("position").Where("parent_list_id = ?", id).Order("position asc").Find(&Position).Error
+	err := db.WithContext(ctx).Model(&Item{}).Select("position").Where("parent_list_id = ?", id).Order("position asc").Find(&Position).Error
 	if err != nil {
 		return 0, err
 	}
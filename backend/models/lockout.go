@@ -0,0 +1,66 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/mailer"
+	"github.com/urento/shoppinglist/pkg/util"
+)
+
+var ErrUnlockTokenUsed = e.New(http.StatusBadRequest, e.ERROR_UNLOCK_TOKEN_INVALID)
+
+// SendUnlockEmail sends a signed link to the user that lifts the login lockout
+func SendUnlockEmail(ctx context.Context, email string) error {
+	exists, err := Exists(ctx, email)
+	if err != nil || !exists {
+		return err
+	}
+
+	token, err := util.GenerateUnlockToken(email)
+	if err != nil {
+		return err
+	}
+
+	err = cache.SaveUnlockToken(ctx, hashUnlockToken(token), util.UnlockTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/unlock?token=%s", appURL, url.QueryEscape(token))
+	return sendUnlockEmail(email, link)
+}
+
+// UnlockAccount lifts the lockout of the account and of ip, the address the link was opened from. Every token can
+// only be used once
+func UnlockAccount(ctx context.Context, token, ip string) error {
+	email, err := util.ParseUnlockToken(token)
+	if err != nil {
+		return err
+	}
+
+	unused, err := cache.ConsumeUnlockToken(ctx, hashUnlockToken(token))
+	if err != nil {
+		return err
+	}
+	if !unused {
+		return ErrUnlockTokenUsed
+	}
+
+	return cache.ClearLockout(ctx, email, ip)
+}
+
+func hashUnlockToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func sendUnlockEmail(email, link string) error {
+	return mailer.Send(email, "Unlock your account", "Someone tried to log into your account too many times. Use this link to unlock it:\n\n"+link)
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	LoginMethodPassword   = "password"
+	LoginMethodTOTP       = "totp"
+	LoginMethodBackupCode = "backup_code"
+	LoginMethodWebAuthn   = "webauthn"
+	LoginMethodEmailOTP   = "email_otp"
+)
+
+type LoginEvent struct {
+	Model
+
+	ID        int    `gorm:"primaryKey" json:"id"`
+	UserID    int    `gorm:"index" json:"user_id"`
+	IPAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent"`
+	Success   bool   `json:"success"`
+	Method    string `json:"method"`
+}
+
+// RecordLogin saves the login attempt and notifies the user if it's the first successful login from this ip or device
+func RecordLogin(ctx context.Context, email, ip, userAgent, method string, success bool) error {
+	userId, err := GetUserIDByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	//don't record attempts for accounts that don't exist
+	if userId <= 0 {
+		return nil
+	}
+
+	newDevice := false
+	if success {
+		newDevice, err = isNewDevice(ctx, userId, ip, userAgent)
+		if err != nil {
+			return err
+		}
+	}
+
+	event := LoginEvent{
+		UserID:    userId,
+		IPAddress: ip,
+		UserAgent: userAgent,
+		Success:   success,
+		Method:    method,
+	}
+
+	err = db.WithContext(ctx).Create(&event).Error
+	if err != nil {
+		return err
+	}
+
+	if !newDevice {
+		return nil
+	}
+
+	notification := Notification{
+		UserID:           userId,
+		Title:            "New Login",
+		Text:             fmt.Sprintf("New login from %s (%s)", ip, userAgent),
+		NotificationType: "new_login",
+		Date:             time.Now().Format("02.01.2006"),
+	}
+
+	return CreateNotification(ctx, notification)
+}
+
+func isNewDevice(ctx context.Context, userId int, ip, userAgent string) (bool, error) {
+	var previous int64
+	err := db.WithContext(ctx).Model(&LoginEvent{}).Where("user_id = ?", userId).Where("success = ?", true).Count(&previous).Error
+	if err != nil {
+		return false, err
+	}
+
+	//the first login of an account is not a new device
+	if previous <= 0 {
+		return false, nil
+	}
+
+	var knownIP int64
+	err = db.WithContext(ctx).Model(&LoginEvent{}).
+		Where("user_id = ?", userId).Where("success = ?", true).Where("ip_address = ?", ip).
+		Limit(1).Count(&knownIP).Error
+	if err != nil {
+		return false, err
+	}
+
+	var knownDevice int64
+	err = db.WithContext(ctx).Model(&LoginEvent{}).
+		Where("user_id = ?", userId).Where("success = ?", true).Where("user_agent = ?", userAgent).
+		Limit(1).Count(&knownDevice).Error
+	if err != nil {
+		return false, err
+	}
+
+	return knownIP <= 0 || knownDevice <= 0, nil
+}
+
+func GetLoginEvents(ctx context.Context, userId int) ([]LoginEvent, error) {
+	exists, err := ExistsUserID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	var events []LoginEvent
+	err = db.WithContext(ctx).Model(&LoginEvent{}).Where("user_id = ?", userId).Order("created_on desc").Limit(50).Find(&events).Error
+	return events, err
+}
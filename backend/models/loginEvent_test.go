@@ -0,0 +1,71 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/util"
+)
+
+func TestRecordLogin(t *testing.T) {
+	ctx := context.Background()
+
+	SetupTestAuth()
+
+	user, err := CreateUser()
+	if err != nil {
+		t.Errorf("Error while creating user: %s", err)
+	}
+
+	ip := util.RandomIPAddress()
+	userAgent := util.StringWithCharset(50)
+
+	t.Run("Record Logins", func(t *testing.T) {
+		err := RecordLogin(ctx, user.EMail, ip, userAgent, LoginMethodPassword, false)
+		if err != nil {
+			t.Errorf("Error while recording login: %s", err)
+		}
+
+		err = RecordLogin(ctx, user.EMail, ip, userAgent, LoginMethodPassword, true)
+		if err != nil {
+			t.Errorf("Error while recording login: %s", err)
+		}
+
+		events, err := GetLoginEvents(ctx, user.ID)
+		if err != nil {
+			t.Errorf("Error while getting login events: %s", err)
+		}
+
+		hasUnread, err := HasUnreadNotifications(ctx, user.ID)
+		if err != nil {
+			t.Errorf("Error while checking for unread notifications: %s", err)
+		}
+
+		Equal(t, 2, len(events))
+		Equal(t, LoginMethodPassword, events[0].Method)
+		Equal(t, ip, events[0].IPAddress)
+		Equal(t, userAgent, events[0].UserAgent)
+		False(t, hasUnread)
+	})
+
+	t.Run("Record Login from new device", func(t *testing.T) {
+		err := RecordLogin(ctx, user.EMail, ip, util.StringWithCharset(50), LoginMethodTOTP, true)
+		if err != nil {
+			t.Errorf("Error while recording login: %s", err)
+		}
+
+		notifications, err := GetNotifications(ctx, user.ID)
+		if err != nil {
+			t.Errorf("Error while getting notifications: %s", err)
+		}
+
+		Equal(t, "new_login", notifications[0].NotificationType)
+	})
+
+	t.Run("Record Login when the user doesn't exist", func(t *testing.T) {
+		err := RecordLogin(ctx, util.RandomEmail(), ip, userAgent, LoginMethodPassword, true)
+
+		Nil(t, err)
+	})
+}
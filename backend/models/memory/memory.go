@@ -0,0 +1,768 @@
+// Package memory implements the stores of the models package in memory. It behaves like the gorm stores, including
+// their errors, so handlers can be tested without postgres
+package memory
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alexedwards/argon2id"
+	"github.com/urento/shoppinglist/models"
+	pwd "github.com/urento/shoppinglist/pkg/password"
+	"gorm.io/gorm"
+)
+
+type database struct {
+	mu sync.RWMutex
+
+	nextId        int
+	users         map[int]models.Auth
+	lists         map[int]models.Shoppinglist
+	items         map[int]models.Item
+	participants  map[int]models.Participant
+	notifications map[int]models.Notification
+}
+
+// NewStores returns empty stores that share one in-memory database
+func NewStores() models.Stores {
+	db := &database{
+		users:         map[int]models.Auth{},
+		lists:         map[int]models.Shoppinglist{},
+		items:         map[int]models.Item{},
+		participants:  map[int]models.Participant{},
+		notifications: map[int]models.Notification{},
+	}
+
+	return models.Stores{
+		Shoppinglists: &shoppinglistStore{db},
+		Items:         &itemStore{db},
+		Participants:  &participantStore{db},
+		Auth:          &authStore{db},
+		Notifications: &notificationStore{db},
+	}
+}
+
+func (db *database) id() int {
+	db.nextId++
+	return db.nextId
+}
+
+// userId returns 0 for unknown emails like models.GetUserIDByEmail
+func (db *database) userId(email string) int {
+	for id, user := range db.users {
+		if user.EMail == email {
+			return id
+		}
+	}
+	return 0
+}
+
+func (db *database) isDeactivated(userId int) bool {
+	return db.users[userId].DeactivatedOn > 0
+}
+
+func (db *database) participant(participant models.Participant) *models.Participant {
+	participant.Email = db.users[participant.UserID].EMail
+	if participant.RequestFromID != nil {
+		participant.RequestFrom = db.users[*participant.RequestFromID].EMail
+	}
+	return &participant
+}
+
+// list returns a copy of the list with its participants and, if withItems is set, its items
+func (db *database) list(list models.Shoppinglist, withItems bool) models.Shoppinglist {
+	list.Owner = db.users[list.OwnerID].EMail
+	list.Participants = nil
+	list.Items = nil
+
+	for _, id := range sortedIds(db.participants) {
+		if db.participants[id].ParentListID == list.ID {
+			list.Participants = append(list.Participants, db.participant(db.participants[id]))
+		}
+	}
+
+	if withItems {
+		for _, id := range sortedIds(db.items) {
+			if db.items[id].ParentListID == list.ID {
+				item := db.items[id]
+				list.Items = append(list.Items, &item)
+			}
+		}
+	}
+	return list
+}
+
+func sortedIds(rows interface{}) []int {
+	var ids []int
+	switch rows := rows.(type) {
+	case map[int]models.Shoppinglist:
+		for id := range rows {
+			ids = append(ids, id)
+		}
+	case map[int]models.Item:
+		for id := range rows {
+			ids = append(ids, id)
+		}
+	case map[int]models.Participant:
+		for id := range rows {
+			ids = append(ids, id)
+		}
+	case map[int]models.Notification:
+		for id := range rows {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+type shoppinglistStore struct {
+	db *database
+}
+
+func (s *shoppinglistStore) ExistByID(ctx context.Context, id int) (bool, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	_, ok := s.db.lists[id]
+	return ok, nil
+}
+
+func (s *shoppinglistStore) GetList(ctx context.Context, id int, owner string) (*models.Shoppinglist, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	list, ok := s.db.lists[id]
+	if !ok || list.OwnerID != s.db.userId(owner) {
+		return nil, models.ErrListNotFound
+	}
+
+	list = s.db.list(list, true)
+	return &list, nil
+}
+
+func (s *shoppinglistStore) GetListWithoutOwner(ctx context.Context, id int) (*models.Shoppinglist, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	list, ok := s.db.lists[id]
+	if !ok || s.db.isDeactivated(list.OwnerID) {
+		return nil, models.ErrListNotFound
+	}
+
+	list = s.db.list(list, true)
+	return &list, nil
+}
+
+func (s *shoppinglistStore) GetListByEmail(ctx context.Context, email string, offset int) (*[]models.Shoppinglist, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	userId := s.db.userId(email)
+	lists := []models.Shoppinglist{}
+	for _, id := range sortedIds(s.db.lists) {
+		if s.db.lists[id].OwnerID == userId {
+			lists = append(lists, s.db.list(s.db.lists[id], false))
+		}
+	}
+
+	if offset >= len(lists) {
+		lists = []models.Shoppinglist{}
+	} else {
+		lists = lists[offset:]
+	}
+
+	if len(lists) > 6 {
+		lists = lists[:6]
+	}
+	return &lists, nil
+}
+
+func (s *shoppinglistStore) GetListsByParticipant(ctx context.Context, email string) ([]models.Shoppinglist, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	userId := s.db.userId(email)
+	lists := []models.Shoppinglist{}
+	for _, id := range sortedIds(s.db.participants) {
+		participant := s.db.participants[id]
+		if participant.UserID != userId || participant.Status != "accepted" {
+			continue
+		}
+
+		list, ok := s.db.lists[participant.ParentListID]
+		if !ok || s.db.isDeactivated(list.OwnerID) {
+			continue
+		}
+		lists = append(lists, s.db.list(list, false))
+	}
+	return lists, nil
+}
+
+func (s *shoppinglistStore) CreateList(ctx context.Context, data models.Shoppinglist, userId int, withNotification bool) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userId]; !ok {
+		return models.ErrUserNotFound
+	}
+
+	if _, ok := s.db.lists[data.ID]; ok {
+		return errors.New("duplicate key value violates unique constraint \"shoppinglists_pkey\"")
+	}
+
+	if withNotification {
+		s.db.createNotification(models.Notification{
+			UserID:           userId,
+			Title:            "New Shoppinglist",
+			Text:             data.Title + " was created",
+			NotificationType: "new_shoppinglist",
+			Date:             time.Now().Format("02.01.2006"),
+		})
+	}
+
+	if data.ID == 0 {
+		data.ID = s.db.id()
+	}
+
+	data.OwnerID = userId
+	data.Owner = ""
+	data.Items = nil
+	data.Participants = nil
+	data.CreatedOn = int(time.Now().Unix())
+	s.db.lists[data.ID] = data
+	return nil
+}
+
+// EditList only changes the set fields like gorm's Updates
+func (s *shoppinglistStore) EditList(ctx context.Context, id int, data models.Shoppinglist) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	list, ok := s.db.lists[id]
+	if !ok {
+		return nil
+	}
+
+	if len(data.Title) > 0 {
+		list.Title = data.Title
+	}
+
+	if data.OwnerID > 0 {
+		list.OwnerID = data.OwnerID
+	}
+
+	s.db.lists[id] = list
+	return nil
+}
+
+func (s *shoppinglistStore) DeleteList(ctx context.Context, id int) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for itemId, item := range s.db.items {
+		if item.ParentListID == id {
+			delete(s.db.items, itemId)
+		}
+	}
+
+	delete(s.db.lists, id)
+	return nil
+}
+
+func (s *shoppinglistStore) BelongsShoppinglistToEmail(ctx context.Context, email string, id int) (bool, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	list, ok := s.db.lists[id]
+	return ok && list.OwnerID == s.db.userId(email), nil
+}
+
+type itemStore struct {
+	db *database
+}
+
+func (s *itemStore) AddItem(ctx context.Context, item models.Item) (*models.Item, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.lists[item.ParentListID]; !ok {
+		return nil, models.ErrListNotFound
+	}
+
+	if item.ID == 0 {
+		item.ID = s.db.id()
+	}
+
+	item.CreatedOn = int(time.Now().Unix())
+	s.db.items[item.ID] = item
+	return &item, nil
+}
+
+func (s *itemStore) GetItems(ctx context.Context, parentListId int) ([]models.Item, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	items := []models.Item{}
+	for _, id := range sortedIds(s.db.items) {
+		if s.db.items[id].ParentListID == parentListId {
+			items = append(items, s.db.items[id])
+		}
+	}
+	return items, nil
+}
+
+func (s *itemStore) UpdateItem(ctx context.Context, item models.Item) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.lists[item.ParentListID]; !ok {
+		return models.ErrListNotFound
+	}
+
+	s.db.updateItem(item.ParentListID, item)
+	return nil
+}
+
+func (s *itemStore) UpdateItems(ctx context.Context, parentListId int, items []models.Item) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.lists[parentListId]; !ok {
+		return models.ErrListNotFound
+	}
+
+	for _, item := range items {
+		s.db.updateItem(parentListId, item)
+	}
+	return nil
+}
+
+// updateItem only changes the set fields like gorm's Updates, so an item can't be marked as not bought this way
+func (db *database) updateItem(parentListId int, update models.Item) {
+	for id, item := range db.items {
+		if item.ParentListID != parentListId || item.ItemID != update.ItemID {
+			continue
+		}
+
+		if len(update.Title) > 0 {
+			item.Title = update.Title
+		}
+
+		if update.Position != 0 {
+			item.Position = update.Position
+		}
+
+		if update.Bought {
+			item.Bought = true
+		}
+
+		db.items[id] = item
+	}
+}
+
+func (s *itemStore) DeleteItem(ctx context.Context, parentListId, id int) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.lists[parentListId]; !ok {
+		return models.ErrListNotFound
+	}
+
+	for itemId, item := range s.db.items {
+		if item.ParentListID == parentListId && item.ItemID == id {
+			delete(s.db.items, itemId)
+		}
+	}
+	return nil
+}
+
+type participantStore struct {
+	db *database
+}
+
+func (s *participantStore) AddParticipant(ctx context.Context, participant models.Participant) (models.Participant, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.lists[participant.ParentListID]; !ok {
+		return models.Participant{}, models.ErrListNotFound
+	}
+
+	participant.UserID = s.db.userId(participant.Email)
+	if participant.UserID <= 0 {
+		return models.Participant{}, models.ErrUserNotFound
+	}
+
+	participant.RequestFromID = nil
+	if requestFromId := s.db.userId(participant.RequestFrom); requestFromId > 0 {
+		participant.RequestFromID = &requestFromId
+	}
+
+	if len(participant.Status) <= 0 {
+		participant.Status = "pending"
+	}
+
+	if participant.ID == 0 {
+		participant.ID = s.db.id()
+	}
+
+	participant.CreatedOn = int(time.Now().Unix())
+	s.db.participants[participant.ID] = participant
+	return *s.db.participant(participant), nil
+}
+
+func (s *participantStore) RemoveParticipant(ctx context.Context, parentListId, id int) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.lists[parentListId]; !ok {
+		return models.ErrListNotFound
+	}
+
+	if s.db.participants[id].ParentListID == parentListId {
+		delete(s.db.participants, id)
+	}
+	return nil
+}
+
+func (s *participantStore) GetParticipants(ctx context.Context, parentListId int) ([]models.Participant, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	if _, ok := s.db.lists[parentListId]; !ok {
+		return nil, models.ErrListNotFound
+	}
+
+	return s.db.findParticipants(func(p models.Participant) bool { return p.ParentListID == parentListId }), nil
+}
+
+func (db *database) findParticipants(match func(p models.Participant) bool) []models.Participant {
+	participants := []models.Participant{}
+	for _, id := range sortedIds(db.participants) {
+		if match(db.participants[id]) {
+			participants = append(participants, *db.participant(db.participants[id]))
+		}
+	}
+	return participants
+}
+
+func (s *participantStore) IsParticipantAlreadyIncluded(ctx context.Context, email string, parentListId int) (bool, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	userId := s.db.userId(email)
+	participants := s.db.findParticipants(func(p models.Participant) bool {
+		return p.ParentListID == parentListId && p.UserID == userId
+	})
+	return len(participants) > 0, nil
+}
+
+func (s *participantStore) GetPendingRequests(ctx context.Context, email string) ([]models.Participant, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	userId := s.db.userId(email)
+	return s.db.findParticipants(func(p models.Participant) bool {
+		return p.Status == "pending" && p.UserID == userId
+	}), nil
+}
+
+func (s *participantStore) GetPendingRequestsFromShoppinglist(ctx context.Context, email string, id int) ([]models.Participant, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	list, ok := s.db.lists[id]
+	if !ok || list.OwnerID != s.db.userId(email) {
+		return []models.Participant{}, models.ErrListNotOwned
+	}
+
+	return s.db.findParticipants(func(p models.Participant) bool {
+		return p.Status == "pending" && p.ParentListID == id
+	}), nil
+}
+
+func (s *participantStore) AcceptRequest(ctx context.Context, id int, email string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	participant, ok := s.db.participants[id]
+	if !ok || participant.UserID != s.db.userId(email) {
+		return nil
+	}
+
+	participant.Status = "accepted"
+	participant.RequestFromID = nil
+	s.db.participants[id] = participant
+	return nil
+}
+
+func (s *participantStore) DeleteRequest(ctx context.Context, id int, email string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if participant, ok := s.db.participants[id]; ok && participant.UserID == s.db.userId(email) {
+		delete(s.db.participants, id)
+	}
+	return nil
+}
+
+func (s *participantStore) DeleteAll(ctx context.Context, email string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	userId := s.db.userId(email)
+	for id, participant := range s.db.participants {
+		if participant.UserID == userId {
+			delete(s.db.participants, id)
+		}
+	}
+	return nil
+}
+
+func (s *participantStore) LeaveShoppinglist(ctx context.Context, id int, email string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	userId := s.db.userId(email)
+	for participantId, participant := range s.db.participants {
+		if participant.ParentListID == id && participant.UserID == userId && participant.Status == "accepted" {
+			delete(s.db.participants, participantId)
+		}
+	}
+	return nil
+}
+
+type authStore struct {
+	db *database
+}
+
+func (s *authStore) CreateAccount(ctx context.Context, email, username, password, ip string) error {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return models.ErrInvalidEmail
+	}
+
+	if err := pwd.Validate(email, password); err != nil {
+		return err
+	}
+
+	passwordHash, err := pwd.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if s.db.userId(email) > 0 {
+		return models.ErrEmailTaken
+	}
+
+	id := s.db.id()
+	s.db.users[id] = models.Auth{
+		Model:           models.Model{CreatedOn: int(time.Now().Unix())},
+		ID:              id,
+		EMail:           email,
+		Password:        passwordHash,
+		Username:        username,
+		Rank:            "default",
+		TwoFactorMethod: models.TwoFactorMethodTOTP,
+		IPAddress:       ip,
+	}
+	return nil
+}
+
+func (s *authStore) user(email string) (models.Auth, error) {
+	user, ok := s.db.users[s.db.userId(email)]
+	if !ok {
+		return models.Auth{}, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (s *authStore) Exists(ctx context.Context, email string) (bool, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	return s.db.userId(email) > 0, nil
+}
+
+func (s *authStore) GetUser(ctx context.Context, email string) (*models.Auth, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	user, err := s.user(email)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Password = ""
+	user.IPAddress = ""
+	return &user, nil
+}
+
+func (s *authStore) GetUserIDByEmail(ctx context.Context, email string) (int, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	return s.db.userId(email), nil
+}
+
+func (s *authStore) GetRank(ctx context.Context, email string) (string, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	user, err := s.user(email)
+	return user.Rank, err
+}
+
+func (s *authStore) CheckPassword(ctx context.Context, email, password string) (bool, error) {
+	s.db.mu.RLock()
+	user, err := s.user(email)
+	s.db.mu.RUnlock()
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := argon2id.ComparePasswordAndHash(password, user.Password)
+	return ok && err == nil, nil
+}
+
+func (s *authStore) IsTwoFactorEnabled(ctx context.Context, email string) (bool, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	user, err := s.user(email)
+	return user.TwoFactorAuthentication, err
+}
+
+func (s *authStore) GetTwoFactorMethod(ctx context.Context, email string) (string, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	user, err := s.user(email)
+	return user.TwoFactorMethod, err
+}
+
+func (s *authStore) IsDeactivated(ctx context.Context, email string) (bool, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	return s.db.isDeactivated(s.db.userId(email)), nil
+}
+
+type notificationStore struct {
+	db *database
+}
+
+func (db *database) createNotification(notification models.Notification) {
+	if notification.ID == 0 {
+		notification.ID = db.id()
+	}
+
+	notification.CreatedOn = int(time.Now().Unix())
+	db.notifications[notification.ID] = notification
+}
+
+func (s *notificationStore) CreateNotification(ctx context.Context, notification models.Notification) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[notification.UserID]; !ok {
+		return models.ErrUserNotFound
+	}
+
+	s.db.createNotification(notification)
+	return nil
+}
+
+// notifications returns the newest 50 notifications of the user
+func (s *notificationStore) notifications(userId int) ([]models.Notification, error) {
+	if _, ok := s.db.users[userId]; !ok {
+		return nil, models.ErrUserNotFound
+	}
+
+	ids := sortedIds(s.db.notifications)
+	notifications := []models.Notification{}
+	for i := len(ids) - 1; i >= 0 && len(notifications) < 50; i-- {
+		if s.db.notifications[ids[i]].UserID == userId {
+			notifications = append(notifications, s.db.notifications[ids[i]])
+		}
+	}
+	return notifications, nil
+}
+
+func (s *notificationStore) HasUnreadNotifications(ctx context.Context, userId int) (bool, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	notifications, err := s.notifications(userId)
+	if err != nil {
+		return false, err
+	}
+
+	for _, notification := range notifications {
+		if !notification.Read {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *notificationStore) GetNotifications(ctx context.Context, userId int) ([]models.Notification, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	return s.notifications(userId)
+}
+
+// GetNotification returns an empty notification if it doesn't exist, like the gorm store
+func (s *notificationStore) GetNotification(ctx context.Context, userId, id int) (models.Notification, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	if _, ok := s.db.users[userId]; !ok {
+		return models.Notification{}, models.ErrUserNotFound
+	}
+
+	notification, ok := s.db.notifications[id]
+	if !ok || notification.UserID != userId {
+		return models.Notification{}, nil
+	}
+	return notification, nil
+}
+
+func (s *notificationStore) DeleteNotification(ctx context.Context, userId, id int) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userId]; !ok {
+		return models.ErrUserNotFound
+	}
+
+	if s.db.notifications[id].UserID == userId {
+		delete(s.db.notifications, id)
+	}
+	return nil
+}
+
+func (s *notificationStore) MarkAllNotificationsAsRead(ctx context.Context, userId int) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userId]; !ok {
+		return models.ErrUserNotFound
+	}
+
+	for id, notification := range s.db.notifications {
+		if notification.UserID == userId {
+			notification.Read = true
+			s.db.notifications[id] = notification
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/models"
+)
+
+func createUser(t *testing.T, stores models.Stores, email string) int {
+	err := stores.Auth.CreateAccount(context.Background(), email, "username", "WbHu9+kLr!3xZpQa", "")
+	if err != nil {
+		t.Fatalf("Error while creating account: %s", err)
+	}
+
+	userId, _ := stores.Auth.GetUserIDByEmail(context.Background(), email)
+	return userId
+}
+
+func TestShoppinglists(t *testing.T) {
+	ctx := context.Background()
+
+	stores := NewStores()
+	owner := "owner@gmail.com"
+	ownerId := createUser(t, stores, owner)
+
+	err := stores.Shoppinglists.CreateList(ctx, models.Shoppinglist{ID: 1, Title: "Groceries"}, ownerId, true)
+	if err != nil {
+		t.Errorf("Error while creating shoppinglist: %s", err)
+	}
+
+	t.Run("TestGetList", func(t *testing.T) {
+		list, err := stores.Shoppinglists.GetList(ctx, 1, owner)
+		if err != nil {
+			t.Errorf("Error while getting shoppinglist: %s", err)
+		}
+
+		Equal(t, "Groceries", list.Title)
+		Equal(t, owner, list.Owner)
+		Equal(t, ownerId, list.OwnerID)
+	})
+
+	t.Run("TestGetListOfOtherOwner", func(t *testing.T) {
+		_, err := stores.Shoppinglists.GetList(ctx, 1, "other@gmail.com")
+
+		Equal(t, models.ErrListNotFound, err)
+	})
+
+	t.Run("TestCreateListWithNotification", func(t *testing.T) {
+		has, err := stores.Notifications.HasUnreadNotifications(ctx, ownerId)
+		if err != nil {
+			t.Errorf("Error while checking for notifications: %s", err)
+		}
+
+		True(t, has)
+	})
+
+	t.Run("TestDeleteList", func(t *testing.T) {
+		err := stores.Shoppinglists.DeleteList(ctx, 1)
+		if err != nil {
+			t.Errorf("Error while deleting shoppinglist: %s", err)
+		}
+
+		exists, _ := stores.Shoppinglists.ExistByID(ctx, 1)
+		False(t, exists)
+	})
+}
+
+func TestParticipants(t *testing.T) {
+	ctx := context.Background()
+
+	stores := NewStores()
+	owner := "owner@gmail.com"
+	participant := "participant@gmail.com"
+	ownerId := createUser(t, stores, owner)
+	participantId := createUser(t, stores, participant)
+
+	err := stores.Shoppinglists.CreateList(ctx, models.Shoppinglist{ID: 1, Title: "Groceries"}, ownerId, false)
+	if err != nil {
+		t.Errorf("Error while creating shoppinglist: %s", err)
+	}
+
+	added, err := stores.Participants.AddParticipant(ctx, models.Participant{ParentListID: 1, Email: participant, RequestFrom: owner})
+	if err != nil {
+		t.Errorf("Error while adding participant: %s", err)
+	}
+
+	Equal(t, participantId, added.UserID)
+	Equal(t, "pending", added.Status)
+	Equal(t, owner, added.RequestFrom)
+
+	t.Run("TestAddUnknownParticipant", func(t *testing.T) {
+		_, err := stores.Participants.AddParticipant(ctx, models.Participant{ParentListID: 1, Email: "unknown@gmail.com"})
+
+		NotNil(t, err)
+	})
+
+	t.Run("TestAcceptRequest", func(t *testing.T) {
+		err := stores.Participants.AcceptRequest(ctx, added.ID, participant)
+		if err != nil {
+			t.Errorf("Error while accepting request: %s", err)
+		}
+
+		lists, err := stores.Shoppinglists.GetListsByParticipant(ctx, participant)
+		if err != nil {
+			t.Errorf("Error while getting shoppinglists: %s", err)
+		}
+
+		Equal(t, 1, len(lists))
+		Equal(t, owner, lists[0].Owner)
+		Equal(t, participant, lists[0].Participants[0].Email)
+		Nil(t, lists[0].Participants[0].RequestFromID)
+	})
+
+	t.Run("TestLeaveShoppinglist", func(t *testing.T) {
+		err := stores.Participants.LeaveShoppinglist(ctx, 1, participant)
+		if err != nil {
+			t.Errorf("Error while leaving shoppinglist: %s", err)
+		}
+
+		included, _ := stores.Participants.IsParticipantAlreadyIncluded(ctx, participant, 1)
+		False(t, included)
+	})
+}
+
+func TestAuth(t *testing.T) {
+	ctx := context.Background()
+
+	stores := NewStores()
+	email := "user@gmail.com"
+	createUser(t, stores, email)
+
+	t.Run("TestCreateAccountTwice", func(t *testing.T) {
+		err := stores.Auth.CreateAccount(ctx, email, "username", "WbHu9+kLr!3xZpQa", "")
+
+		NotNil(t, err)
+	})
+
+	t.Run("TestCheckPassword", func(t *testing.T) {
+		ok, err := stores.Auth.CheckPassword(ctx, email, "WbHu9+kLr!3xZpQa")
+		if err != nil {
+			t.Errorf("Error while checking password: %s", err)
+		}
+
+		wrong, _ := stores.Auth.CheckPassword(ctx, email, "wrong password")
+
+		True(t, ok)
+		False(t, wrong)
+	})
+
+	t.Run("TestGetUser", func(t *testing.T) {
+		user, err := stores.Auth.GetUser(ctx, email)
+		if err != nil {
+			t.Errorf("Error while getting user: %s", err)
+		}
+
+		Equal(t, email, user.EMail)
+		Equal(t, "default", user.Rank)
+		Empty(t, user.Password)
+	})
+}
@@ -0,0 +1,214 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/urento/shoppinglist/pkg/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// migrationLockKey is the postgres advisory lock that serializes migrations of instances starting at the same time
+const migrationLockKey = 7361839201
+
+var ErrIrreversibleMigration = errors.New("migration can't be reverted")
+
+// Migration is one numbered schema change. Up and Down run inside the transaction that holds the migration lock
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration is a row of schema_migrations, one per applied migration
+type SchemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string `json:"name"`
+	AppliedAt int64  `json:"applied_at"`
+}
+
+// MigrationStatus is returned by `migrate status`; AppliedAt is 0 for pending migrations
+type MigrationStatus struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	AppliedAt int64  `json:"applied_at"`
+}
+
+// sqlMigration builds a migration from plain statements; a migration without down statements can't be reverted
+func sqlMigration(version int, name string, up []string, down []string) Migration {
+	migration := Migration{Version: version, Name: name, Up: execStatements(up)}
+	if len(down) > 0 {
+		migration.Down = execStatements(down)
+	}
+	return migration
+}
+
+func execStatements(statements []string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func validateMigrations(migrations []Migration) error {
+	for i, migration := range migrations {
+		if migration.Version <= 0 {
+			return fmt.Errorf("migration %q has no version", migration.Name)
+		}
+
+		if migration.Up == nil {
+			return fmt.Errorf("migration %d has no up step", migration.Version)
+		}
+
+		if i > 0 && migration.Version <= migrations[i-1].Version {
+			return fmt.Errorf("migration %d is out of order or duplicated", migration.Version)
+		}
+	}
+	return nil
+}
+
+// withMigrationLock runs fn in a transaction that holds the advisory lock; the lock is released with the transaction
+func withMigrationLock(fn func(tx *gorm.DB) error) error {
+	if err := validateMigrations(migrations); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+			return err
+		}
+
+		err := tx.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version bigint PRIMARY KEY, name text NOT NULL, applied_at bigint NOT NULL)").Error
+		if err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
+
+func appliedMigrations(tx *gorm.DB) (map[int]SchemaMigration, error) {
+	var rows []SchemaMigration
+	if err := tx.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// MigrateUp applies all pending migrations in order and returns how many ran.
+// Either all of them are applied or none
+func MigrateUp() (int, error) {
+	count := 0
+	err := withMigrationLock(func(tx *gorm.DB) error {
+		applied, err := appliedMigrations(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			if err := migration.Up(tx); err != nil {
+				return fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, err)
+			}
+
+			row := SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().Unix()}
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+
+			logging.L().Info("applied migration", zap.Int("version", migration.Version), zap.String("name", migration.Name))
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// MigrateDown reverts the last steps applied migrations, newest first
+func MigrateDown(steps int) (int, error) {
+	count := 0
+	err := withMigrationLock(func(tx *gorm.DB) error {
+		applied, err := appliedMigrations(tx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && count < steps; i-- {
+			migration := migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			if migration.Down == nil {
+				return fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, ErrIrreversibleMigration)
+			}
+
+			if err := migration.Down(tx); err != nil {
+				return fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, err)
+			}
+
+			if err := tx.Delete(&SchemaMigration{}, migration.Version).Error; err != nil {
+				return err
+			}
+
+			logging.L().Info("reverted migration", zap.Int("version", migration.Version), zap.String("name", migration.Name))
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetMigrationStatus lists every known migration and when it was applied, including applied versions this build
+// doesn't know about
+func GetMigrationStatus() ([]MigrationStatus, error) {
+	var status []MigrationStatus
+	err := withMigrationLock(func(tx *gorm.DB) error {
+		applied, err := appliedMigrations(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			status = append(status, MigrationStatus{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: applied[migration.Version].AppliedAt,
+			})
+			delete(applied, migration.Version)
+		}
+
+		for _, row := range applied {
+			status = append(status, MigrationStatus{Version: row.Version, Name: row.Name, AppliedAt: row.AppliedAt})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+	return status, nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
+	"gorm.io/gorm"
+)
+
+func TestValidateMigrations(t *testing.T) {
+	noop := func(tx *gorm.DB) error { return nil }
+
+	t.Run("TestRegisteredMigrations", func(t *testing.T) {
+		Nil(t, validateMigrations(migrations))
+	})
+
+	t.Run("TestOutOfOrder", func(t *testing.T) {
+		err := validateMigrations([]Migration{
+			{Version: 2, Name: "second", Up: noop},
+			{Version: 1, Name: "first", Up: noop},
+		})
+
+		NotNil(t, err)
+	})
+
+	t.Run("TestDuplicated", func(t *testing.T) {
+		err := validateMigrations([]Migration{
+			{Version: 1, Name: "first", Up: noop},
+			{Version: 1, Name: "again", Up: noop},
+		})
+
+		NotNil(t, err)
+	})
+
+	t.Run("TestMissingUp", func(t *testing.T) {
+		err := validateMigrations([]Migration{{Version: 1, Name: "first"}})
+
+		NotNil(t, err)
+	})
+}
+
+func TestMigrationStatus(t *testing.T) {
+	Setup(config.MustLoad(nil))
+
+	status, err := GetMigrationStatus()
+	if err != nil {
+		t.Errorf("Error while getting the migration status: %s", err)
+	}
+
+	Equal(t, len(migrations), len(status))
+	for _, migration := range status {
+		Greater(t, migration.AppliedAt, int64(0))
+	}
+}
@@ -0,0 +1,185 @@
+package models
+
+// migrations are applied in order of their version. Never change or remove a migration that was released,
+// add a new one instead
+var migrations = []Migration{
+	{Version: 1, Name: "hash_legacy_backup_codes", Up: migrateLegacyBackupCodes},
+	{Version: 2, Name: "drop_legacy_reset_passwords", Up: migrateLegacyResetPasswords},
+	{Version: 3, Name: "reference_users_by_id", Up: migrateEmailReferences},
+	sqlMigration(4, "initial_schema", initialSchemaUp, nil),
+	{
+		Version: 5,
+		Name:    "user_foreign_keys",
+		Up:      createUserConstraints,
+		Down:    dropUserConstraints,
+	},
+	{
+		Version: 6,
+		Name:    "login_event_and_webauthn_foreign_keys",
+		Up:      createLateUserConstraints,
+		Down:    dropLateUserConstraints,
+	},
+	{Version: 7, Name: "reference_totp_secrets_by_user_id", Up: migrateTOTPSecretOwners},
+}
+
+// initialSchemaUp is the schema AutoMigrate created before migrations were versioned. It only creates what's
+// missing, so databases that were set up by AutoMigrate keep their tables. CREATE TABLE IF NOT EXISTS skips the
+// columns that were added to these tables later, they are added explicitly. It can't be reverted, the tables
+// may have existed before and dropping them would delete the data
+var initialSchemaUp = []string{
+	`CREATE TABLE IF NOT EXISTS auths (
+		id bigserial PRIMARY KEY,
+		created_on bigint,
+		modified_on bigint,
+		deleted_at timestamptz,
+		e_mail text,
+		email_verified boolean,
+		username text,
+		password text,
+		rank text,
+		two_factor_authentication boolean,
+		two_factor_method text DEFAULT 'totp',
+		ip_address text,
+		disabled boolean DEFAULT false,
+		deactivated_on bigint DEFAULT 0
+	)`,
+	`ALTER TABLE auths ADD COLUMN IF NOT EXISTS two_factor_method text DEFAULT 'totp'`,
+	`ALTER TABLE auths ADD COLUMN IF NOT EXISTS deactivated_on bigint DEFAULT 0`,
+	`CREATE INDEX IF NOT EXISTS idx_auths_deleted_at ON auths (deleted_at)`,
+
+	`CREATE TABLE IF NOT EXISTS shoppinglists (
+		id bigserial PRIMARY KEY,
+		created_on bigint,
+		modified_on bigint,
+		deleted_at timestamptz,
+		title text,
+		owner_id bigint NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_shoppinglists_deleted_at ON shoppinglists (deleted_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_shoppinglists_owner_id ON shoppinglists (owner_id)`,
+
+	`CREATE TABLE IF NOT EXISTS items (
+		id bigserial PRIMARY KEY,
+		created_on bigint,
+		modified_on bigint,
+		deleted_at timestamptz,
+		parent_list_id bigint,
+		item_id bigint,
+		title text,
+		position bigint,
+		bought boolean DEFAULT false
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_items_deleted_at ON items (deleted_at)`,
+
+	`CREATE TABLE IF NOT EXISTS participants (
+		id bigserial PRIMARY KEY,
+		created_on bigint,
+		modified_on bigint,
+		deleted_at timestamptz,
+		parent_list_id bigint,
+		status text DEFAULT 'pending',
+		user_id bigint NOT NULL,
+		request_from_id bigint
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_participants_deleted_at ON participants (deleted_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_participants_user_id ON participants (user_id)`,
+
+	`CREATE TABLE IF NOT EXISTS reset_passwords (
+		id bigserial PRIMARY KEY,
+		created_on bigint,
+		modified_on bigint,
+		deleted_at timestamptz,
+		user_id bigint NOT NULL,
+		token_hash text,
+		expires_at bigint,
+		used_at bigint DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_reset_passwords_deleted_at ON reset_passwords (deleted_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_reset_passwords_user_id ON reset_passwords (user_id)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_reset_passwords_token_hash ON reset_passwords (token_hash)`,
+
+	`CREATE TABLE IF NOT EXISTS backup_codes (
+		id bigserial PRIMARY KEY,
+		created_on bigint,
+		modified_on bigint,
+		deleted_at timestamptz,
+		user_id bigint NOT NULL,
+		code_hash text,
+		used_at bigint DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_backup_codes_deleted_at ON backup_codes (deleted_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_backup_codes_user_id ON backup_codes (user_id)`,
+
+	`CREATE TABLE IF NOT EXISTS notifications (
+		id bigserial PRIMARY KEY,
+		created_on bigint,
+		modified_on bigint,
+		deleted_at timestamptz,
+		user_id bigint,
+		notification_type text,
+		title text,
+		text text,
+		read boolean DEFAULT false,
+		date text
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_notifications_deleted_at ON notifications (deleted_at)`,
+
+	`CREATE TABLE IF NOT EXISTS login_events (
+		id bigserial PRIMARY KEY,
+		created_on bigint,
+		modified_on bigint,
+		deleted_at timestamptz,
+		user_id bigint,
+		ip_address text,
+		user_agent text,
+		success boolean,
+		method text
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_login_events_deleted_at ON login_events (deleted_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_login_events_user_id ON login_events (user_id)`,
+
+	`CREATE TABLE IF NOT EXISTS totp_secrets (
+		id bigserial PRIMARY KEY,
+		created_on bigint,
+		modified_on bigint,
+		deleted_at timestamptz,
+		owner text,
+		encrypted_key text,
+		encrypted_secret text
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_totp_secrets_deleted_at ON totp_secrets (deleted_at)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_totp_secrets_owner ON totp_secrets (owner)`,
+
+	`CREATE TABLE IF NOT EXISTS web_authn_credentials (
+		id bigserial PRIMARY KEY,
+		created_on bigint,
+		modified_on bigint,
+		deleted_at timestamptz,
+		user_id bigint,
+		name text,
+		credential_id bytea,
+		public_key bytea,
+		attestation_type text,
+		aaguid bytea,
+		sign_count bigint,
+		last_used_on bigint
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_web_authn_credentials_deleted_at ON web_authn_credentials (deleted_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_web_authn_credentials_user_id ON web_authn_credentials (user_id)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_web_authn_credentials_credential_id ON web_authn_credentials (credential_id)`,
+
+	`CREATE TABLE IF NOT EXISTS email_changes (
+		id bigserial PRIMARY KEY,
+		created_on bigint,
+		modified_on bigint,
+		deleted_at timestamptz,
+		user_id bigint NOT NULL,
+		new_email text,
+		token_hash text,
+		expires_at bigint,
+		confirmed_at bigint DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_email_changes_deleted_at ON email_changes (deleted_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_email_changes_user_id ON email_changes (user_id)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_email_changes_token_hash ON email_changes (token_hash)`,
+}
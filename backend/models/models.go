@@ -1,77 +1,140 @@
 package models
 
 import (
-	"log"
-	"os"
+	"context"
 	"time"
 
-	"github.com/joho/godotenv"
-	utils "github.com/urento/shoppinglist/pkg"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/envelope"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"github.com/urento/shoppinglist/pkg/metrics"
+	"github.com/urento/shoppinglist/pkg/password"
+	"github.com/urento/shoppinglist/pkg/tracing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 var db *gorm.DB
 
+var accountDeletionGracePeriod time.Duration
+
+var resetPasswordTTL time.Duration
+
+var emailChangeTTL time.Duration
+
+// appURL is the address of the frontend, used for the links in emails
+var appURL string
+
 type Model struct {
 	CreatedOn  int            `gorm:"autoCreateTime" json:"created_on"`
 	ModifiedOn int            `gorm:"autoUpdateTime:milli" json:"modified_on"`
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
-func Setup() {
-	var err error
-
-	if utils.PROD {
-		err = godotenv.Load()
-	} else if utils.GITHUB_TESTING {
-		err = nil
-	} else if utils.LOCAL_TESTING {
-		err = godotenv.Load("../.env")
-	} else {
-		err = godotenv.Load()
+// Setup connects to the database and applies the pending migrations. Set MIGRATE_ON_START=false to only run
+// them with `migrate up`
+func Setup(cfg *config.Config) {
+	Connect(cfg)
+
+	if cfg.Database.MigrateOnStart {
+		_, err := MigrateUp()
+		if err != nil {
+			panic(err)
+		}
 	}
 
+	if cfg.IsDevelopment() {
+		autoMigrate()
+	}
+}
+
+// Connect opens the database without touching the schema
+func Connect(cfg *config.Config) {
+	password.Setup(cfg)
+
+	err := envelope.Setup(cfg)
 	if err != nil {
-		//log.Fatal(err)
 		panic(err)
 	}
 
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-		logger.Config{
-			SlowThreshold:             time.Second,   // Slow SQL threshold
-			LogLevel:                  logger.Silent, // Log level
-			IgnoreRecordNotFoundError: false,         // Ignore ErrRecordNotFound error for logger
-			Colorful:                  true,          // Disable color
-		},
-	)
+	appURL = cfg.AppURL
+	accountDeletionGracePeriod = cfg.Account.DeletionGracePeriod()
+	resetPasswordTTL = cfg.Account.ResetPasswordTTL()
+	emailChangeTTL = cfg.Account.EmailChangeTTL()
 
-	db, err = gorm.Open(postgres.New(postgres.Config{
-		DSN:                  os.Getenv("DATABASE_DSN"),
+	db, err = gorm.Open(postgresDialector{&postgres.Dialector{Config: &postgres.Config{
+		DSN:                  cfg.Database.DSN,
 		PreferSimpleProtocol: true,
-	}), &gorm.Config{
-		Logger: newLogger,
+	}}}, &gorm.Config{
+		Logger: logging.NewGormLogger(cfg.Log.SlowQueryThreshold()),
 	})
 	if err != nil {
 		//log.Fatalf("Error while connecting to database: %s", err)
 		panic(err)
 	}
 
-	db.AutoMigrate(
+	err = db.Use(metrics.GormPlugin{})
+	if err != nil {
+		panic(err)
+	}
+
+	err = db.Use(tracing.GormPlugin{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = db.DB()
+	if err != nil {
+		//log.Fatalf("Error while connecting to database: %s", err)
+		panic(err)
+	}
+}
+
+// postgresDialector keeps the placeholders in the statements that are logged, the bound values contain emails,
+// token hashes and other personal data
+type postgresDialector struct {
+	*postgres.Dialector
+}
+
+func (postgresDialector) Explain(sql string, vars ...interface{}) string {
+	return sql
+}
+
+// Ping checks if the database can be reached, for the readiness check
+func Ping(ctx context.Context) error {
+	sqlDB, err := db.WithContext(ctx).DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close closes the connection pool, the in-flight requests have to be done before
+func Close() error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// autoMigrate adds the columns and tables of changed models without a migration, to try out changes locally.
+// Everything that has to be deployed needs a migration in migrations.go
+func autoMigrate() {
+	err := db.AutoMigrate(
 		&Shoppinglist{},
 		&Auth{},
 		&ResetPassword{},
 		&Item{},
-		&BackupCodes{},
+		&BackupCode{},
 		&Participant{},
 		&Notification{},
+		&LoginEvent{},
+		&TOTPSecret{},
+		&WebAuthnCredential{},
+		&EmailChange{},
 	)
-
-	_, err = db.DB()
 	if err != nil {
-		//log.Fatalf("Error while connecting to database: %s", err)
 		panic(err)
 	}
 }
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+)
+
+func TestExplainWithoutValues(t *testing.T) {
+	dialector := postgresDialector{&postgres.Dialector{Config: &postgres.Config{}}}
+
+	sql := dialector.Explain("SELECT * FROM auths WHERE e_mail = $1", "user@example.com")
+
+	Equal(t, "SELECT * FROM auths WHERE e_mail = $1", sql)
+}
@@ -1,7 +1,7 @@
 package models
 
 import (
-	"errors"
+	"context"
 )
 
 type Notification struct {
@@ -16,32 +16,32 @@ type Notification struct {
 	Date             string `json:"date"`
 }
 
-func CreateNotification(notification Notification) error {
-	exists, err := ExistsUserID(notification.UserID)
+func CreateNotification(ctx context.Context, notification Notification) error {
+	exists, err := ExistsUserID(ctx, notification.UserID)
 	if err != nil {
 		return err
 	}
 
 	if !exists {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 
-	err = db.Create(&notification).Error
+	err = db.WithContext(ctx).Create(&notification).Error
 	return err
 }
 
-func HasUnreadNotifications(userId int) (bool, error) {
-	exists, err := ExistsUserID(userId)
+func HasUnreadNotifications(ctx context.Context, userId int) (bool, error) {
+	exists, err := ExistsUserID(ctx, userId)
 	if err != nil {
 		return false, err
 	}
 
 	if !exists {
-		return false, errors.New("user not found")
+		return false, ErrUserNotFound
 	}
 
 	var Count int64
-	err = db.Model(&Notification{}).
+	err = db.WithContext(ctx).Model(&Notification{}).
 		Where("user_id = ?", userId).Where("read = ?", false).
 		Limit(1).Count(&Count).Error
 
@@ -56,84 +56,84 @@ func HasUnreadNotifications(userId int) (bool, error) {
 	return false, nil
 }
 
-func GetNotifications(userId int) ([]Notification, error) {
-	exists, err := ExistsUserID(userId)
+func GetNotifications(ctx context.Context, userId int) ([]Notification, error) {
+	exists, err := ExistsUserID(ctx, userId)
 	if err != nil {
 		return nil, err
 	}
 
 	if !exists {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 
 	var Notifications []Notification
-	err = db.Model(&Notification{}).Where("user_id = ?", userId).Order("created_on desc").Limit(50).Find(&Notifications).Error
+	err = db.WithContext(ctx).Model(&Notification{}).Where("user_id = ?", userId).Order("created_on desc").Limit(50).Find(&Notifications).Error
 
 	return Notifications, err
 }
 
-func GetNotification(userId, id int) (Notification, error) {
-	exists, err := ExistsUserID(userId)
+func GetNotification(ctx context.Context, userId, id int) (Notification, error) {
+	exists, err := ExistsUserID(ctx, userId)
 	if err != nil {
 		return Notification{}, err
 	}
 
 	if !exists {
-		return Notification{}, errors.New("user not found")
+		return Notification{}, ErrUserNotFound
 	}
 
 	var notification Notification
-	err = db.Model(&Notification{}).Where("user_id = ?", userId).Where("id = ?", id).Limit(1).Find(&notification).Error
+	err = db.WithContext(ctx).Model(&Notification{}).Where("user_id = ?", userId).Where("id = ?", id).Limit(1).Find(&notification).Error
 
 	return notification, err
 }
 
-func DeleteNotification(userId, id int) error {
-	exists, err := ExistsUserID(userId)
+func DeleteNotification(ctx context.Context, userId, id int) error {
+	exists, err := ExistsUserID(ctx, userId)
 	if err != nil {
 		return err
 	}
 
 	if !exists {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 
-	err = db.Where("user_id = ?", userId).Where("id = ?", id).Delete(&Notification{ID: id, UserID: userId}).Error
+	err = db.WithContext(ctx).Where("user_id = ?", userId).Where("id = ?", id).Delete(&Notification{ID: id, UserID: userId}).Error
 
 	return err
 }
 
-func MarkNotificationAsRead(userId, id int) error {
-	exists, err := ExistsUserID(userId)
+func MarkNotificationAsRead(ctx context.Context, userId, id int) error {
+	exists, err := ExistsUserID(ctx, userId)
 	if err != nil {
 		return err
 	}
 
 	if !exists {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 
-	err = db.Model(&Notification{}).Where("user_id = ?", userId).Where("id = ?", id).Update("read", true).Error
+	err = db.WithContext(ctx).Model(&Notification{}).Where("user_id = ?", userId).Where("id = ?", id).Update("read", true).Error
 	return err
 }
 
-func MarkAllNotificationsAsRead(userId int) error {
-	exists, err := ExistsUserID(userId)
+func MarkAllNotificationsAsRead(ctx context.Context, userId int) error {
+	exists, err := ExistsUserID(ctx, userId)
 	if err != nil {
 		return err
 	}
 
 	if !exists {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 
 	var Notifications []Notification
-	err = db.Model(&Notification{}).Where("user_id = ?", userId).Where("read = ?", false).Find(&Notifications).Error
+	err = db.WithContext(ctx).Model(&Notification{}).Where("user_id = ?", userId).Where("read = ?", false).Find(&Notifications).Error
 	if err != nil {
 		return err
 	}
 
-	tx := db.Begin()
+	tx := db.WithContext(ctx).Begin()
 	for _, notification := range Notifications {
 		if err := tx.Model(&Notification{}).Where("user_id = ?", notification.UserID).Where("id = ?", notification.ID).Update("read", true).Error; err != nil {
 			return err
@@ -1,9 +1,11 @@
 package models
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
 	"github.com/urento/shoppinglist/pkg/util"
 )
 
@@ -15,12 +17,12 @@ func CreateUser() (*Auth, error) {
 	password := util.StringWithCharset(500)
 	ip := util.RandomIPAddress()
 
-	err := CreateAccount(email, username, password, ip)
+	err := CreateAccount(context.Background(), email, username, password, ip)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := GetUser(email)
+	user, err := GetUser(context.Background(), email)
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +31,7 @@ func CreateUser() (*Auth, error) {
 }
 
 func TestCreateNotification(t *testing.T) {
-	Setup()
+	Setup(config.MustLoad(nil))
 
 	user, err := CreateUser()
 	if err != nil {
@@ -48,7 +50,7 @@ func TestCreateNotification(t *testing.T) {
 		Read:             false,
 	}
 
-	err = CreateNotification(notification)
+	err = CreateNotification(context.Background(), notification)
 	if err != nil {
 		t.Errorf("Error while creating notification: %s", err)
 	}
@@ -57,7 +59,9 @@ func TestCreateNotification(t *testing.T) {
 }
 
 func TestHasUnreadNotifications(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Has unread notifications", func(t *testing.T) {
 		user, err := CreateUser()
@@ -77,12 +81,12 @@ func TestHasUnreadNotifications(t *testing.T) {
 			Read:             false,
 		}
 
-		err = CreateNotification(notification)
+		err = CreateNotification(ctx, notification)
 		if err != nil {
 			t.Errorf("Error while creating notification: %s", err)
 		}
 
-		has, err := HasUnreadNotifications(user.ID)
+		has, err := HasUnreadNotifications(ctx, user.ID)
 		if err != nil {
 			t.Errorf("Error while getting unread notifications: %s", err)
 		}
@@ -96,7 +100,7 @@ func TestHasUnreadNotifications(t *testing.T) {
 			t.Errorf("Error while creating user: %s", err)
 		}
 
-		has, err := HasUnreadNotifications(user.ID)
+		has, err := HasUnreadNotifications(ctx, user.ID)
 		if err != nil {
 			t.Errorf("Error while getting unread notifications: %s", err)
 		}
@@ -106,7 +110,9 @@ func TestHasUnreadNotifications(t *testing.T) {
 }
 
 func TestGetNotifications(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Get Notifications", func(t *testing.T) {
 		user, err := CreateUser()
@@ -126,12 +132,12 @@ func TestGetNotifications(t *testing.T) {
 			Read:             false,
 		}
 
-		err = CreateNotification(notification)
+		err = CreateNotification(ctx, notification)
 		if err != nil {
 			t.Errorf("Error while creating notification: %s", err)
 		}
 
-		notifications, err := GetNotifications(user.ID)
+		notifications, err := GetNotifications(ctx, user.ID)
 		if err != nil {
 			t.Errorf("Error while getting notifications: %s", err)
 		}
@@ -149,7 +155,7 @@ func TestGetNotifications(t *testing.T) {
 			t.Errorf("Error while creating user: %s", err)
 		}
 
-		notifications, err := GetNotifications(user.ID)
+		notifications, err := GetNotifications(ctx, user.ID)
 		if err != nil {
 			t.Errorf("Error while getting notifications: %s", err)
 		}
@@ -178,13 +184,13 @@ func TestGetNotifications(t *testing.T) {
 				Read:             false,
 			}
 
-			err = CreateNotification(notification)
+			err = CreateNotification(ctx, notification)
 			if err != nil {
 				t.Errorf("Error while creating notification: %s", err)
 			}
 		}
 
-		notifications, err := GetNotifications(user.ID)
+		notifications, err := GetNotifications(ctx, user.ID)
 		if err != nil {
 			t.Errorf("Error while getting notifications: %s", err)
 		}
@@ -196,7 +202,9 @@ func TestGetNotifications(t *testing.T) {
 }
 
 func TestGetNotification(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Get Notification", func(t *testing.T) {
 		user, err := CreateUser()
@@ -216,17 +224,17 @@ func TestGetNotification(t *testing.T) {
 			Read:             false,
 		}
 
-		err = CreateNotification(notification)
+		err = CreateNotification(ctx, notification)
 		if err != nil {
 			t.Errorf("Error while creating notification: %s", err)
 		}
 
-		notifications, err := GetNotifications(user.ID)
+		notifications, err := GetNotifications(ctx, user.ID)
 		if err != nil {
 			t.Errorf("Error while getting notifications: %s", err)
 		}
 
-		n, err := GetNotification(user.ID, notifications[0].ID)
+		n, err := GetNotification(ctx, user.ID, notifications[0].ID)
 		if err != nil {
 			t.Errorf("Error while getting notification: %s", err)
 		}
@@ -244,14 +252,16 @@ func TestGetNotification(t *testing.T) {
 			t.Errorf("Error while creating user: %s", err)
 		}
 
-		_, err = GetNotification(user.ID, 456784896324532)
+		_, err = GetNotification(ctx, user.ID, 456784896324532)
 
 		Equal(t, nil, err)
 	})
 }
 
 func TestDeleteNotification(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Delete Notification", func(t *testing.T) {
 		user, err := CreateUser()
@@ -271,22 +281,22 @@ func TestDeleteNotification(t *testing.T) {
 			Read:             false,
 		}
 
-		err = CreateNotification(notification)
+		err = CreateNotification(ctx, notification)
 		if err != nil {
 			t.Errorf("Error while creating notification: %s", err)
 		}
 
-		notifications, err := GetNotifications(user.ID)
+		notifications, err := GetNotifications(ctx, user.ID)
 		if err != nil {
 			t.Errorf("Error while getting notifications: %s", err)
 		}
 
-		err = DeleteNotification(user.ID, notifications[0].ID)
+		err = DeleteNotification(ctx, user.ID, notifications[0].ID)
 		if err != nil {
 			t.Errorf("Error while deleting notification: %s", err)
 		}
 
-		notificationsAfter, err := GetNotifications(user.ID)
+		notificationsAfter, err := GetNotifications(ctx, user.ID)
 		if err != nil {
 			t.Errorf("Error while getting notifications: %s", err)
 		}
@@ -302,14 +312,16 @@ func TestDeleteNotification(t *testing.T) {
 			t.Errorf("Error while creating user: %s", err)
 		}
 
-		err = DeleteNotification(user.ID, 456784896324532)
+		err = DeleteNotification(ctx, user.ID, 456784896324532)
 
 		Equal(t, nil, err)
 	})
 }
 
 func TestMarkNotificationAsRead(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	user, err := CreateUser()
 	if err != nil {
@@ -328,22 +340,22 @@ func TestMarkNotificationAsRead(t *testing.T) {
 		Read:             false,
 	}
 
-	err = CreateNotification(notification)
+	err = CreateNotification(ctx, notification)
 	if err != nil {
 		t.Errorf("Error while creating notification: %s", err)
 	}
 
-	notifications, err := GetNotifications(user.ID)
+	notifications, err := GetNotifications(ctx, user.ID)
 	if err != nil {
 		t.Errorf("Error while getting notifications: %s", err)
 	}
 
-	err = MarkNotificationAsRead(user.ID, notifications[0].ID)
+	err = MarkNotificationAsRead(ctx, user.ID, notifications[0].ID)
 	if err != nil {
 		t.Errorf("Error while marking a notification as read: %s", err)
 	}
 
-	notificationsAfter, err := GetNotifications(user.ID)
+	notificationsAfter, err := GetNotifications(ctx, user.ID)
 	if err != nil {
 		t.Errorf("Error while getting notifications: %s", err)
 	}
@@ -352,7 +364,9 @@ func TestMarkNotificationAsRead(t *testing.T) {
 }
 
 func TestMarkAllNotificationsAsRead(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	user, err := CreateUser()
 	if err != nil {
@@ -371,7 +385,7 @@ func TestMarkAllNotificationsAsRead(t *testing.T) {
 		Read:             false,
 	}
 
-	err = CreateNotification(notification)
+	err = CreateNotification(ctx, notification)
 	if err != nil {
 		t.Errorf("Error while creating notification: %s", err)
 	}
@@ -387,17 +401,17 @@ func TestMarkAllNotificationsAsRead(t *testing.T) {
 		Read:             false,
 	}
 
-	err = CreateNotification(notification2)
+	err = CreateNotification(ctx, notification2)
 	if err != nil {
 		t.Errorf("Error while creating notification: %s", err)
 	}
 
-	err = MarkAllNotificationsAsRead(user.ID)
+	err = MarkAllNotificationsAsRead(ctx, user.ID)
 	if err != nil {
 		t.Errorf("Error while marking all notifications as read: %s", err)
 	}
 
-	notifications, err := GetNotifications(user.ID)
+	notifications, err := GetNotifications(ctx, user.ID)
 	if err != nil {
 		t.Errorf("Error while getting notifications: %s", err)
 	}
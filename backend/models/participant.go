@@ -1,60 +1,88 @@
 package models
 
 import (
-	"errors"
+	"context"
 )
 
 type Participant struct {
 	Model
 
-	ID           int    `gorm:"primaryKey" json:"id"`
-	ParentListID int    `json:"parentListId"`
-	Status       string `json:"status" gorm:"default:'pending'"`
-	Email        string `json:"email"`
-	RequestFrom  string `json:"request_from"`
+	ID            int    `gorm:"primaryKey" json:"id"`
+	ParentListID  int    `json:"parentListId"`
+	Status        string `json:"status" gorm:"default:'pending'"`
+	UserID        int    `json:"user_id" gorm:"index;not null"`
+	Email         string `json:"email" gorm:"-"`
+	RequestFromID *int   `json:"request_from_id"`
+	RequestFrom   string `json:"request_from" gorm:"-"`
 }
 
-func AddParticipant(participant Participant) (Participant, error) {
-	exists, err := ExistByID(participant.ParentListID)
+// AddParticipant invites the account with the given Email; RequestFrom is the email of the inviting user.
+// Participants reference the user id, so only emails that have an account can be invited, ErrUserNotFound is
+// returned otherwise. Before, invitations to any email were saved
+func AddParticipant(ctx context.Context, participant Participant) (Participant, error) {
+	exists, err := ExistByID(ctx, participant.ParentListID)
 	if err != nil || !exists {
-		return Participant{}, errors.New("shoppinglist does not exist")
+		return Participant{}, ErrListNotFound
 	}
 
-	err = db.Create(&participant).Error
+	participant.UserID, err = GetUserIDByEmail(ctx, participant.Email)
+	if err != nil {
+		return Participant{}, err
+	}
+
+	if participant.UserID <= 0 {
+		return Participant{}, ErrUserNotFound
+	}
+
+	if len(participant.RequestFrom) > 0 {
+		requestFromId, err := GetUserIDByEmail(ctx, participant.RequestFrom)
+		if err != nil {
+			return Participant{}, err
+		}
+
+		if requestFromId > 0 {
+			participant.RequestFromID = &requestFromId
+		}
+	}
+
+	err = db.WithContext(ctx).Create(&participant).Error
 	return participant, err
 }
 
-func RemoveParticipant(parentListID, id int) error {
-	exists, err := ExistByID(parentListID)
+func RemoveParticipant(ctx context.Context, parentListID, id int) error {
+	exists, err := ExistByID(ctx, parentListID)
 	if err != nil || !exists {
-		return errors.New("shoppinglist does not exist")
+		return ErrListNotFound
 	}
 
-	err = db.Model(&Participant{}).Where("parent_list_id = ?", parentListID).Where("id = ?", id).Delete(&Participant{}).Error
+	err = db.WithContext(ctx).Model(&Participant{}).Where("parent_list_id = ?", parentListID).Where("id = ?", id).Delete(&Participant{}).Error
 	return err
 }
 
-func GetParticipants(parentListID int) ([]Participant, error) {
-	exists, err := ExistByID(parentListID)
+func GetParticipants(ctx context.Context, parentListID int) ([]Participant, error) {
+	exists, err := ExistByID(ctx, parentListID)
 	if err != nil || !exists {
-		return nil, errors.New("shoppinglist does not exist")
+		return nil, ErrListNotFound
 	}
 
 	var Participants []Participant
-	err = db.Model(&Participant{}).Where("parent_list_id = ?", parentListID).Find(&Participants).Error
-	return Participants, err
+	err = db.WithContext(ctx).Model(&Participant{}).Where("parent_list_id = ?", parentListID).Find(&Participants).Error
+	if err != nil {
+		return nil, err
+	}
+	return Participants, fillParticipantEmails(ctx, Participants)
 }
 
-func IsParticipantAlreadyIncluded(email string, parentListID int) (bool, error) {
+func IsParticipantAlreadyIncluded(ctx context.Context, email string, parentListID int) (bool, error) {
 	var Count int64
-	err := db.Model(&Participant{}).Where("parent_list_id = ?", parentListID).Where("email = ?", email).Limit(1).Count(&Count).Error
+	err := db.WithContext(ctx).Model(&Participant{}).Where("parent_list_id = ?", parentListID).Where("user_id = "+userIdByEmail, email).Limit(1).Count(&Count).Error
 	return Count >= 1, err
 }
 
-func GetListsByParticipant(participantEmail string) ([]Shoppinglist, error) {
+func GetListsByParticipant(ctx context.Context, participantEmail string) ([]Shoppinglist, error) {
 	listsByParticipants := []Participant{}
 	lists := []Shoppinglist{}
-	err := db.Model(&Participant{}).Where("email = ?", participantEmail).Where("status = ?", "accepted").Find(&listsByParticipants).Error
+	err := db.WithContext(ctx).Model(&Participant{}).Where("user_id = "+userIdByEmail, participantEmail).Where("status = ?", "accepted").Find(&listsByParticipants).Error
 	if err != nil {
 		return []Shoppinglist{}, nil
 	}
@@ -63,7 +91,7 @@ func GetListsByParticipant(participantEmail string) ([]Shoppinglist, error) {
 		return []Shoppinglist{}, nil
 	}
 
-	tx := db.Begin()
+	tx := db.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -72,9 +100,14 @@ func GetListsByParticipant(participantEmail string) ([]Shoppinglist, error) {
 
 	for _, val := range listsByParticipants {
 		var l Shoppinglist
-		err = tx.Model(&Shoppinglist{}).Preload("Participants").Where("id = ?", val.ParentListID).Limit(1).Find(&l).Error
-		if err != nil {
-			return lists, err
+		res := tx.Model(&Shoppinglist{}).Preload("Participants").Where("id = ?", val.ParentListID).Where(ownerIsActive).Limit(1).Find(&l)
+		if res.Error != nil {
+			return lists, res.Error
+		}
+
+		//the owner deactivated their account
+		if res.RowsAffected <= 0 {
+			continue
 		}
 		lists = append(lists, l)
 	}
@@ -84,44 +117,44 @@ func GetListsByParticipant(participantEmail string) ([]Shoppinglist, error) {
 		return []Shoppinglist{}, err
 	}
 
-	return lists, nil
+	return lists, fillListEmails(ctx, lists)
 }
 
-func GetPendingRequests(email string) ([]Participant, error) {
+func GetPendingRequests(ctx context.Context, email string) ([]Participant, error) {
 	var requests []Participant
-	err := db.Model(&Participant{}).Where("status = ?", "pending").Where("email = ?", email).Find(&requests).Error
+	err := db.WithContext(ctx).Model(&Participant{}).Where("status = ?", "pending").Where("user_id = "+userIdByEmail, email).Find(&requests).Error
 	if err != nil {
 		return []Participant{}, err
 	}
-	return requests, nil
+	return requests, fillParticipantEmails(ctx, requests)
 }
 
-func GetPendingRequestsFromShoppinglist(email string, id int) ([]Participant, error) {
-	belongs, err := BelongsShoppinglistToEmail(email, id)
+func GetPendingRequestsFromShoppinglist(ctx context.Context, email string, id int) ([]Participant, error) {
+	belongs, err := BelongsShoppinglistToEmail(ctx, email, id)
 	if err != nil {
 		return []Participant{}, err
 	}
 
 	if !belongs {
-		return []Participant{}, errors.New("shoppinglist doesn not belongs to the given email")
+		return []Participant{}, ErrListNotOwned
 	}
 
 	var requests []Participant
-	err = db.Model(&Participant{}).Where("status = ?", "pending").Where("parent_list_id = ?", id).Find(&requests).Error
+	err = db.WithContext(ctx).Model(&Participant{}).Where("status = ?", "pending").Where("parent_list_id = ?", id).Find(&requests).Error
 	if err != nil {
 		return []Participant{}, err
 	}
-	return requests, nil
+	return requests, fillParticipantEmails(ctx, requests)
 }
 
-func AcceptRequest(id int, email string) error {
-	tx := db.Begin()
+func AcceptRequest(ctx context.Context, id int, email string) error {
+	tx := db.WithContext(ctx).Begin()
 
-	err := tx.Model(&Participant{}).Where("id = ?", id).Where("email = ?", email).Update("status", "accepted").Error
+	err := tx.Model(&Participant{}).Where("id = ?", id).Where("user_id = "+userIdByEmail, email).Update("status", "accepted").Error
 	if err != nil {
 		return err
 	}
-	err = tx.Model(&Participant{}).Where("id = ?", id).Where("email = ?", email).Update("request_from", nil).Error
+	err = tx.Model(&Participant{}).Where("id = ?", id).Where("user_id = "+userIdByEmail, email).Update("request_from_id", nil).Error
 	if err != nil {
 		return err
 	}
@@ -130,22 +163,22 @@ func AcceptRequest(id int, email string) error {
 	return err
 }
 
-func DeleteRequest(id int, email string) error {
-	err := db.Model(&Participant{}).Where("id = ?", id).Where("email = ?", email).Delete(&Participant{ID: id, Email: email}).Error
+func DeleteRequest(ctx context.Context, id int, email string) error {
+	err := db.WithContext(ctx).Model(&Participant{}).Where("id = ?", id).Where("user_id = "+userIdByEmail, email).Delete(&Participant{ID: id}).Error
 	return err
 }
 
-func DeleteAll(email string) error {
+func DeleteAll(ctx context.Context, email string) error {
 	var requests []Participant
-	err := db.Model(&Participant{}).Where("email = ?", email).Find(&requests).Error
+	err := db.WithContext(ctx).Model(&Participant{}).Where("user_id = "+userIdByEmail, email).Find(&requests).Error
 	if err != nil {
 		return err
 	}
 
-	tx := db.Begin()
+	tx := db.WithContext(ctx).Begin()
 
 	for _, request := range requests {
-		err = tx.Model(&Participant{}).Where("id = ?", request.ID).Where("email = ?", request.Email).Delete(&Participant{Email: email}).Error
+		err = tx.Model(&Participant{}).Where("id = ?", request.ID).Where("user_id = ?", request.UserID).Delete(&Participant{}).Error
 		if err != nil {
 			return err
 		}
@@ -155,7 +188,7 @@ func DeleteAll(email string) error {
 	return err
 }
 
-func LeaveShoppinglist(id int, email string) error {
-	err := db.Model(&Participant{}).Where("parent_list_id = ?", id).Where("email = ?", email).Where("status = ?", "accepted").Delete(&Participant{ParentListID: id, Email: email}).Error
+func LeaveShoppinglist(ctx context.Context, id int, email string) error {
+	err := db.WithContext(ctx).Model(&Participant{}).Where("parent_list_id = ?", id).Where("user_id = "+userIdByEmail, email).Where("status = ?", "accepted").Delete(&Participant{ParentListID: id}).Error
 	return err
 }
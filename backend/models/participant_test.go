@@ -1,20 +1,24 @@
 package models
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
 	"github.com/urento/shoppinglist/pkg/util"
 )
 
 func TestGetPendingRequests(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Get Pending Requests with 1 Request Pending", func(t *testing.T) {
 		id := util.RandomIntWithLength(50000)
 		title := "title3332999" + util.StringWithCharset(200)
-		owner := "owner999" + util.StringWithCharset(300)
-		participantEmail := util.StringWithCharset(500) + "@gmail.com"
+		owner, ownerId := createTestUser(t)
+		participantEmail, _ := createTestUser(t)
 		participant := Participant{
 			ParentListID: id,
 			Email:        participantEmail,
@@ -24,20 +28,19 @@ func TestGetPendingRequests(t *testing.T) {
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Error while creating shoppinglist: %s", err)
 		}
 
-		_, err = AddParticipant(participant)
+		_, err = AddParticipant(ctx, participant)
 		if err != nil {
 			t.Errorf("Error while adding participant to list: %s", err)
 		}
 
-		requests, err := GetPendingRequests(participantEmail)
+		requests, err := GetPendingRequests(ctx, participantEmail)
 		if err != nil {
 			t.Errorf("Error while getting pending requests: %s", err)
 		}
@@ -52,10 +55,10 @@ func TestGetPendingRequests(t *testing.T) {
 	t.Run("Get Pending Requests with 3 Requests", func(t *testing.T) {
 		id := util.RandomIntWithLength(50000)
 		title := "title3332999" + util.StringWithCharset(200)
-		owner := "owner999" + util.StringWithCharset(300)
-		participantEmail := util.RandomEmail()
-		participantEmail2 := util.RandomEmail()
-		participantEmail3 := util.RandomEmail()
+		owner, ownerId := createTestUser(t)
+		participantEmail, _ := createTestUser(t)
+		participantEmail2, _ := createTestUser(t)
+		participantEmail3, _ := createTestUser(t)
 		participant := Participant{
 			ParentListID: id,
 			Email:        participantEmail,
@@ -77,30 +80,29 @@ func TestGetPendingRequests(t *testing.T) {
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Error while creating shoppinglist: %s", err)
 		}
 
-		_, addParticipantErr1 := AddParticipant(participant)
+		_, addParticipantErr1 := AddParticipant(ctx, participant)
 		if addParticipantErr1 != nil {
 			t.Errorf("Error while adding participant to list: %s", err)
 		}
 
-		_, addParticipantErr2 := AddParticipant(participant2)
+		_, addParticipantErr2 := AddParticipant(ctx, participant2)
 		if addParticipantErr2 != nil {
 			t.Errorf("Error while adding participant to list: %s", err)
 		}
 
-		_, addParticipantErr3 := AddParticipant(participant3)
+		_, addParticipantErr3 := AddParticipant(ctx, participant3)
 		if addParticipantErr3 != nil {
 			t.Errorf("Error while adding participant to list: %s", err)
 		}
 
-		requests, err := GetPendingRequests(participantEmail)
+		requests, err := GetPendingRequests(ctx, participantEmail)
 		if err != nil {
 			t.Errorf("Error while getting pending requests: %s", err)
 		}
@@ -122,12 +124,14 @@ func TestGetPendingRequests(t *testing.T) {
 }
 
 func TestAcceptRequest(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	title := "title3332999" + util.StringWithCharset(200)
-	owner := "owner999" + util.StringWithCharset(300)
-	participantEmail := util.StringWithCharset(500) + "@gmail.com"
+	owner, ownerId := createTestUser(t)
+	participantEmail, _ := createTestUser(t)
 	participant := Participant{
 		ParentListID: id,
 		Email:        participantEmail,
@@ -137,30 +141,29 @@ func TestAcceptRequest(t *testing.T) {
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Error while creating shoppinglist: %s", err)
 	}
 
-	p, err := AddParticipant(participant)
+	p, err := AddParticipant(ctx, participant)
 	if err != nil {
 		t.Errorf("Error while adding participant to list: %s", err)
 	}
 
-	requests, err := GetPendingRequests(participantEmail)
+	requests, err := GetPendingRequests(ctx, participantEmail)
 	if err != nil {
 		t.Errorf("Error while getting pending requests: %s", err)
 	}
 
-	err = AcceptRequest(p.ID, participantEmail)
+	err = AcceptRequest(ctx, p.ID, participantEmail)
 	if err != nil {
 		t.Errorf("Error while accepting request: %s", err)
 	}
 
-	requestsAfter, err := GetPendingRequests(participantEmail)
+	requestsAfter, err := GetPendingRequests(ctx, participantEmail)
 	if err != nil {
 		t.Errorf("Error while getting pending requests: %s", err)
 	}
@@ -177,12 +180,14 @@ func TestAcceptRequest(t *testing.T) {
 }
 
 func TestDeleteRequest(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	title := "title3332999" + util.StringWithCharset(200)
-	owner := "owner999" + util.StringWithCharset(300)
-	participantEmail := util.StringWithCharset(500) + "@gmail.com"
+	owner, ownerId := createTestUser(t)
+	participantEmail, _ := createTestUser(t)
 	participant := Participant{
 		ParentListID: id,
 		Email:        participantEmail,
@@ -192,30 +197,29 @@ func TestDeleteRequest(t *testing.T) {
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Error while creating shoppinglist: %s", err)
 	}
 
-	p, err := AddParticipant(participant)
+	p, err := AddParticipant(ctx, participant)
 	if err != nil {
 		t.Errorf("Error while adding participant to list: %s", err)
 	}
 
-	requests, err := GetPendingRequests(participantEmail)
+	requests, err := GetPendingRequests(ctx, participantEmail)
 	if err != nil {
 		t.Errorf("Error while getting pending requests: %s", err)
 	}
 
-	err = DeleteRequest(p.ID, participantEmail)
+	err = DeleteRequest(ctx, p.ID, participantEmail)
 	if err != nil {
 		t.Errorf("Error while deleting request: %s", err)
 	}
 
-	requestsAfter, err := GetPendingRequests(participantEmail)
+	requestsAfter, err := GetPendingRequests(ctx, participantEmail)
 	if err != nil {
 		t.Errorf("Error while getting pending requests: %s", err)
 	}
@@ -232,12 +236,14 @@ func TestDeleteRequest(t *testing.T) {
 }
 
 func TestGetPendingRequestsFromShoppinglist(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	title := "title3332999" + util.StringWithCharset(200)
-	owner := "owner999" + util.StringWithCharset(300)
-	participantEmail := util.StringWithCharset(500) + "@gmail.com"
+	owner, ownerId := createTestUser(t)
+	participantEmail, _ := createTestUser(t)
 	participant := Participant{
 		ParentListID: id,
 		Email:        participantEmail,
@@ -247,20 +253,19 @@ func TestGetPendingRequestsFromShoppinglist(t *testing.T) {
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Error while creating shoppinglist: %s", err)
 	}
 
-	_, err = AddParticipant(participant)
+	_, err = AddParticipant(ctx, participant)
 	if err != nil {
 		t.Errorf("Error while adding participant to list: %s", err)
 	}
 
-	requests, err := GetPendingRequestsFromShoppinglist(owner, id)
+	requests, err := GetPendingRequestsFromShoppinglist(ctx, owner, id)
 	if err != nil {
 		t.Errorf("Error while getting pending requess from shoppinglist: %s", err)
 	}
@@ -272,13 +277,15 @@ func TestGetPendingRequestsFromShoppinglist(t *testing.T) {
 }
 
 func TestIsParticipantAlreadyIncluded(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Is Participant already included", func(t *testing.T) {
 		id := util.RandomInt()
 		title := "title3332999" + util.StringWithCharset(200)
-		owner := "owner999" + util.StringWithCharset(300)
-		participantEmail := util.RandomEmail()
+		owner, ownerId := createTestUser(t)
+		participantEmail, _ := createTestUser(t)
 		participant := Participant{
 			ParentListID: id,
 			Email:        participantEmail,
@@ -288,20 +295,19 @@ func TestIsParticipantAlreadyIncluded(t *testing.T) {
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Error while creating shoppinglist: %s", err)
 		}
 
-		_, err = AddParticipant(participant)
+		_, err = AddParticipant(ctx, participant)
 		if err != nil {
 			t.Errorf("Error while adding participant to list: %s", err)
 		}
 
-		included, err := IsParticipantAlreadyIncluded(participantEmail, id)
+		included, err := IsParticipantAlreadyIncluded(ctx, participantEmail, id)
 		if err != nil {
 			t.Errorf("Error while checking if the participant is already included: %s", err)
 		}
@@ -312,19 +318,18 @@ func TestIsParticipantAlreadyIncluded(t *testing.T) {
 	t.Run("Is Participant already included but the participant isn't included", func(t *testing.T) {
 		id := util.RandomIntWithLength(9000000)
 		title := "title3332999" + util.StringWithCharset(200)
-		owner := "owner999" + util.StringWithCharset(300)
+		_, ownerId := createTestUser(t)
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Error while creating shoppinglist: %s", err)
 		}
 
-		included, err := IsParticipantAlreadyIncluded("jndfgh", id)
+		included, err := IsParticipantAlreadyIncluded(ctx, "jndfgh", id)
 		if err != nil {
 			t.Errorf("Error while checking if the participant is already included: %s", err)
 		}
@@ -334,15 +339,17 @@ func TestIsParticipantAlreadyIncluded(t *testing.T) {
 }
 
 func TestDeleteAll(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	title := "title3332999" + util.StringWithCharset(200)
-	owner := "owner999" + util.StringWithCharset(300)
-	participantEmail := util.StringWithCharset(500) + "@gmail.com"
-	participantEmail2 := util.StringWithCharset(500) + "@gmail.com"
-	participantEmail3 := util.StringWithCharset(500) + "@gmail.com"
-	participantEmail4 := util.StringWithCharset(500) + "@gmail.com"
+	owner, ownerId := createTestUser(t)
+	participantEmail, _ := createTestUser(t)
+	participantEmail2, _ := createTestUser(t)
+	participantEmail3, _ := createTestUser(t)
+	participantEmail4, _ := createTestUser(t)
 	participant := Participant{
 		ParentListID: id,
 		Email:        participantEmail,
@@ -370,40 +377,39 @@ func TestDeleteAll(t *testing.T) {
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Error while creating shoppinglist: %s", err)
 	}
 
-	_, err = AddParticipant(participant)
+	_, err = AddParticipant(ctx, participant)
 	if err != nil {
 		t.Errorf("Error while adding participant to list: %s", err)
 	}
 
-	_, err = AddParticipant(participant2)
+	_, err = AddParticipant(ctx, participant2)
 	if err != nil {
 		t.Errorf("Error while adding participant to list: %s", err)
 	}
 
-	_, err = AddParticipant(participant3)
+	_, err = AddParticipant(ctx, participant3)
 	if err != nil {
 		t.Errorf("Error while adding participant to list: %s", err)
 	}
 
-	_, err = AddParticipant(participant4)
+	_, err = AddParticipant(ctx, participant4)
 	if err != nil {
 		t.Errorf("Error while adding participant to list: %s", err)
 	}
 
-	err = DeleteAll(owner)
+	err = DeleteAll(ctx, owner)
 	if err != nil {
 		t.Errorf("Error while deleting all requests: %s", err)
 	}
 
-	requests, err := GetPendingRequests(owner)
+	requests, err := GetPendingRequests(ctx, owner)
 	if err != nil {
 		t.Errorf("Error while getting pending requests: %s", err)
 	}
@@ -416,12 +422,14 @@ func TestDeleteAll(t *testing.T) {
 }
 
 func TestLeaveShoppinglist(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	title := "title3332999" + util.StringWithCharset(200)
-	owner := "owner999" + util.StringWithCharset(300)
-	participantEmail := util.StringWithCharset(500) + "@gmail.com"
+	owner, ownerId := createTestUser(t)
+	participantEmail, _ := createTestUser(t)
 	participant := Participant{
 		ParentListID: id,
 		Email:        participantEmail,
@@ -431,30 +439,29 @@ func TestLeaveShoppinglist(t *testing.T) {
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Error while creating shoppinglist: %s", err)
 	}
 
-	p, err := AddParticipant(participant)
+	p, err := AddParticipant(ctx, participant)
 	if err != nil {
 		t.Errorf("Error while adding participant to list: %s", err)
 	}
 
-	err = AcceptRequest(p.ID, p.Email)
+	err = AcceptRequest(ctx, p.ID, p.Email)
 	if err != nil {
 		t.Errorf("Error while accepting request: %s", err)
 	}
 
-	err = LeaveShoppinglist(p.ID, p.Email)
+	err = LeaveShoppinglist(ctx, p.ID, p.Email)
 	if err != nil {
 		t.Errorf("Error while leaving shoppinglist: %s", err)
 	}
 
-	requests, err := GetPendingRequests(owner)
+	requests, err := GetPendingRequests(ctx, owner)
 	if err != nil {
 		t.Errorf("Error while getting pending requests: %s", err)
 	}
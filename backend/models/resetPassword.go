@@ -1,69 +1,101 @@
 package models
 
 import (
-	"errors"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
 	"time"
 
-	"github.com/rs/xid"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/mailer"
+	pwd "github.com/urento/shoppinglist/pkg/password"
 	"gorm.io/gorm"
 )
 
+var ErrInvalidResetToken = e.New(http.StatusBadRequest, e.ERROR_VERIFYING_VERIFICATION_ID)
+
+// ResetPassword is a pending password reset; only the sha256 hash of the token is stored
 type ResetPassword struct {
 	Model
 
-	VerificationID  string `json:"verification_id"`
-	Email           string `json:"email"`
-	AlreadyVerified bool   `json:"already_verified"`
+	ID        int    `gorm:"primaryKey" json:"id"`
+	UserID    int    `gorm:"index;not null" json:"user_id"`
+	TokenHash string `gorm:"uniqueIndex" json:"-"`
+	ExpiresAt int64  `json:"expires_at"`
+	UsedAt    int64  `gorm:"default:0" json:"used_at"`
 }
 
-//TODO: Let them expire after 1 day (just check createdat and add 1 day)
-
-func HasResetPassword(email string) (bool, error) {
-	valid, err := IsStillValid(email)
-	if err != nil || !valid {
-		return false, err
-	}
-
+func HasResetPassword(ctx context.Context, email string) (bool, error) {
 	var Exists bool
-	err = db.Raw("SELECT EXISTS(SELECT created_on FROM reset_passwords WHERE email = ? AND already_verified = ?) AS found", email, false).Scan(&Exists).Error
+	err := db.WithContext(ctx).Raw("SELECT EXISTS(SELECT id FROM reset_passwords WHERE user_id = "+userIdByEmail+" AND used_at = 0 AND expires_at > ? AND deleted_at IS NULL) AS found", email, time.Now().Unix()).Scan(&Exists).Error
 	return Exists, err
 }
 
-func DeleteResetPassword(email string) error {
-	err := db.Transaction(func(tx *gorm.DB) error {
-		err := tx.Model(&ResetPassword{}).Where("email = ?", email).Update("already_verified", true).Error
-		if err != nil {
-			return err
-		}
+func DeleteResetPassword(ctx context.Context, email string) error {
+	err := db.WithContext(ctx).Unscoped().Where("user_id = "+userIdByEmail, email).Delete(&ResetPassword{}).Error
+	return err
+}
 
-		err = tx.Where("email = ?", email).Delete(&ResetPassword{}).Error
+// CreateResetPassword emails a new reset link to the user; previous links become invalid.
+// Unknown emails are ignored so the endpoint doesn't reveal which accounts exist
+func CreateResetPassword(ctx context.Context, email string) error {
+	exists, err := Exists(ctx, email)
+	if err != nil || !exists {
 		return err
-	})
-	return err
+	}
+
+	token, err := createResetToken(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	return sendEmail(email, token)
 }
 
-func GetVerificationID(email string) (string, error) {
-	valid, err := IsStillValid(email)
-	if err != nil || !valid {
+func createResetToken(ctx context.Context, email string) (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
 		return "", err
 	}
+	token := base64.RawURLEncoding.EncodeToString(b)
 
-	var verificationID string
-	err = db.Model(&ResetPassword{}).Where("email = ?", email).Select("verification_id").First(&verificationID).Error
+	userId, err := GetUserIDByEmail(ctx, email)
 	if err != nil {
 		return "", err
 	}
-	return verificationID, nil
-}
 
-func VerifyVerificationID(email, verificationId string) (bool, error) {
-	valid, err := IsStillValid(email)
-	if err != nil || !valid {
-		return false, err
+	err = DeleteResetPassword(ctx, email)
+	if err != nil {
+		return "", err
+	}
+
+	resetPwdObj := ResetPassword{
+		UserID:    userId,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(resetPasswordTTL).Unix(),
 	}
 
+	err = db.WithContext(ctx).Create(&resetPwdObj).Error
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// VerifyResetToken checks the token without using it up
+func VerifyResetToken(ctx context.Context, email, token string) (bool, error) {
 	var Correct int64
-	err = db.Model(&ResetPassword{}).Where("email = ?", email).Where("verification_id = ?", verificationId).Count(&Correct).Error
+	err := db.WithContext(ctx).Model(&ResetPassword{}).
+		Where("user_id = "+userIdByEmail, email).Where("token_hash = ?", hashToken(token)).
+		Where("used_at = 0").Where("expires_at > ?", time.Now().Unix()).
+		Count(&Correct).Error
 	if err != nil {
 		return false, err
 	}
@@ -71,64 +103,51 @@ func VerifyVerificationID(email, verificationId string) (bool, error) {
 	return Correct >= 1, nil
 }
 
-func CreateResetPassword(email string) error {
-	var verificationID string
-	/**
-	* Has a pending request already
-	 */
-	has, err := HasResetPassword(email)
+// ResetPasswordWithToken uses up the token, sets the new password and logs the user out everywhere
+func ResetPasswordWithToken(ctx context.Context, email, token, password string) error {
+	err := pwd.Validate(email, password)
 	if err != nil {
 		return err
 	}
 
-	if has {
-		verificationID, err = GetVerificationID(email)
-		if err != nil {
-			return err
-		}
-	} else {
-		/**
-		* Create new password reset request
-		 */
-		guid := xid.New()
-		verificationID := guid.String()
-
-		resetPwdObj := ResetPassword{
-			VerificationID:  verificationID,
-			Email:           email,
-			AlreadyVerified: false,
-		}
-
-		err = db.Create(&resetPwdObj).Error
-		if err != nil {
-			return err
-		}
+	now := time.Now().Unix()
+
+	//the token can only be used once, even if two requests arrive at the same time
+	result := db.WithContext(ctx).Model(&ResetPassword{}).
+		Where("user_id = "+userIdByEmail, email).Where("token_hash = ?", hashToken(token)).
+		Where("used_at = 0").Where("expires_at > ?", now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected != 1 {
+		return ErrInvalidResetToken
 	}
 
-	err = sendEmail(email, verificationID)
+	err = ResetPasswordFromUser(ctx, email, password, "", false)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return DeleteResetPassword(ctx, email)
 }
 
-func IsStillValid(email string) (bool, error) {
-	var createdOn int64
-	err := db.Model(&ResetPassword{}).Where("email = ?", email).Select("created_on").Find(&createdOn).Error
-	if err != nil {
-		return false, err
-	}
-	currentDate := time.Now().AddDate(0, 0, 1)
-
-	if currentDate.Unix() >= createdOn {
-		return true, nil
+// migrateLegacyResetPasswords drops the old table with plaintext verification ids; pending requests have to be made again
+func migrateLegacyResetPasswords(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn(&ResetPassword{}, "verification_id") {
+		return nil
 	}
+	return tx.Migrator().DropTable("reset_passwords")
+}
 
-	return false, errors.New("resetpassword request already expired")
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
-func sendEmail(email, verificationID string) error {
-	//TODO: SEND EMAIL
-	return nil
+func sendEmail(email, token string) error {
+	link := fmt.Sprintf("%s/resetpassword?email=%s&token=%s", appURL, url.QueryEscape(email), url.QueryEscape(token))
+	body := fmt.Sprintf("Use this link to reset your password:\n\n%s\n\nThe link expires in %d minutes. If you didn't request it, you can ignore this email.", link, int(resetPasswordTTL.Minutes()))
+	return mailer.Send(email, "Reset your password", body)
 }
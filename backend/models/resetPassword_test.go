@@ -1,24 +1,33 @@
 package models
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
 	"github.com/urento/shoppinglist/pkg/util"
 )
 
 func TestCreateResetPassword(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	email := util.StringWithCharset(10) + "@gmail.com"
+	err := CreateAccount(ctx, email, util.StringWithCharset(20), util.StringWithCharset(20), "")
+	if err != nil {
+		t.Errorf("Error while creating account: %s", err)
+	}
 
 	t.Run("TestCreateResetPassword", func(t *testing.T) {
-		err := CreateResetPassword(email)
+		err := CreateResetPassword(ctx, email)
 		if err != nil {
 			t.Errorf("Error while creating reset password: %s", err)
 		}
 
-		exists, err := HasResetPassword(email)
+		exists, err := HasResetPassword(ctx, email)
 		if err != nil {
 			t.Errorf("Error while checking if reset password request exists: %s", err)
 		}
@@ -28,45 +37,56 @@ func TestCreateResetPassword(t *testing.T) {
 	})
 
 	t.Run("TestCreateResetPasswordWhenRequestAlreadyExists", func(t *testing.T) {
-		existsBefore, err := HasResetPassword(email)
+		tokenBefore, err := createResetToken(ctx, email)
 		if err != nil {
-			t.Errorf("Error while checking if reset password request exists: %s", err)
+			t.Errorf("Error while creating reset token: %s", err)
 		}
 
-		verificationIDBefore, err := GetVerificationID(email)
+		tokenAfter, err := createResetToken(ctx, email)
 		if err != nil {
-			t.Errorf("Error while getting verificationID: %s", err)
+			t.Errorf("Error while creating reset token: %s", err)
 		}
 
-		err = CreateResetPassword(email)
+		validBefore, err := VerifyResetToken(ctx, email, tokenBefore)
 		if err != nil {
-			t.Errorf("Error while creating reset password: %s", err)
+			t.Errorf("Error while verifying reset token: %s", err)
 		}
 
-		existsAfter, err := HasResetPassword(email)
+		validAfter, err := VerifyResetToken(ctx, email, tokenAfter)
 		if err != nil {
-			t.Errorf("Error while checking if reset password request exists: %s", err)
+			t.Errorf("Error while verifying reset token: %s", err)
 		}
 
-		verificationIDAfter, err := GetVerificationID(email)
+		NotEqual(t, tokenBefore, tokenAfter)
+		Equal(t, false, validBefore)
+		Equal(t, true, validAfter)
+		Equal(t, 43, len(tokenAfter))
+	})
+
+	t.Run("TestCreateResetPasswordForUnknownEmail", func(t *testing.T) {
+		unknown := util.StringWithCharset(10) + "@gmail.com"
+
+		err := CreateResetPassword(ctx, unknown)
 		if err != nil {
-			t.Errorf("Error while getting verificaitonID: %s", err)
+			t.Errorf("Error while creating reset password: %s", err)
 		}
 
-		Equal(t, true, existsBefore)
-		Equal(t, true, existsAfter)
-		Equal(t, nil, err)
-		Equal(t, verificationIDBefore, verificationIDAfter)
+		exists, err := HasResetPassword(ctx, unknown)
+		if err != nil {
+			t.Errorf("Error while checking if reset password request exists: %s", err)
+		}
+
+		Equal(t, false, exists)
 	})
 }
 
 func TestExistResetPassword(t *testing.T) {
-	Setup()
+	Setup(config.MustLoad(nil))
 
 	t.Run("Exist Reset Password Where Request doesn't exist", func(t *testing.T) {
 		email := util.StringWithCharset(10) + "@gmail.com"
 
-		exists, err := HasResetPassword(email)
+		exists, err := HasResetPassword(context.Background(), email)
 		if err != nil {
 			t.Errorf("Error while checking if reset password object exists: %s", err)
 		}
@@ -77,27 +97,29 @@ func TestExistResetPassword(t *testing.T) {
 }
 
 func TestDeleteResetPassword(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Delete Reset Password", func(t *testing.T) {
-		email := util.StringWithCharset(10) + "@gmail.com"
+		email, _ := createTestUser(t)
 
-		err := CreateResetPassword(email)
+		_, err := createResetToken(ctx, email)
 		if err != nil {
-			t.Errorf("Error while creating reset password: %s", err)
+			t.Errorf("Error while creating reset token: %s", err)
 		}
 
-		existsBefore, err := HasResetPassword(email)
+		existsBefore, err := HasResetPassword(ctx, email)
 		if err != nil {
 			t.Errorf("Error while checking if reset password request exists 1: %s", err)
 		}
 
-		err = DeleteResetPassword(email)
+		err = DeleteResetPassword(ctx, email)
 		if err != nil {
 			t.Errorf("Error while deleting reset password object: %s", err)
 		}
 
-		existsAfter, err := HasResetPassword(email)
+		existsAfter, err := HasResetPassword(ctx, email)
 		if err != nil {
 			t.Errorf("Error while checking if reset password request exists 1: %s", err)
 		}
@@ -110,17 +132,17 @@ func TestDeleteResetPassword(t *testing.T) {
 	t.Run("Delete Reset Password Where Request Doesn't exist", func(t *testing.T) {
 		email := util.StringWithCharset(10) + "@gmail.com"
 
-		existsBefore, err := HasResetPassword(email)
+		existsBefore, err := HasResetPassword(ctx, email)
 		if err != nil {
 			t.Errorf("Error while checking if reset password request exists 1: %s", err)
 		}
 
-		err = DeleteResetPassword(email)
+		err = DeleteResetPassword(ctx, email)
 		if err != nil {
 			t.Errorf("Error while deleting reset password object: %s", err)
 		}
 
-		existsAfter, err := HasResetPassword(email)
+		existsAfter, err := HasResetPassword(ctx, email)
 		if err != nil {
 			t.Errorf("Error while checking if reset password request exists 1: %s", err)
 		}
@@ -131,61 +153,102 @@ func TestDeleteResetPassword(t *testing.T) {
 	})
 }
 
-func TestVerifyVerificationId(t *testing.T) {
-	Setup()
+func TestVerifyResetToken(t *testing.T) {
+	ctx := context.Background()
 
-	t.Run("Verify Verification Id", func(t *testing.T) {
-		email := util.StringWithCharset(10) + "@gmail.com"
+	Setup(config.MustLoad(nil))
 
-		err := CreateResetPassword(email)
-		if err != nil {
-			t.Errorf("Error while creating reset password: %s", err)
-		}
+	t.Run("Verify Reset Token", func(t *testing.T) {
+		email, _ := createTestUser(t)
 
-		verificationId, err := GetVerificationID(email)
+		token, err := createResetToken(ctx, email)
 		if err != nil {
-			t.Errorf("Error while getting verification id: %s", err)
+			t.Errorf("Error while creating reset token: %s", err)
 		}
 
-		ok, err := VerifyVerificationID(email, verificationId)
+		ok, err := VerifyResetToken(ctx, email, token)
 		if err != nil {
-			t.Errorf("Error while verifying verification id: %s", err)
+			t.Errorf("Error while verifying reset token: %s", err)
 		}
 
 		Equal(t, true, ok)
 		Equal(t, nil, err)
 	})
 
-	t.Run("Test Verify Verification Id With Wrong Id", func(t *testing.T) {
-		email := util.StringWithCharset(10) + "@gmail.com"
+	t.Run("Verify Reset Token With Wrong Token", func(t *testing.T) {
+		email, _ := createTestUser(t)
 
-		err := CreateResetPassword(email)
+		_, err := createResetToken(ctx, email)
 		if err != nil {
-			t.Errorf("Error while creating reset password: %s", err)
+			t.Errorf("Error while creating reset token: %s", err)
 		}
 
-		ok, err := VerifyVerificationID(email, "verificationId")
+		ok, err := VerifyResetToken(ctx, email, "verificationId")
 
 		Equal(t, false, ok)
 		Equal(t, nil, err)
 	})
+
+	t.Run("Verify Expired Reset Token", func(t *testing.T) {
+		email, _ := createTestUser(t)
+
+		token, err := createResetToken(ctx, email)
+		if err != nil {
+			t.Errorf("Error while creating reset token: %s", err)
+		}
+
+		err = db.Model(&ResetPassword{}).Where("user_id = "+userIdByEmail, email).Update("expires_at", time.Now().Add(-time.Minute).Unix()).Error
+		if err != nil {
+			t.Errorf("Error while expiring reset token: %s", err)
+		}
+
+		ok, err := VerifyResetToken(ctx, email, token)
+		if err != nil {
+			t.Errorf("Error while verifying reset token: %s", err)
+		}
+
+		exists, err := HasResetPassword(ctx, email)
+		if err != nil {
+			t.Errorf("Error while checking if reset password request exists: %s", err)
+		}
+
+		Equal(t, false, ok)
+		Equal(t, false, exists)
+	})
 }
 
-func TestIsStillValid(t *testing.T) {
-	Setup()
+func TestResetPasswordWithToken(t *testing.T) {
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	email := util.StringWithCharset(10) + "@gmail.com"
+	newPassword := util.StringWithCharset(20)
+	err := CreateAccount(ctx, email, util.StringWithCharset(20), util.StringWithCharset(20), "")
+	if err != nil {
+		t.Errorf("Error while creating account: %s", err)
+	}
 
-	err := CreateResetPassword(email)
+	token, err := createResetToken(ctx, email)
 	if err != nil {
-		t.Errorf("Error while creating reset password: %s", err)
+		t.Errorf("Error while creating reset token: %s", err)
 	}
 
-	valid, err := IsStillValid(email)
+	err = ResetPasswordWithToken(ctx, email, token, newPassword)
 	if err != nil {
-		t.Errorf("Error while checking if reset password request is still valid: %s", err)
+		t.Errorf("Error while resetting password: %s", err)
 	}
 
-	Equal(t, true, valid)
-	Equal(t, nil, err)
+	ok, err := CheckPassword(ctx, email, newPassword)
+	if err != nil {
+		t.Errorf("Error while checking password: %s", err)
+	}
+
+	True(t, ok)
+
+	t.Run("Use Token Twice", func(t *testing.T) {
+		err := ResetPasswordWithToken(ctx, email, token, util.StringWithCharset(20))
+
+		Equal(t, ErrInvalidResetToken, err)
+	})
 }
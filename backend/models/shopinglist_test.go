@@ -1,40 +1,61 @@
 package models
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/stretchr/testify/assert"
 	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
 	"github.com/urento/shoppinglist/pkg/util"
 )
 
 func SetupTest() {
-	Setup()
-	util.Setup()
-	cache.Setup(false)
+	cfg := config.MustLoad(nil)
+	Setup(cfg)
+	util.Setup(cfg)
+	cache.Setup(cfg)
+}
+
+// createTestUser creates an account since lists and participants reference the user id
+func createTestUser(t *testing.T) (string, int) {
+	email := util.RandomEmail()
+	err := CreateAccount(context.Background(), email, util.StringWithCharset(20), util.StringWithCharset(20), "")
+	if err != nil {
+		t.Fatalf("Error while creating account: %s", err)
+	}
+
+	userId, err := GetUserIDByEmail(context.Background(), email)
+	if err != nil {
+		t.Fatalf("Error while getting user id: %s", err)
+	}
+
+	return email, userId
 }
 
 func TestGetTotalListsByOwner(t *testing.T) {
-	Setup()
-	util.Setup()
-	cache.Setup(true)
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	Setup(cfg)
+	util.Setup(cfg)
+	cache.Setup(cfg)
 
 	t.Run("TestGetTotalListsByOwner", func(t *testing.T) {
 		id := util.RandomInt()
 		title := "title" + util.StringWithCharset(200)
-		owner := "ParentListID" + util.StringWithCharset(300)
+		owner, ownerId := createTestUser(t)
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Error while creating Shoppinglist %s", err.Error())
 		}
 
-		count, err := GetTotalListsByOwner(owner)
+		count, err := GetTotalListsByOwner(ctx, owner)
 		if err != nil {
 			t.Errorf("Error while getting the total lists by ParentListID %s", err.Error())
 		}
@@ -45,14 +66,13 @@ func TestGetTotalListsByOwner(t *testing.T) {
 	t.Run("TestGetTotalListsByOwnerWithMultipleLists", func(t *testing.T) {
 		id := util.RandomInt()
 		title := "title" + util.StringWithCharset(200)
-		owner := "ParentListID" + util.StringWithCharset(300)
+		owner, ownerId := createTestUser(t)
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Error while creating Shoppinglist 1 %s", err.Error())
 		}
@@ -62,10 +82,9 @@ func TestGetTotalListsByOwner(t *testing.T) {
 		shoppinglist2 := Shoppinglist{
 			ID:    id2,
 			Title: title2,
-			Owner: owner,
 		}
 
-		err = CreateList(shoppinglist2, 0, false)
+		err = CreateList(ctx, shoppinglist2, ownerId, false)
 		if err != nil {
 			t.Errorf("Error while creating Shoppinglist 2 %s", err.Error())
 		}
@@ -75,15 +94,14 @@ func TestGetTotalListsByOwner(t *testing.T) {
 		shoppinglist3 := Shoppinglist{
 			ID:    id3,
 			Title: title3,
-			Owner: owner,
 		}
 
-		err = CreateList(shoppinglist3, 0, false)
+		err = CreateList(ctx, shoppinglist3, ownerId, false)
 		if err != nil {
 			t.Errorf("Error while creating Shoppinglist 3 %s", err.Error())
 		}
 
-		count, err := GetTotalListsByOwner(owner)
+		count, err := GetTotalListsByOwner(ctx, owner)
 		if err != nil {
 			t.Errorf("Error while getting the total lists by ParentListID %s", err.Error())
 		}
@@ -93,20 +111,22 @@ func TestGetTotalListsByOwner(t *testing.T) {
 }
 
 func TestGetListsByOwner(t *testing.T) {
-	Setup()
-	util.Setup()
-	cache.Setup(true)
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	Setup(cfg)
+	util.Setup(cfg)
+	cache.Setup(cfg)
 
 	id := util.RandomInt()
 	title := "title" + util.StringWithCharset(200)
-	owner := "Owner123123123123" + util.StringWithCharset(300)
+	owner, ownerId := createTestUser(t)
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	if err := CreateList(shoppinglist, 0, false); err != nil {
+	if err := CreateList(ctx, shoppinglist, ownerId, false); err != nil {
 		t.Errorf("Error while creating Shoppinglist 1 %s", err.Error())
 	}
 
@@ -115,14 +135,13 @@ func TestGetListsByOwner(t *testing.T) {
 	shoppinglist2 := Shoppinglist{
 		ID:    id2,
 		Title: title2,
-		Owner: owner,
 	}
 
-	if err := CreateList(shoppinglist2, 0, false); err != nil {
+	if err := CreateList(ctx, shoppinglist2, ownerId, false); err != nil {
 		t.Errorf("Error while creating Shoppinglist 2 %s", err.Error())
 	}
 
-	lists, err := GetLists(owner, 0)
+	lists, err := GetLists(ctx, owner, 0)
 	if err != nil {
 		t.Errorf("Error while getting the Shoppinglists %s", err.Error())
 	}
@@ -140,24 +159,26 @@ func TestGetListsByOwner(t *testing.T) {
 }
 
 func TestGetListsWithOffset(t *testing.T) {
-	Setup()
-	util.Setup()
-	cache.Setup(true)
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	Setup(cfg)
+	util.Setup(cfg)
+	cache.Setup(cfg)
 
 	id := util.RandomInt()
 	title := "title" + util.StringWithCharset(200)
-	owner := "Owner123123123123" + util.StringWithCharset(300)
+	owner, ownerId := createTestUser(t)
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	if err := CreateList(shoppinglist, 0, false); err != nil {
+	if err := CreateList(ctx, shoppinglist, ownerId, false); err != nil {
 		t.Errorf("Error while creating Shoppinglist 1 %s", err.Error())
 	}
 
-	lists, err := GetLists(owner, 1)
+	lists, err := GetLists(ctx, owner, 1)
 	if err != nil {
 		t.Errorf("Error while getting the Shoppinglists %s", err.Error())
 	}
@@ -170,25 +191,27 @@ func TestGetListsWithOffset(t *testing.T) {
 }
 
 func TestBelongsShoppinglistToEmail(t *testing.T) {
-	Setup()
-	util.Setup()
-	cache.Setup(true)
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	Setup(cfg)
+	util.Setup(cfg)
+	cache.Setup(cfg)
 
 	t.Run("Belongs Shoppinglist to email", func(t *testing.T) {
 		id := util.RandomInt()
 		title := "title" + util.StringWithCharset(200)
-		owner := "Owner123123123123" + util.StringWithCharset(300)
+		owner, ownerId := createTestUser(t)
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		if err := CreateList(shoppinglist, 0, false); err != nil {
+		if err := CreateList(ctx, shoppinglist, ownerId, false); err != nil {
 			t.Errorf("Error while creating Shoppinglist 1 %s", err.Error())
 		}
 
-		belongs, err := BelongsShoppinglistToEmail(owner, id)
+		belongs, err := BelongsShoppinglistToEmail(ctx, owner, id)
 		if err != nil {
 			t.Errorf("Error while checking if the shoppinglist belongs to the email: %s", err)
 		}
@@ -198,43 +221,45 @@ func TestBelongsShoppinglistToEmail(t *testing.T) {
 	})
 
 	t.Run("Belongs Shoppinglist email when the shoppinglist doesn't belong to the owner", func(t *testing.T) {
-		belongs, _ := BelongsShoppinglistToEmail("jdfghnkjdlfg", 0)
+		belongs, _ := BelongsShoppinglistToEmail(ctx, "jdfghnkjdlfg", 0)
 
 		False(t, belongs)
 	})
 }
 
 func TestCreate(t *testing.T) {
-	Setup()
-	util.Setup()
-	cache.Setup(true)
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	Setup(cfg)
+	util.Setup(cfg)
+	cache.Setup(cfg)
 
 	t.Run("Create and Check", func(t *testing.T) {
 		id := util.RandomIntWithLength(5000)
 		title := "title" + util.StringWithCharset(200)
-		owner := "owner" + util.StringWithCharset(300)
+		owner, ownerId := createTestUser(t)
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Failed to create shoppinglist %s", err.Error())
 		}
 
-		exists, err := ExistByID(id)
+		exists, err := ExistByID(ctx, id)
 		if err != nil || !exists {
 			t.Errorf("Shoppinglist did not get created %s", err.Error())
 		}
 
-		l, err := GetList(id, owner)
+		l, err := GetList(ctx, id, owner)
 		if err != nil {
 			t.Errorf("Error while getting the shoppinglist: %s", err.Error())
 		}
 
-		err = DeleteList(id)
+		err = DeleteList(ctx, id)
 		if err != nil {
 			t.Errorf("Shoppinglist couldn't be deleted %s", err.Error())
 		}
@@ -248,14 +273,13 @@ func TestCreate(t *testing.T) {
 	t.Run("Create and Edit", func(t *testing.T) {
 		id := util.RandomIntWithLength(5000)
 		title := "title33232999" + util.StringWithCharset(200)
-		owner := "999owner999" + util.StringWithCharset(300)
+		owner, ownerId := createTestUser(t)
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Failed to create shoppinglist %s", err.Error())
 		}
@@ -264,25 +288,24 @@ func TestCreate(t *testing.T) {
 		shoppinglist = Shoppinglist{
 			ID:    id,
 			Title: title2,
-			Owner: owner,
 		}
 
-		err = EditList(id, shoppinglist)
+		err = EditList(ctx, id, shoppinglist)
 		if err != nil {
 			t.Errorf("Failed to edit shoppinglist %s", err.Error())
 		}
 
-		exists, err := ExistByID(id)
+		exists, err := ExistByID(ctx, id)
 		if err != nil || !exists {
 			t.Errorf("Shoppinglist did not get created %s", err.Error())
 		}
 
-		l, err := GetList(id, owner)
+		l, err := GetList(ctx, id, owner)
 		if err != nil {
 			t.Errorf("Shoppinglist not found %s", err.Error())
 		}
 
-		err = DeleteList(id)
+		err = DeleteList(ctx, id)
 		if err != nil {
 			t.Errorf("Shoppinglist couldn't be deleted %s", err.Error())
 		}
@@ -296,28 +319,29 @@ func TestCreate(t *testing.T) {
 }
 
 func TestExistsByID(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(500000)
 	title := "titlesdfgdsghdshgfdzhjf" + util.StringWithCharset(20000)
-	owner := "ownersthfdghdfhfdthfxgdh" + util.StringWithCharset(30000)
+	_, ownerId := createTestUser(t)
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Failed to create shoppinglist %s", err.Error())
 	}
 
-	exists, err := ExistByID(id)
+	exists, err := ExistByID(ctx, id)
 	if err != nil || !exists {
 		t.Errorf("Shoppinglist did not get created %s", err.Error())
 	}
 
-	err = DeleteList(id)
+	err = DeleteList(ctx, id)
 	if err != nil {
 		t.Errorf("Shoppinglist couldn't be deleted %s", err.Error())
 	}
@@ -327,12 +351,14 @@ func TestExistsByID(t *testing.T) {
 }
 
 func TestAddItem(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	itemID := util.RandomIntWithLength(50000)
 	title := "title3332999" + util.StringWithCharset(20000)
-	owner := "owner999" + util.StringWithCharset(30000)
+	_, ownerId := createTestUser(t)
 	items := []*Item{
 		{
 			ParentListID: id,
@@ -345,15 +371,14 @@ func TestAddItem(t *testing.T) {
 		ID:    id,
 		Title: title,
 		Items: items,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Failed to create shoppinglist %s", err.Error())
 	}
 
-	item, err := AddItem(*items[0])
+	item, err := AddItem(ctx, *items[0])
 	if err != nil {
 		t.Errorf("Failed to edit shoppinglist %s", err.Error())
 	}
@@ -363,23 +388,24 @@ func TestAddItem(t *testing.T) {
 }
 
 func TestGetList(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	title := "titlesdfgdsghdshgfdzhjf" + util.StringWithCharset(20000)
-	owner := "ownersthfdghdfhfdthfxgdh" + util.StringWithCharset(3000)
+	owner, ownerId := createTestUser(t)
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Failed to create shoppinglist %s", err.Error())
 	}
 
-	list, err := GetList(id, owner)
+	list, err := GetList(ctx, id, owner)
 	if err != nil {
 		t.Errorf("Error while getting list: %s", err)
 	}
@@ -389,12 +415,14 @@ func TestGetList(t *testing.T) {
 }
 
 func TestGetItems(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	itemID := util.RandomIntWithLength(50000)
 	title := "title3332999" + util.StringWithCharset(20000)
-	owner := "owner999" + util.StringWithCharset(30000)
+	_, ownerId := createTestUser(t)
 	items := []*Item{
 		{
 			ParentListID: id,
@@ -407,20 +435,19 @@ func TestGetItems(t *testing.T) {
 		ID:    id,
 		Title: title,
 		Items: items,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Failed to create shoppinglist %s", err.Error())
 	}
 
-	_, err = AddItem(*items[0])
+	_, err = AddItem(ctx, *items[0])
 	if err != nil {
 		t.Errorf("Error while adding item: %s", err)
 	}
 
-	itemsInList, _ := GetItems(id)
+	itemsInList, _ := GetItems(ctx, id)
 
 	Equal(t, items[0].Title, itemsInList[0].Title)
 	Equal(t, items[0].Bought, itemsInList[0].Bought)
@@ -431,13 +458,15 @@ func TestGetItems(t *testing.T) {
 }
 
 func TestGetLastPosition(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Get last position with two items", func(t *testing.T) {
 		id := util.RandomIntWithLength(7000)
 		itemID := util.RandomIntWithLength(50000)
 		title := "title3332999" + util.StringWithCharset(20000)
-		owner := "owner999" + util.StringWithCharset(300)
+		_, ownerId := createTestUser(t)
 		position := util.RandomPosition()
 		items := []*Item{
 			{
@@ -451,15 +480,14 @@ func TestGetLastPosition(t *testing.T) {
 			ID:    id,
 			Title: title,
 			Items: items,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Failed to create shoppinglist %s", err.Error())
 		}
 
-		_, err = AddItem(*items[0])
+		_, err = AddItem(ctx, *items[0])
 		if err != nil {
 			t.Errorf("Error while adding item: %s", err)
 		}
@@ -474,12 +502,12 @@ func TestGetLastPosition(t *testing.T) {
 			},
 		}
 
-		_, err = AddItem(*items2[0])
+		_, err = AddItem(ctx, *items2[0])
 		if err != nil {
 			t.Errorf("Error while adding item: %s", err)
 		}
 
-		lastPosition, err := GetLastPosition(id)
+		lastPosition, err := GetLastPosition(ctx, id)
 		if err != nil {
 			t.Errorf("Error while getting last position: %s", err)
 		}
@@ -492,32 +520,33 @@ func TestGetLastPosition(t *testing.T) {
 	t.Run("Get Last Position without any items", func(t *testing.T) {
 		id := util.RandomIntWithLength(5000)
 		title := "title3332999" + util.StringWithCharset(200)
-		owner := "owner999" + util.StringWithCharset(300)
+		_, ownerId := createTestUser(t)
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Failed to create shoppinglist %s", err.Error())
 		}
 
-		lastPosition, _ := GetLastPosition(id)
+		lastPosition, _ := GetLastPosition(ctx, id)
 
 		Equal(t, int64(0), lastPosition)
 	})
 }
 
 func TestGetItem(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Get Items", func(t *testing.T) {
 		id := util.RandomIntWithLength(50000)
 		itemID := util.RandomIntWithLength(200)
 		title := "title3332999" + util.StringWithCharset(200)
-		owner := "owner999" + util.StringWithCharset(300)
+		_, ownerId := createTestUser(t)
 		position := util.RandomPosition()
 		itemTitle := util.StringWithCharset(100)
 		items := []*Item{
@@ -532,20 +561,19 @@ func TestGetItem(t *testing.T) {
 			ID:    id,
 			Title: title,
 			Items: items,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Failed to create shoppinglist %s", err.Error())
 		}
 
-		_, err = AddItem(*items[0])
+		_, err = AddItem(ctx, *items[0])
 		if err != nil {
 			t.Errorf("Error while adding item: %s", err)
 		}
 
-		i, err := GetItem(id, itemID)
+		i, err := GetItem(ctx, id, itemID)
 		if err != nil {
 			t.Errorf("Error while getting item: %s", err)
 		}
@@ -560,7 +588,7 @@ func TestGetItem(t *testing.T) {
 		id := util.RandomIntWithLength(500)
 		itemID := util.RandomIntWithLength(200)
 
-		i, err := GetItem(id, itemID)
+		i, err := GetItem(ctx, id, itemID)
 		t.Log(i)
 
 		NotEqual(t, nil, err)
@@ -568,12 +596,14 @@ func TestGetItem(t *testing.T) {
 }
 
 func TestUpdateItem(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	itemID := util.RandomIntWithLength(50000)
 	title := "title3332999" + util.StringWithCharset(00020)
-	owner := "owner999" + util.StringWithCharset(30000)
+	_, ownerId := createTestUser(t)
 	position := util.RandomPosition()
 	itemTitle := util.StringWithCharset(10000)
 	items := []*Item{
@@ -588,20 +618,19 @@ func TestUpdateItem(t *testing.T) {
 		ID:    id,
 		Title: title,
 		Items: items,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Failed to create shoppinglist %s", err.Error())
 	}
 
-	_, err = AddItem(*items[0])
+	_, err = AddItem(ctx, *items[0])
 	if err != nil {
 		t.Errorf("Error while adding item: %s", err)
 	}
 
-	i, err := GetItem(id, itemID)
+	i, err := GetItem(ctx, id, itemID)
 	if err != nil {
 		t.Errorf("Error while getting item: %s", err)
 	}
@@ -617,12 +646,12 @@ func TestUpdateItem(t *testing.T) {
 		Bought:       newBought,
 	}
 
-	err = UpdateItem(updatedItem)
+	err = UpdateItem(ctx, updatedItem)
 	if err != nil {
 		t.Errorf("Error while updating item: %s", err)
 	}
 
-	i2, err := GetItem(id, itemID)
+	i2, err := GetItem(ctx, id, itemID)
 	if err != nil {
 		t.Errorf("Error while getting item: %s", err)
 	}
@@ -639,13 +668,15 @@ func TestUpdateItem(t *testing.T) {
 }
 
 func TestAddParticipant(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Add Participant", func(t *testing.T) {
 		id := util.RandomInt()
 		title := "title3332999" + util.StringWithCharset(20000)
-		owner := "owner999" + util.StringWithCharset(30000)
-		participantEmail := util.RandomEmail()
+		_, ownerId := createTestUser(t)
+		participantEmail, _ := createTestUser(t)
 		participantStatus := util.StringWithCharset(20000)
 		participant := Participant{
 			ParentListID: id,
@@ -655,15 +686,14 @@ func TestAddParticipant(t *testing.T) {
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Failed to create shoppinglist %s", err.Error())
 		}
 
-		_, err = AddParticipant(participant)
+		_, err = AddParticipant(ctx, participant)
 		if err != nil {
 			t.Errorf("Error while adding participant: %s", err)
 		}
@@ -673,7 +703,7 @@ func TestAddParticipant(t *testing.T) {
 
 	t.Run("Add Participant when Shoppinglist doesn't exist", func(t *testing.T) {
 		id := util.RandomIntWithLength(50000)
-		participantEmail := util.RandomEmail()
+		participantEmail, _ := createTestUser(t)
 		participantStatus := util.StringWithCharset(20000)
 		participant := Participant{
 			ParentListID: id,
@@ -681,19 +711,21 @@ func TestAddParticipant(t *testing.T) {
 			Status:       participantStatus,
 		}
 
-		_, err := AddParticipant(participant)
+		_, err := AddParticipant(ctx, participant)
 
 		NotNil(t, err)
 	})
 }
 
 func TestGetParticipants(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	title := "title3332999" + util.StringWithCharset(20000)
-	owner := "owner999" + util.StringWithCharset(30000)
-	participantEmail := util.RandomEmail()
+	_, ownerId := createTestUser(t)
+	participantEmail, _ := createTestUser(t)
 	participantStatus := util.StringWithCharset(20000)
 	participant := Participant{
 		ParentListID: id,
@@ -703,20 +735,19 @@ func TestGetParticipants(t *testing.T) {
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Failed to create shoppinglist %s", err.Error())
 	}
 
-	_, err = AddParticipant(participant)
+	_, err = AddParticipant(ctx, participant)
 	if err != nil {
 		t.Errorf("Error while adding participant: %s", err)
 	}
 
-	participants, err := GetParticipants(id)
+	participants, err := GetParticipants(ctx, id)
 	if err != nil {
 		t.Errorf("Error while getting participants: %s", err)
 	}
@@ -727,13 +758,15 @@ func TestGetParticipants(t *testing.T) {
 }
 
 func TestRemoveParticipant(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Remove Participant", func(t *testing.T) {
 		id := util.RandomInt() + 50000
 		title := "title3332999" + util.StringWithCharset(20000)
-		owner := "owner999" + util.StringWithCharset(30000)
-		participantEmail := util.RandomEmail()
+		_, ownerId := createTestUser(t)
+		participantEmail, _ := createTestUser(t)
 		participantStatus := util.StringWithCharset(20000)
 		participant := Participant{
 			ParentListID: id,
@@ -743,30 +776,29 @@ func TestRemoveParticipant(t *testing.T) {
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Failed to create shoppinglist %s", err)
 		}
 
-		_, err = AddParticipant(participant)
+		_, err = AddParticipant(ctx, participant)
 		if err != nil {
 			t.Errorf("Error while adding participant: %s", err)
 		}
 
-		participants, err := GetParticipants(id)
+		participants, err := GetParticipants(ctx, id)
 		if err != nil {
 			t.Errorf("Error while getting participants: %s", err)
 		}
 
-		delErr := RemoveParticipant(id, participants[0].ID)
+		delErr := RemoveParticipant(ctx, id, participants[0].ID)
 		if delErr != nil {
 			t.Errorf("Error while removing participant: %s", err)
 		}
 
-		participantsAfter, err := GetParticipants(id)
+		participantsAfter, err := GetParticipants(ctx, id)
 		if err != nil {
 			t.Errorf("Error while getting participants: %s", err)
 		}
@@ -781,19 +813,21 @@ func TestRemoveParticipant(t *testing.T) {
 	t.Run("Remove Participant when Shoppinglist doesn't exist", func(t *testing.T) {
 		id := util.RandomIntWithLength(50000)
 
-		err := RemoveParticipant(id, 348756324753645)
+		err := RemoveParticipant(ctx, id, 348756324753645)
 
 		NotNil(t, err)
 	})
 }
 
 func TestDeleteItem(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	itemID := util.RandomIntWithLength(50000)
 	title := "title3332999" + util.StringWithCharset(20000)
-	owner := "owner999" + util.StringWithCharset(30000)
+	_, ownerId := createTestUser(t)
 	items := []*Item{
 		{
 			ParentListID: id,
@@ -806,25 +840,24 @@ func TestDeleteItem(t *testing.T) {
 		ID:    id,
 		Title: title,
 		Items: items,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Failed to create shoppinglist %s", err.Error())
 	}
 
-	item, err := AddItem(*items[0])
+	item, err := AddItem(ctx, *items[0])
 	if err != nil {
 		t.Errorf("Failed to edit shoppinglist %s", err.Error())
 	}
 
-	err = DeleteItem(id, itemID)
+	err = DeleteItem(ctx, id, itemID)
 	if err != nil {
 		t.Errorf("Error while deleting item: %s", err)
 	}
 
-	it, err := GetItems(id)
+	it, err := GetItems(ctx, id)
 	if err != nil {
 		t.Errorf("Error while getting items: %s", err)
 	}
@@ -839,13 +872,15 @@ func TestDeleteItem(t *testing.T) {
 }
 
 func TestUpdateItems(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	id := util.RandomIntWithLength(50000)
 	itemID := util.RandomIntWithLength(50000)
 	itemID2 := util.RandomIntWithLength(50000)
 	title := "title3332999" + util.StringWithCharset(200)
-	owner := "owner999" + util.StringWithCharset(300)
+	_, ownerId := createTestUser(t)
 	items := []*Item{
 		{
 			ParentListID: id,
@@ -863,16 +898,15 @@ func TestUpdateItems(t *testing.T) {
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Error while creating shoppinglist: %s", err)
 	}
 
 	for _, item := range items {
-		_, err = AddItem(*item)
+		_, err = AddItem(ctx, *item)
 		if err != nil {
 			t.Errorf("Error while adding item: %s", err)
 		}
@@ -897,12 +931,12 @@ func TestUpdateItems(t *testing.T) {
 		},
 	}
 
-	err = UpdateItems(id, updateItems)
+	err = UpdateItems(ctx, id, updateItems)
 	if err != nil {
 		t.Errorf("Error while updating items: %s", err)
 	}
 
-	i, err := GetItems(id)
+	i, err := GetItems(ctx, id)
 	if err != nil {
 		t.Errorf("Error while getting items: %s", err)
 	}
@@ -914,13 +948,15 @@ func TestUpdateItems(t *testing.T) {
 }
 
 func TestGetListsByParticipant(t *testing.T) {
-	Setup()
+	ctx := context.Background()
+
+	Setup(config.MustLoad(nil))
 
 	t.Run("Get Lists By Participant with 1 list", func(t *testing.T) {
 		id := util.RandomIntWithLength(50000)
 		title := "title3332999" + util.StringWithCharset(200)
-		owner := "owner999" + util.StringWithCharset(300)
-		participantEmail := util.RandomEmail()
+		owner, ownerId := createTestUser(t)
+		participantEmail, _ := createTestUser(t)
 		participant := Participant{
 			ParentListID: id,
 			Email:        participantEmail,
@@ -930,21 +966,20 @@ func TestGetListsByParticipant(t *testing.T) {
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Error while creating shoppinglist: %s", err)
 		}
 
-		_, err = AddParticipant(participant)
+		_, err = AddParticipant(ctx, participant)
 
 		if err != nil {
 			t.Errorf("Error while adding participant to list: %s", err)
 		}
 
-		lists, err := GetListsByParticipant(participantEmail)
+		lists, err := GetListsByParticipant(ctx, participantEmail)
 		if err != nil {
 			t.Errorf("Error while getting lists by participant: %s", err)
 		}
@@ -957,10 +992,10 @@ func TestGetListsByParticipant(t *testing.T) {
 	t.Run("Get Lists By Participant with 3 lists", func(t *testing.T) {
 		id := util.RandomIntWithLength(90000)
 		title := "title3332999" + util.StringWithCharset(200)
-		owner := "owner999" + util.StringWithCharset(300)
-		participantEmail := util.RandomEmail()
-		participantEmail2 := util.RandomEmail()
-		participantEmail3 := util.RandomEmail()
+		owner, ownerId := createTestUser(t)
+		participantEmail, _ := createTestUser(t)
+		participantEmail2, _ := createTestUser(t)
+		participantEmail3, _ := createTestUser(t)
 		participant := Participant{
 			ParentListID: id,
 			Email:        participantEmail,
@@ -982,30 +1017,29 @@ func TestGetListsByParticipant(t *testing.T) {
 		shoppinglist := Shoppinglist{
 			ID:    id,
 			Title: title,
-			Owner: owner,
 		}
 
-		err := CreateList(shoppinglist, 0, false)
+		err := CreateList(ctx, shoppinglist, ownerId, false)
 		if err != nil {
 			t.Errorf("Error while creating shoppinglist: %s", err)
 		}
 
-		_, err = AddParticipant(participant)
+		_, err = AddParticipant(ctx, participant)
 		if err != nil {
 			t.Errorf("Error while adding participant to list: %s", err)
 		}
 
-		_, err = AddParticipant(participant2)
+		_, err = AddParticipant(ctx, participant2)
 		if err != nil {
 			t.Errorf("Error while adding participant to list: %s", err)
 		}
 
-		_, err = AddParticipant(participant3)
+		_, err = AddParticipant(ctx, participant3)
 		if err != nil {
 			t.Errorf("Error while adding participant to list: %s", err)
 		}
 
-		lists, err := GetListsByParticipant(participantEmail)
+		lists, err := GetListsByParticipant(ctx, participantEmail)
 		if err != nil {
 			t.Errorf("Error while getting lists by participant: %s", err)
 		}
@@ -1023,23 +1057,24 @@ func TestGetListsByParticipant(t *testing.T) {
 }
 
 func TestGetListWithoutOwner(t *testing.T) {
+	ctx := context.Background()
+
 	SetupTestAuth()
 
 	id := util.RandomIntWithLength(90000)
 	title := "title3332999" + util.StringWithCharset(200)
-	owner := "owner999" + util.StringWithCharset(300)
+	owner, ownerId := createTestUser(t)
 	shoppinglist := Shoppinglist{
 		ID:    id,
 		Title: title,
-		Owner: owner,
 	}
 
-	err := CreateList(shoppinglist, 0, false)
+	err := CreateList(ctx, shoppinglist, ownerId, false)
 	if err != nil {
 		t.Errorf("Error while creating shoppinglist: %s", err)
 	}
 
-	list, err := GetListWithoutOwner(id)
+	list, err := GetListWithoutOwner(ctx, id)
 	if err != nil {
 		t.Errorf("Error while getting list without owner: %s", err)
 	}
@@ -1,9 +1,14 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/urento/shoppinglist/pkg/e"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -13,66 +18,94 @@ type Shoppinglist struct {
 	ID           int            `json:"id" gorm:"primaryKey"`
 	Title        string         `json:"title"`
 	Items        []*Item        `json:"items" gorm:"foreignKey:ParentListID;"`
-	Owner        string         `json:"owner"`
+	OwnerID      int            `json:"owner_id" gorm:"index;not null"`
+	Owner        string         `json:"owner" gorm:"-"`
 	Participants []*Participant `json:"participants" gorm:"foreignKey:ParentListID;"`
 }
 
-func ExistByID(id int) (bool, error) {
+var (
+	ErrListNotFound = e.New(http.StatusNotFound, e.ERROR_LIST_DOES_NOT_EXIST)
+	ErrListNotOwned = e.New(http.StatusForbidden, e.ERROR_LIST_DOES_NOT_BELONG_TO_TOKEN)
+)
+
+// lists of deactivated accounts are hidden from participants
+const ownerIsActive = "NOT EXISTS (SELECT 1 FROM auths WHERE auths.id = shoppinglists.owner_id AND auths.deactivated_on > 0)"
+
+func ExistByID(ctx context.Context, id int) (bool, error) {
 	var Found bool
-	err := db.Raw("SELECT EXISTS(SELECT created_on FROM shoppinglists WHERE id = ?) AS found", id).Scan(&Found).Error
+	err := db.WithContext(ctx).Raw("SELECT EXISTS(SELECT created_on FROM shoppinglists WHERE id = ?) AS found", id).Scan(&Found).Error
 	return Found, err
 }
 
-func GetTotalListsByOwner(ownerID string) (int64, error) {
+func GetTotalListsByOwner(ctx context.Context, owner string) (int64, error) {
 	var count int64
-	if err := db.Model(&Shoppinglist{}).Where("owner = ?", ownerID).Count(&count).Error; err != nil {
+	if err := db.WithContext(ctx).Model(&Shoppinglist{}).Where("owner_id = "+userIdByEmail, owner).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func GetLists(owner string, offset int) ([]Shoppinglist, error) {
+// CountShoppinglists is the number of all shoppinglists, for the metrics
+func CountShoppinglists(ctx context.Context) (int64, error) {
+	var count int64
+	err := db.WithContext(ctx).Model(&Shoppinglist{}).Count(&count).Error
+	return count, err
+}
+
+func GetLists(ctx context.Context, owner string, offset int) ([]Shoppinglist, error) {
 	var lists []Shoppinglist
-	err := db.Preload("Participants").Omit("Items").Where("owner = ?", owner).Limit(6).Offset(offset).Find(&lists).Error
+	err := db.WithContext(ctx).Preload("Participants").Omit("Items").Where("owner_id = "+userIdByEmail, owner).Limit(6).Offset(offset).Find(&lists).Error
 	if err != nil {
 		return nil, err
 	}
-	return lists, nil
+	return lists, fillListEmails(ctx, lists)
 }
 
-func GetList(id int, owner string) (*Shoppinglist, error) {
+func GetList(ctx context.Context, id int, owner string) (*Shoppinglist, error) {
 	var list Shoppinglist
-	err := db.Preload(clause.Associations).Where("id = ?", id).Where("owner = ?", owner).First(&list).Error
+	err := db.WithContext(ctx).Preload(clause.Associations).Where("id = ?", id).Where("owner_id = "+userIdByEmail, owner).First(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrListNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &list, nil
+
+	lists := []Shoppinglist{list}
+	err = fillListEmails(ctx, lists)
+	return &lists[0], err
 }
 
-func GetListWithoutOwner(id int) (*Shoppinglist, error) {
+func GetListWithoutOwner(ctx context.Context, id int) (*Shoppinglist, error) {
 	var list Shoppinglist
-	err := db.Preload(clause.Associations).Where("id = ?", id).First(&list).Error
+	err := db.WithContext(ctx).Preload(clause.Associations).Where("id = ?", id).Where(ownerIsActive).First(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrListNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &list, nil
+
+	lists := []Shoppinglist{list}
+	err = fillListEmails(ctx, lists)
+	return &lists[0], err
 }
 
-func GetListByEmail(email string, offset int) (*[]Shoppinglist, error) {
+func GetListByEmail(ctx context.Context, email string, offset int) (*[]Shoppinglist, error) {
 	var list []Shoppinglist
-	err := db.Model(&Shoppinglist{}).Preload("Participants").Where("owner = ?", email).Limit(6).Offset(offset).Find(&list).Error
+	err := db.WithContext(ctx).Model(&Shoppinglist{}).Preload("Participants").Where("owner_id = "+userIdByEmail, email).Limit(6).Offset(offset).Find(&list).Error
 	if err != nil {
 		return nil, err
 	}
-	return &list, nil
+	return &list, fillListEmails(ctx, list)
 }
 
-func EditList(id int, data Shoppinglist) error {
-	err := db.Omit(clause.Associations).Where("id = ?", id).Updates(&data).Error
+func EditList(ctx context.Context, id int, data Shoppinglist) error {
+	err := db.WithContext(ctx).Omit(clause.Associations).Where("id = ?", id).Updates(&data).Error
 	return err
 }
 
-func CreateList(data Shoppinglist, userId int, withNotification bool) error {
+func CreateList(ctx context.Context, data Shoppinglist, userId int, withNotification bool) error {
 	if withNotification {
 		notification := Notification{
 			UserID:           userId,
@@ -82,30 +115,31 @@ func CreateList(data Shoppinglist, userId int, withNotification bool) error {
 			Date:             time.Now().Format("02.01.2006"),
 		}
 
-		if err := CreateNotification(notification); err != nil {
+		if err := CreateNotification(ctx, notification); err != nil {
 			return err
 		}
 	}
 
-	err := db.Model(&Shoppinglist{}).Omit(clause.Associations).Create(&data).Error
+	data.OwnerID = userId
+	err := db.WithContext(ctx).Model(&Shoppinglist{}).Omit(clause.Associations).Create(&data).Error
 	return err
 }
 
-func DeleteList(id int) error {
-	itemsCount := db.Model(&Shoppinglist{}).Where("id = ?", id).Association("Items").Count()
+func DeleteList(ctx context.Context, id int) error {
+	itemsCount := db.WithContext(ctx).Model(&Shoppinglist{}).Where("id = ?", id).Association("Items").Count()
 
 	if itemsCount > 0 {
-		if err := db.Model(&Shoppinglist{}).Where("id = ?", id).Association("Items").Delete(&Shoppinglist{ID: id}); err != nil {
+		if err := db.WithContext(ctx).Model(&Shoppinglist{}).Where("id = ?", id).Association("Items").Delete(&Shoppinglist{ID: id}); err != nil {
 			return err
 		}
 	}
 
-	err := db.Where("id = ?", id).Delete(&Shoppinglist{ID: id}).Error
+	err := db.WithContext(ctx).Where("id = ?", id).Delete(&Shoppinglist{ID: id}).Error
 	return err
 }
 
-func BelongsShoppinglistToEmail(email string, id int) (bool, error) {
+func BelongsShoppinglistToEmail(ctx context.Context, email string, id int) (bool, error) {
 	var Count int64
-	err := db.Model(&Shoppinglist{}).Where("id = ?", id).Where("owner = ?", email).Count(&Count).Limit(1).Error
+	err := db.WithContext(ctx).Model(&Shoppinglist{}).Where("id = ?", id).Where("owner_id = "+userIdByEmail, email).Count(&Count).Limit(1).Error
 	return Count >= 1, err
 }
@@ -0,0 +1,245 @@
+package models
+
+import "context"
+
+// The stores are what the handlers use to read and write data. NewGormStores returns the postgres implementation,
+// models/memory an in-memory one so handlers can be tested without a database
+
+type ShoppinglistStore interface {
+	ExistByID(ctx context.Context, id int) (bool, error)
+	GetList(ctx context.Context, id int, owner string) (*Shoppinglist, error)
+	GetListWithoutOwner(ctx context.Context, id int) (*Shoppinglist, error)
+	GetListByEmail(ctx context.Context, email string, offset int) (*[]Shoppinglist, error)
+	GetListsByParticipant(ctx context.Context, email string) ([]Shoppinglist, error)
+	CreateList(ctx context.Context, data Shoppinglist, userId int, withNotification bool) error
+	EditList(ctx context.Context, id int, data Shoppinglist) error
+	DeleteList(ctx context.Context, id int) error
+	BelongsShoppinglistToEmail(ctx context.Context, email string, id int) (bool, error)
+}
+
+type ItemStore interface {
+	AddItem(ctx context.Context, item Item) (*Item, error)
+	GetItems(ctx context.Context, parentListId int) ([]Item, error)
+	UpdateItem(ctx context.Context, item Item) error
+	UpdateItems(ctx context.Context, parentListId int, items []Item) error
+	DeleteItem(ctx context.Context, parentListId, id int) error
+}
+
+type ParticipantStore interface {
+	AddParticipant(ctx context.Context, participant Participant) (Participant, error)
+	RemoveParticipant(ctx context.Context, parentListId, id int) error
+	GetParticipants(ctx context.Context, parentListId int) ([]Participant, error)
+	IsParticipantAlreadyIncluded(ctx context.Context, email string, parentListId int) (bool, error)
+	GetPendingRequests(ctx context.Context, email string) ([]Participant, error)
+	GetPendingRequestsFromShoppinglist(ctx context.Context, email string, id int) ([]Participant, error)
+	AcceptRequest(ctx context.Context, id int, email string) error
+	DeleteRequest(ctx context.Context, id int, email string) error
+	DeleteAll(ctx context.Context, email string) error
+	LeaveShoppinglist(ctx context.Context, id int, email string) error
+}
+
+type AuthStore interface {
+	CreateAccount(ctx context.Context, email, username, password, ip string) error
+	Exists(ctx context.Context, email string) (bool, error)
+	GetUser(ctx context.Context, email string) (*Auth, error)
+	GetUserIDByEmail(ctx context.Context, email string) (int, error)
+	GetRank(ctx context.Context, email string) (string, error)
+	CheckPassword(ctx context.Context, email, password string) (bool, error)
+	IsTwoFactorEnabled(ctx context.Context, email string) (bool, error)
+	GetTwoFactorMethod(ctx context.Context, email string) (string, error)
+	IsDeactivated(ctx context.Context, email string) (bool, error)
+}
+
+type NotificationStore interface {
+	CreateNotification(ctx context.Context, notification Notification) error
+	HasUnreadNotifications(ctx context.Context, userId int) (bool, error)
+	GetNotifications(ctx context.Context, userId int) ([]Notification, error)
+	GetNotification(ctx context.Context, userId, id int) (Notification, error)
+	DeleteNotification(ctx context.Context, userId, id int) error
+	MarkAllNotificationsAsRead(ctx context.Context, userId int) error
+}
+
+type Stores struct {
+	Shoppinglists ShoppinglistStore
+	Items         ItemStore
+	Participants  ParticipantStore
+	Auth          AuthStore
+	Notifications NotificationStore
+}
+
+// NewGormStores returns the stores backed by the database opened in Setup
+func NewGormStores() Stores {
+	return Stores{
+		Shoppinglists: gormShoppinglistStore{},
+		Items:         gormItemStore{},
+		Participants:  gormParticipantStore{},
+		Auth:          gormAuthStore{},
+		Notifications: gormNotificationStore{},
+	}
+}
+
+type gormShoppinglistStore struct{}
+
+func (gormShoppinglistStore) ExistByID(ctx context.Context, id int) (bool, error) {
+	return ExistByID(ctx, id)
+}
+
+func (gormShoppinglistStore) GetList(ctx context.Context, id int, owner string) (*Shoppinglist, error) {
+	return GetList(ctx, id, owner)
+}
+
+func (gormShoppinglistStore) GetListWithoutOwner(ctx context.Context, id int) (*Shoppinglist, error) {
+	return GetListWithoutOwner(ctx, id)
+}
+
+func (gormShoppinglistStore) GetListByEmail(ctx context.Context, email string, offset int) (*[]Shoppinglist, error) {
+	return GetListByEmail(ctx, email, offset)
+}
+
+func (gormShoppinglistStore) GetListsByParticipant(ctx context.Context, email string) ([]Shoppinglist, error) {
+	return GetListsByParticipant(ctx, email)
+}
+
+func (gormShoppinglistStore) CreateList(ctx context.Context, data Shoppinglist, userId int, withNotification bool) error {
+	return CreateList(ctx, data, userId, withNotification)
+}
+
+func (gormShoppinglistStore) EditList(ctx context.Context, id int, data Shoppinglist) error {
+	return EditList(ctx, id, data)
+}
+
+func (gormShoppinglistStore) DeleteList(ctx context.Context, id int) error {
+	return DeleteList(ctx, id)
+}
+
+func (gormShoppinglistStore) BelongsShoppinglistToEmail(ctx context.Context, email string, id int) (bool, error) {
+	return BelongsShoppinglistToEmail(ctx, email, id)
+}
+
+type gormItemStore struct{}
+
+func (gormItemStore) AddItem(ctx context.Context, item Item) (*Item, error) {
+	return AddItem(ctx, item)
+}
+
+func (gormItemStore) GetItems(ctx context.Context, parentListId int) ([]Item, error) {
+	return GetItems(ctx, parentListId)
+}
+
+func (gormItemStore) UpdateItem(ctx context.Context, item Item) error {
+	return UpdateItem(ctx, item)
+}
+
+func (gormItemStore) UpdateItems(ctx context.Context, parentListId int, items []Item) error {
+	return UpdateItems(ctx, parentListId, items)
+}
+
+func (gormItemStore) DeleteItem(ctx context.Context, parentListId, id int) error {
+	return DeleteItem(ctx, parentListId, id)
+}
+
+type gormParticipantStore struct{}
+
+func (gormParticipantStore) AddParticipant(ctx context.Context, participant Participant) (Participant, error) {
+	return AddParticipant(ctx, participant)
+}
+
+func (gormParticipantStore) RemoveParticipant(ctx context.Context, parentListId, id int) error {
+	return RemoveParticipant(ctx, parentListId, id)
+}
+
+func (gormParticipantStore) GetParticipants(ctx context.Context, parentListId int) ([]Participant, error) {
+	return GetParticipants(ctx, parentListId)
+}
+
+func (gormParticipantStore) IsParticipantAlreadyIncluded(ctx context.Context, email string, parentListId int) (bool, error) {
+	return IsParticipantAlreadyIncluded(ctx, email, parentListId)
+}
+
+func (gormParticipantStore) GetPendingRequests(ctx context.Context, email string) ([]Participant, error) {
+	return GetPendingRequests(ctx, email)
+}
+
+func (gormParticipantStore) GetPendingRequestsFromShoppinglist(ctx context.Context, email string, id int) ([]Participant, error) {
+	return GetPendingRequestsFromShoppinglist(ctx, email, id)
+}
+
+func (gormParticipantStore) AcceptRequest(ctx context.Context, id int, email string) error {
+	return AcceptRequest(ctx, id, email)
+}
+
+func (gormParticipantStore) DeleteRequest(ctx context.Context, id int, email string) error {
+	return DeleteRequest(ctx, id, email)
+}
+
+func (gormParticipantStore) DeleteAll(ctx context.Context, email string) error {
+	return DeleteAll(ctx, email)
+}
+
+func (gormParticipantStore) LeaveShoppinglist(ctx context.Context, id int, email string) error {
+	return LeaveShoppinglist(ctx, id, email)
+}
+
+type gormAuthStore struct{}
+
+func (gormAuthStore) CreateAccount(ctx context.Context, email, username, password, ip string) error {
+	return CreateAccount(ctx, email, username, password, ip)
+}
+
+func (gormAuthStore) Exists(ctx context.Context, email string) (bool, error) {
+	return Exists(ctx, email)
+}
+
+func (gormAuthStore) GetUser(ctx context.Context, email string) (*Auth, error) {
+	return GetUser(ctx, email)
+}
+
+func (gormAuthStore) GetUserIDByEmail(ctx context.Context, email string) (int, error) {
+	return GetUserIDByEmail(ctx, email)
+}
+
+func (gormAuthStore) GetRank(ctx context.Context, email string) (string, error) {
+	return GetRank(ctx, email)
+}
+
+func (gormAuthStore) CheckPassword(ctx context.Context, email, password string) (bool, error) {
+	return CheckPassword(ctx, email, password)
+}
+
+func (gormAuthStore) IsTwoFactorEnabled(ctx context.Context, email string) (bool, error) {
+	return IsTwoFactorEnabled(ctx, email)
+}
+
+func (gormAuthStore) GetTwoFactorMethod(ctx context.Context, email string) (string, error) {
+	return GetTwoFactorMethod(ctx, email)
+}
+
+func (gormAuthStore) IsDeactivated(ctx context.Context, email string) (bool, error) {
+	return IsDeactivated(ctx, email)
+}
+
+type gormNotificationStore struct{}
+
+func (gormNotificationStore) CreateNotification(ctx context.Context, notification Notification) error {
+	return CreateNotification(ctx, notification)
+}
+
+func (gormNotificationStore) HasUnreadNotifications(ctx context.Context, userId int) (bool, error) {
+	return HasUnreadNotifications(ctx, userId)
+}
+
+func (gormNotificationStore) GetNotifications(ctx context.Context, userId int) ([]Notification, error) {
+	return GetNotifications(ctx, userId)
+}
+
+func (gormNotificationStore) GetNotification(ctx context.Context, userId, id int) (Notification, error) {
+	return GetNotification(ctx, userId, id)
+}
+
+func (gormNotificationStore) DeleteNotification(ctx context.Context, userId, id int) error {
+	return DeleteNotification(ctx, userId, id)
+}
+
+func (gormNotificationStore) MarkAllNotificationsAsRead(ctx context.Context, userId int) error {
+	return MarkAllNotificationsAsRead(ctx, userId)
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/envelope"
+	"gorm.io/gorm/clause"
+)
+
+// TOTPSecret is the source of truth for 2FA secrets; redis only caches the decrypted secret
+type TOTPSecret struct {
+	Model
+
+	ID              int    `gorm:"primaryKey" json:"id"`
+	UserID          int    `gorm:"uniqueIndex;not null" json:"user_id"`
+	EncryptedKey    string `json:"-"`
+	EncryptedSecret string `json:"-"`
+}
+
+func SaveTOTPSecret(ctx context.Context, email, secret string) error {
+	userId, err := GetUserIDByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if userId <= 0 {
+		return ErrUserNotFound
+	}
+
+	encryptedKey, encryptedSecret, err := envelope.Encrypt([]byte(secret))
+	if err != nil {
+		return err
+	}
+
+	totpSecret := TOTPSecret{
+		UserID:          userId,
+		EncryptedKey:    encryptedKey,
+		EncryptedSecret: encryptedSecret,
+	}
+
+	err = db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"encrypted_key", "encrypted_secret", "modified_on", "deleted_at"}),
+	}).Create(&totpSecret).Error
+	if err != nil {
+		return err
+	}
+
+	return cache.CacheTOTPSecret(ctx, email, secret)
+}
+
+func GetTOTPSecret(ctx context.Context, email string) (string, error) {
+	secret, err := cache.GetTOTPSecret(ctx, email)
+	if err == nil {
+		return secret, nil
+	}
+
+	var totpSecret TOTPSecret
+	err = db.WithContext(ctx).Model(&TOTPSecret{}).Where("user_id = "+userIdByEmail, email).First(&totpSecret).Error
+	if err != nil {
+		return "", err
+	}
+
+	decrypted, err := envelope.Decrypt(totpSecret.EncryptedKey, totpSecret.EncryptedSecret)
+	if err != nil {
+		return "", err
+	}
+
+	err = cache.CacheTOTPSecret(ctx, email, string(decrypted))
+	if err != nil {
+		return "", err
+	}
+
+	return string(decrypted), nil
+}
+
+func HasTOTPSecret(ctx context.Context, email string) (bool, error) {
+	var Found bool
+	err := db.WithContext(ctx).Raw("SELECT EXISTS(SELECT id FROM totp_secrets WHERE user_id = "+userIdByEmail+" AND deleted_at IS NULL) AS found", email).Scan(&Found).Error
+	return Found, err
+}
+
+func DeleteTOTPSecret(ctx context.Context, email string) error {
+	err := db.WithContext(ctx).Unscoped().Where("user_id = "+userIdByEmail, email).Delete(&TOTPSecret{}).Error
+	if err != nil {
+		return err
+	}
+
+	return cache.DeleteTOTPSecret(ctx, email)
+}
+
+// ImportTOTPSecret persists a secret that only exists in redis and keeps an already persisted one. Secrets of emails
+// without account are skipped
+func ImportTOTPSecret(ctx context.Context, email, secret string) (bool, error) {
+	has, err := HasTOTPSecret(ctx, email)
+	if err != nil || has {
+		return false, err
+	}
+
+	exists, err := Exists(ctx, email)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	err = SaveTOTPSecret(ctx, email, secret)
+	return err == nil, err
+}
+
+// ImportCachedTOTPSecrets persists every secret that is still only stored in redis. Login only checks postgres for
+// a secret, so this has to be done before requests are served
+func ImportCachedTOTPSecrets(ctx context.Context) (int, int, error) {
+	secrets, err := cache.GetAllTOTPSecrets(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	migrated := 0
+	for email, secret := range secrets {
+		imported, err := ImportTOTPSecret(ctx, email, secret)
+		if err != nil {
+			return migrated, len(secrets), err
+		}
+
+		if imported {
+			migrated++
+		}
+	}
+
+	return migrated, len(secrets), nil
+}
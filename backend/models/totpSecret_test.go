@@ -0,0 +1,171 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/util"
+)
+
+func TestSaveAndGetTOTPSecret(t *testing.T) {
+	ctx := context.Background()
+
+	SetupTestAuth()
+
+	t.Run("Save and Get TOTP Secret", func(t *testing.T) {
+		email, _ := createTestUser(t)
+		secret := util.StringWithCharset(32)
+
+		err := SaveTOTPSecret(ctx, email, secret)
+		if err != nil {
+			t.Errorf("Error while saving totp secret: %s", err)
+		}
+
+		totpSecret, err := GetTOTPSecret(ctx, email)
+		if err != nil {
+			t.Errorf("Error while getting totp secret: %s", err)
+		}
+
+		Equal(t, secret, totpSecret)
+	})
+
+	t.Run("Get TOTP Secret after the cache was flushed", func(t *testing.T) {
+		email, _ := createTestUser(t)
+		secret := util.StringWithCharset(32)
+
+		err := SaveTOTPSecret(ctx, email, secret)
+		if err != nil {
+			t.Errorf("Error while saving totp secret: %s", err)
+		}
+
+		err = cache.DeleteTOTPSecret(ctx, email)
+		if err != nil {
+			t.Errorf("Error while deleting cached totp secret: %s", err)
+		}
+
+		totpSecret, err := GetTOTPSecret(ctx, email)
+		if err != nil {
+			t.Errorf("Error while getting totp secret: %s", err)
+		}
+
+		cached, err := cache.IsTOTPSecretCached(ctx, email)
+		if err != nil {
+			t.Errorf("Error while checking if the totp secret is cached: %s", err)
+		}
+
+		Equal(t, secret, totpSecret)
+		True(t, cached)
+	})
+
+	t.Run("Secret is encrypted at rest", func(t *testing.T) {
+		email, _ := createTestUser(t)
+		secret := util.StringWithCharset(32)
+
+		err := SaveTOTPSecret(ctx, email, secret)
+		if err != nil {
+			t.Errorf("Error while saving totp secret: %s", err)
+		}
+
+		var totpSecret TOTPSecret
+		err = db.Model(&TOTPSecret{}).Where("user_id = "+userIdByEmail, email).First(&totpSecret).Error
+		if err != nil {
+			t.Errorf("Error while getting totp secret row: %s", err)
+		}
+
+		NotEqual(t, secret, totpSecret.EncryptedSecret)
+		NotContains(t, totpSecret.EncryptedSecret, secret)
+	})
+
+	t.Run("Get TOTP Secret after the email changed", func(t *testing.T) {
+		email, userId := createTestUser(t)
+		newEmail := util.RandomEmail()
+		secret := util.StringWithCharset(32)
+
+		err := SaveTOTPSecret(ctx, email, secret)
+		if err != nil {
+			t.Errorf("Error while saving totp secret: %s", err)
+		}
+
+		err = db.Model(&Auth{}).Where("id = ?", userId).Update("e_mail", newEmail).Error
+		if err != nil {
+			t.Errorf("Error while changing the email: %s", err)
+		}
+
+		err = cache.DeleteTOTPSecret(ctx, email)
+		if err != nil {
+			t.Errorf("Error while deleting cached totp secret: %s", err)
+		}
+
+		totpSecret, err := GetTOTPSecret(ctx, newEmail)
+		if err != nil {
+			t.Errorf("Error while getting totp secret: %s", err)
+		}
+
+		Equal(t, secret, totpSecret)
+	})
+}
+
+func TestDeleteTOTPSecretFromDatabase(t *testing.T) {
+	ctx := context.Background()
+
+	SetupTestAuth()
+
+	email, _ := createTestUser(t)
+
+	err := SaveTOTPSecret(ctx, email, util.StringWithCharset(32))
+	if err != nil {
+		t.Errorf("Error while saving totp secret: %s", err)
+	}
+
+	err = DeleteTOTPSecret(ctx, email)
+	if err != nil {
+		t.Errorf("Error while deleting totp secret: %s", err)
+	}
+
+	has, err := HasTOTPSecret(ctx, email)
+	if err != nil {
+		t.Errorf("Error while checking if the user has a totp secret: %s", err)
+	}
+
+	_, err = GetTOTPSecret(ctx, email)
+
+	False(t, has)
+	NotNil(t, err)
+}
+
+func TestImportTOTPSecret(t *testing.T) {
+	ctx := context.Background()
+
+	SetupTestAuth()
+
+	email, _ := createTestUser(t)
+	secret := util.StringWithCharset(32)
+
+	imported, err := ImportTOTPSecret(ctx, email, secret)
+	if err != nil {
+		t.Errorf("Error while importing totp secret: %s", err)
+	}
+
+	importedAgain, err := ImportTOTPSecret(ctx, email, util.StringWithCharset(32))
+	if err != nil {
+		t.Errorf("Error while importing totp secret: %s", err)
+	}
+
+	totpSecret, err := GetTOTPSecret(ctx, email)
+	if err != nil {
+		t.Errorf("Error while getting totp secret: %s", err)
+	}
+
+	True(t, imported)
+	False(t, importedAgain)
+	Equal(t, secret, totpSecret)
+
+	t.Run("Email without account", func(t *testing.T) {
+		imported, err := ImportTOTPSecret(ctx, util.RandomEmail(), util.StringWithCharset(32))
+
+		False(t, imported)
+		Nil(t, err)
+	})
+}
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urento/shoppinglist/pkg/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// userIdByEmail resolves the email of the session inside the query since rows reference the user id
+const userIdByEmail = "(SELECT id FROM auths WHERE e_mail = ?)"
+
+// userReference is a column that references auths.id; emailColumn is the column that stored the email before
+type userReference struct {
+	model       interface{}
+	table       string
+	emailColumn string
+	column      string
+	onDelete    string
+}
+
+var userReferences = []userReference{
+	{&Shoppinglist{}, "shoppinglists", "owner", "owner_id", "CASCADE"},
+	{&Participant{}, "participants", "email", "user_id", "CASCADE"},
+	{&Participant{}, "participants", "request_from", "request_from_id", "SET NULL"},
+	{&BackupCode{}, "backup_codes", "owner", "user_id", "CASCADE"},
+	{&ResetPassword{}, "reset_passwords", "email", "user_id", "CASCADE"},
+	{&EmailChange{}, "email_changes", "old_email", "user_id", "CASCADE"},
+}
+
+// totpSecretReferences are the secrets that were still stored by email after reference_users_by_id was released
+var totpSecretReferences = []userReference{
+	{&TOTPSecret{}, "totp_secrets", "owner", "user_id", "CASCADE"},
+}
+
+// lateUserReferences always stored the user id but only got their foreign keys after user_foreign_keys was released
+var lateUserReferences = []userReference{
+	{&LoginEvent{}, "login_events", "", "user_id", "CASCADE"},
+	{&WebAuthnCredential{}, "web_authn_credentials", "", "user_id", "CASCADE"},
+}
+
+// migrateEmailReferences replaces the email columns with user ids. Rows that reference an email without an account
+// can't be kept in a required column and are deleted: lists of owners without account together with their items
+// and participants, invitations to emails without account, their backup codes, password resets and email changes.
+// Optional references (the inviting user) are set to NULL instead. The number of deleted rows is logged per table
+func migrateEmailReferences(tx *gorm.DB) error {
+	return referenceUsersById(tx, userReferences)
+}
+
+// migrateTOTPSecretOwners moves the totp secrets to the user id, so changing the email doesn't have to rename them.
+// Secrets of emails without account are deleted
+func migrateTOTPSecretOwners(tx *gorm.DB) error {
+	if err := referenceUsersById(tx, totpSecretReferences); err != nil {
+		return err
+	}
+
+	err := execStatements([]string{"CREATE UNIQUE INDEX IF NOT EXISTS idx_totp_secrets_user_id ON totp_secrets (user_id)"})(tx)
+	if err != nil {
+		return err
+	}
+	return addUserConstraints(tx, totpSecretReferences)
+}
+
+func referenceUsersById(tx *gorm.DB, refs []userReference) error {
+	for _, ref := range refs {
+		if !tx.Migrator().HasColumn(ref.model, ref.emailColumn) {
+			continue
+		}
+
+		err := execStatements([]string{
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s bigint", ref.table, ref.column),
+			fmt.Sprintf("UPDATE %[1]s SET %[2]s = auths.id FROM auths WHERE auths.e_mail = %[1]s.%[3]s", ref.table, ref.column, ref.emailColumn),
+		})(tx)
+		if err != nil {
+			return err
+		}
+
+		if ref.onDelete == "CASCADE" {
+			if ref.table == "shoppinglists" {
+				orphans := "(SELECT id FROM shoppinglists WHERE owner_id IS NULL)"
+				for _, table := range []string{"items", "participants"} {
+					err := deleteOrphans(tx, table, fmt.Sprintf("DELETE FROM %s WHERE parent_list_id IN %s", table, orphans))
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			err := deleteOrphans(tx, ref.table, fmt.Sprintf("DELETE FROM %s WHERE %s IS NULL", ref.table, ref.column))
+			if err != nil {
+				return err
+			}
+
+			err = execStatements([]string{fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", ref.table, ref.column)})(tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = execStatements([]string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", ref.table, ref.emailColumn)})(tx)
+		if err != nil {
+			return err
+		}
+
+		logging.L().Info("migrated email reference", zap.String("table", ref.table), zap.String("from", ref.emailColumn), zap.String("to", ref.column))
+	}
+	return nil
+}
+
+// deleteOrphans runs the delete of rows without account and logs how many were lost
+func deleteOrphans(tx *gorm.DB, table, statement string) error {
+	result := tx.Exec(statement)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		logging.L().Warn("deleted rows that reference a missing account", zap.String("table", table), zap.Int64("rows", result.RowsAffected))
+	}
+	return nil
+}
+
+// createUserConstraints adds the foreign keys to auths once the tables exist
+func createUserConstraints(tx *gorm.DB) error {
+	return addUserConstraints(tx, userReferences)
+}
+
+func dropUserConstraints(tx *gorm.DB) error {
+	return dropConstraints(tx, userReferences)
+}
+
+// createLateUserConstraints deletes the rows of accounts that were purged before and adds the foreign keys
+func createLateUserConstraints(tx *gorm.DB) error {
+	for _, ref := range lateUserReferences {
+		statement := fmt.Sprintf("DELETE FROM %[1]s WHERE NOT EXISTS (SELECT 1 FROM auths WHERE auths.id = %[1]s.%[2]s)", ref.table, ref.column)
+		if err := deleteOrphans(tx, ref.table, statement); err != nil {
+			return err
+		}
+	}
+	return addUserConstraints(tx, lateUserReferences)
+}
+
+func dropLateUserConstraints(tx *gorm.DB) error {
+	return dropConstraints(tx, lateUserReferences)
+}
+
+func addUserConstraints(tx *gorm.DB, refs []userReference) error {
+	for _, ref := range refs {
+		name := fmt.Sprintf("fk_%s_%s", ref.table, ref.column)
+		if tx.Migrator().HasConstraint(ref.model, name) {
+			continue
+		}
+
+		err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES auths(id) ON DELETE %s", ref.table, name, ref.column, ref.onDelete)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dropConstraints(tx *gorm.DB, refs []userReference) error {
+	for _, ref := range refs {
+		err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS fk_%s_%s", ref.table, ref.table, ref.column)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emailsByUserId returns the current email of every given user
+func emailsByUserId(ctx context.Context, userIds []int) (map[int]string, error) {
+	emails := make(map[int]string, len(userIds))
+	if len(userIds) == 0 {
+		return emails, nil
+	}
+
+	var users []Auth
+	err := db.WithContext(ctx).Model(&Auth{}).Select("id, e_mail").Where("id IN ?", userIds).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		emails[user.ID] = user.EMail
+	}
+	return emails, nil
+}
+
+// fillListEmails sets the owner and participant emails that are returned to the frontend
+func fillListEmails(ctx context.Context, lists []Shoppinglist) error {
+	var participants []*Participant
+	userIds := make([]int, 0, len(lists))
+	for _, list := range lists {
+		userIds = append(userIds, list.OwnerID)
+		participants = append(participants, list.Participants...)
+	}
+
+	for _, participant := range participants {
+		userIds = append(userIds, participantUserIds(participant)...)
+	}
+
+	emails, err := emailsByUserId(ctx, userIds)
+	if err != nil {
+		return err
+	}
+
+	for i := range lists {
+		lists[i].Owner = emails[lists[i].OwnerID]
+	}
+
+	for _, participant := range participants {
+		setParticipantEmails(participant, emails)
+	}
+	return nil
+}
+
+func fillParticipantEmails(ctx context.Context, participants []Participant) error {
+	var userIds []int
+	for i := range participants {
+		userIds = append(userIds, participantUserIds(&participants[i])...)
+	}
+
+	emails, err := emailsByUserId(ctx, userIds)
+	if err != nil {
+		return err
+	}
+
+	for i := range participants {
+		setParticipantEmails(&participants[i], emails)
+	}
+	return nil
+}
+
+func participantUserIds(participant *Participant) []int {
+	if participant.RequestFromID != nil {
+		return []int{participant.UserID, *participant.RequestFromID}
+	}
+	return []int{participant.UserID}
+}
+
+func setParticipantEmails(participant *Participant, emails map[int]string) {
+	participant.Email = emails[participant.UserID]
+	if participant.RequestFromID != nil {
+		participant.RequestFrom = emails[*participant.RequestFromID]
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/urento/shoppinglist/pkg/e"
+)
+
+var ErrCredentialNotFound = e.New(http.StatusNotFound, e.ERROR_WEBAUTHN_CREDENTIAL_NOT_FOUND)
+
+// WebAuthnCredential is a registered security key or passkey of a user
+type WebAuthnCredential struct {
+	Model
+
+	ID              int    `gorm:"primaryKey" json:"id"`
+	UserID          int    `gorm:"index" json:"userId"`
+	Name            string `json:"name"`
+	CredentialID    []byte `gorm:"uniqueIndex" json:"-"`
+	PublicKey       []byte `json:"-"`
+	AttestationType string `json:"attestation_type"`
+	AAGUID          []byte `json:"-"`
+	SignCount       uint32 `json:"-"`
+	LastUsedOn      int64  `json:"last_used_on"`
+}
+
+func CreateWebAuthnCredential(ctx context.Context, credential WebAuthnCredential) error {
+	exists, err := ExistsUserID(ctx, credential.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	return db.WithContext(ctx).Create(&credential).Error
+}
+
+func GetWebAuthnCredentials(ctx context.Context, userId int) ([]WebAuthnCredential, error) {
+	var credentials []WebAuthnCredential
+	err := db.WithContext(ctx).Model(&WebAuthnCredential{}).Where("user_id = ?", userId).Order("id").Find(&credentials).Error
+	return credentials, err
+}
+
+func HasWebAuthnCredential(ctx context.Context, userId int) (bool, error) {
+	var Found bool
+	err := db.WithContext(ctx).Raw("SELECT EXISTS(SELECT id FROM web_authn_credentials WHERE user_id = ? AND deleted_at IS NULL) AS found", userId).Scan(&Found).Error
+	return Found, err
+}
+
+// UpdateWebAuthnSignCount stores the signature counter reported by the authenticator after a successful login
+func UpdateWebAuthnSignCount(ctx context.Context, userId int, credentialId []byte, signCount uint32, lastUsedOn int64) error {
+	return db.WithContext(ctx).Model(&WebAuthnCredential{}).
+		Where("user_id = ?", userId).Where("credential_id = ?", credentialId).
+		Updates(map[string]interface{}{"sign_count": signCount, "last_used_on": lastUsedOn}).Error
+}
+
+func DeleteWebAuthnCredential(ctx context.Context, userId, id int) error {
+	result := db.WithContext(ctx).Unscoped().Where("user_id = ?", userId).Where("id = ?", id).Delete(&WebAuthnCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected <= 0 {
+		return ErrCredentialNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/logging"
+)
+
+// Error aborts the request with err, the response is written by the Errors middleware. Errors that aren't an
+// e.AppError are answered with a 500
+func (g *Gin) Error(err error) {
+	if err == nil {
+		err = e.New(http.StatusInternalServerError, e.ERROR)
+	}
+
+	_ = g.C.Error(err)
+	g.C.Abort()
+}
+
+// Errors renders the last error added with Gin.Error once the handlers are done. The body has the same shape as
+// every other response, with success "false" and the details of the error in data. The cause is only logged.
+// Errors caused by the request running out of time are left to the deadline middleware
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) <= 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		if errors.Is(err, context.DeadlineExceeded) {
+			return
+		}
+
+		appErr := e.Wrap(err, http.StatusInternalServerError, e.ERROR)
+		if appErr.Err != nil || appErr.Status >= http.StatusInternalServerError {
+			logging.Error(c, err)
+		}
+
+		data := map[string]string{"success": "false"}
+		for key, value := range appErr.Details {
+			data[key] = value
+		}
+
+		c.JSON(appErr.Status, Response{
+			Code: appErr.Code,
+			Msg:  appErr.Message,
+			Data: data,
+		})
+	}
+}
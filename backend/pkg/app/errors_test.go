@@ -0,0 +1,86 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/e"
+)
+
+type errorResponse struct {
+	Code int               `json:"code"`
+	Msg  string            `json:"message"`
+	Data map[string]string `json:"data"`
+}
+
+func TestErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Errors())
+	r.GET("/apperror", func(c *gin.Context) {
+		appG := Gin{C: c}
+		appG.Error(e.New(http.StatusNotFound, e.ERROR_LIST_DOES_NOT_EXIST).WithDetail("id", "1"))
+	})
+	r.GET("/wrapped", func(c *gin.Context) {
+		appG := Gin{C: c}
+		appG.Error(e.Wrap(errors.New("connection refused"), http.StatusInternalServerError, e.ERROR_GET_LIST_FAIL))
+	})
+	r.GET("/plain", func(c *gin.Context) {
+		appG := Gin{C: c}
+		appG.Error(errors.New("connection refused"))
+	})
+	r.GET("/written", func(c *gin.Context) {
+		appG := Gin{C: c}
+		appG.Response(http.StatusOK, e.SUCCESS, nil)
+		_ = c.Error(errors.New("after the response"))
+	})
+
+	request := func(path string) (*httptest.ResponseRecorder, errorResponse) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var response errorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Error while parsing response: %s", err)
+		}
+		return w, response
+	}
+
+	t.Run("TestAppError", func(t *testing.T) {
+		w, response := request("/apperror")
+
+		Equal(t, http.StatusNotFound, w.Code)
+		Equal(t, e.ERROR_LIST_DOES_NOT_EXIST, response.Code)
+		Equal(t, e.GetMsg(e.ERROR_LIST_DOES_NOT_EXIST), response.Msg)
+		Equal(t, map[string]string{"success": "false", "id": "1"}, response.Data)
+	})
+
+	t.Run("TestCauseIsNotSent", func(t *testing.T) {
+		w, response := request("/wrapped")
+
+		Equal(t, http.StatusInternalServerError, w.Code)
+		Equal(t, e.ERROR_GET_LIST_FAIL, response.Code)
+		NotContains(t, w.Body.String(), "connection refused")
+	})
+
+	t.Run("TestPlainError", func(t *testing.T) {
+		w, response := request("/plain")
+
+		Equal(t, http.StatusInternalServerError, w.Code)
+		Equal(t, e.ERROR, response.Code)
+		NotContains(t, w.Body.String(), "connection refused")
+	})
+
+	t.Run("TestAlreadyWritten", func(t *testing.T) {
+		w, response := request("/written")
+
+		Equal(t, http.StatusOK, w.Code)
+		Equal(t, e.SUCCESS, response.Code)
+	})
+}
@@ -1,13 +1,13 @@
 package app
 
 import (
-	"log"
-
 	"github.com/astaxie/beego/validation"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"go.uber.org/zap"
 )
 
 func MarkErrors(errors []*validation.Error) {
 	for _, err := range errors {
-		log.Print(err.Key, err.Message)
+		logging.L().Info("invalid param", zap.String("key", err.Key), zap.String("message", err.Message))
 	}
 }
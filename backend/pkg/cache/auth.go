@@ -3,29 +3,33 @@ package cache
 import (
 	"context"
 	"encoding/json"
-	"errors"
-	"log"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"go.uber.org/zap"
 )
 
 var (
 	redisJwtPrefix            = "jwt:"
 	failedLoginAttemptsPrefix = "login_attempts:"
-	changePasswordPrefix      = "change_password:"
 	tokenPrefix               = "token:"
 	emailPrefix               = "email:"
 	userPrefix                = "user:"
 	totpPrefix                = "totp:"
+	totpPendingPrefix         = "totp_pending:"
+	totpStepPrefix            = "totp_step:"
+	webAuthnSessionPrefix     = "webauthn_session:"
+	mfaPendingPrefix          = "mfa_pending:"
+	emailOTPPrefix            = "email_otp:"
+	emailOTPAttemptsPrefix    = "email_otp_attempts:"
 )
 
-func CacheJWT(email, token string) error {
-	ctx := context.Background()
+func (s *redisStore) CacheJWT(ctx context.Context, email, token string) error {
 	t := 24 * time.Hour
 
-	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
 		err := pipe.Set(ctx, tokenPrefix+email, token, t).Err()
 		if err != nil {
 			return err
@@ -45,9 +49,8 @@ func CacheJWT(email, token string) error {
 	return nil
 }
 
-func InvalidateSpecificJWTToken(email, token string) error {
-	ctx := context.Background()
-	pipe := rdb.Pipeline()
+func (s *redisStore) InvalidateSpecificJWTToken(ctx context.Context, email, token string) error {
+	pipe := s.client.Pipeline()
 
 	err := pipe.Del(ctx, emailPrefix+token).Err()
 	if err != nil {
@@ -72,8 +75,31 @@ func InvalidateSpecificJWTToken(email, token string) error {
 	return nil
 }
 
-func DoesTokenBelongToEmail(email, token string) (bool, error) {
-	val, err := rdb.Get(context.Background(), tokenPrefix+email).Result()
+// InvalidateAllJWTTokens revokes every session of the user including the secretId
+func (s *redisStore) InvalidateAllJWTTokens(ctx context.Context, email string) error {
+	token, err := s.client.Get(ctx, tokenPrefix+email).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(token) > 0 {
+			if err := pipe.Del(ctx, emailPrefix+token).Err(); err != nil {
+				return err
+			}
+		}
+
+		if err := pipe.Del(ctx, tokenPrefix+email).Err(); err != nil {
+			return err
+		}
+
+		return pipe.Del(ctx, redisJwtPrefix+email).Err()
+	})
+	return err
+}
+
+func (s *redisStore) DoesTokenBelongToEmail(ctx context.Context, email, token string) (bool, error) {
+	val, err := s.client.Get(ctx, tokenPrefix+email).Result()
 	if err != nil {
 		return false, err
 	}
@@ -85,28 +111,28 @@ func DoesTokenBelongToEmail(email, token string) (bool, error) {
 	return true, nil
 }
 
-func GetJWTByEmail(email string) (string, error) {
-	val, err := rdb.Get(context.Background(), tokenPrefix+email).Result()
+func (s *redisStore) GetJWTByEmail(ctx context.Context, email string) (string, error) {
+	val, err := s.client.Get(ctx, tokenPrefix+email).Result()
 	if err == redis.Nil {
-		return "", errors.New("jwt token not cached")
+		return "", errJWTNotCached
 	} else if err != nil {
 		return "", err
 	}
 	return val, nil
 }
 
-func GetEmailByJWT(token string) (string, error) {
-	val, err := rdb.Get(context.Background(), emailPrefix+token).Result()
+func (s *redisStore) GetEmailByJWT(ctx context.Context, token string) (string, error) {
+	val, err := s.client.Get(ctx, emailPrefix+token).Result()
 	if err == redis.Nil {
-		return "", errors.New("jwt token not cached")
+		return "", errJWTNotCached
 	} else if err != nil {
 		return "", err
 	}
 	return val, nil
 }
 
-func EmailExists(email string) (bool, error) {
-	exists, err := rdb.Exists(context.Background(), tokenPrefix+email).Result()
+func (s *redisStore) EmailExists(ctx context.Context, email string) (bool, error) {
+	exists, err := s.client.Exists(ctx, tokenPrefix+email).Result()
 	if err != nil {
 		return false, err
 	}
@@ -114,11 +140,9 @@ func EmailExists(email string) (bool, error) {
 	return exists == 1, nil
 }
 
-func Check(email, token string) (bool, error) {
-	ctx := context.Background()
-
+func (s *redisStore) Check(ctx context.Context, email, token string) (bool, error) {
 	//check token
-	t, err := GetJWTByEmail(email)
+	t, err := s.GetJWTByEmail(ctx, email)
 	if err != nil {
 		//error is probably just that the jwt token is not cached
 		return false, nil
@@ -129,9 +153,9 @@ func Check(email, token string) (bool, error) {
 	}
 
 	//update ttl
-	pipe := rdb.Pipeline()
+	pipe := s.client.Pipeline()
 
-	ttl, err := rdb.TTL(ctx, tokenPrefix+email).Result()
+	ttl, err := s.client.TTL(ctx, tokenPrefix+email).Result()
 	if err != nil {
 		return false, err
 	}
@@ -159,22 +183,21 @@ func Check(email, token string) (bool, error) {
 	return true, nil
 }
 
-func IsTokenValid(token string) (bool, error) {
-	exists, err := rdb.Exists(context.Background(), emailPrefix+token).Result()
+func (s *redisStore) IsTokenValid(ctx context.Context, token string) (bool, error) {
+	exists, err := s.client.Exists(ctx, emailPrefix+token).Result()
 	return exists == 1, err
 }
 
-func DeleteTokenByEmail(email, token string) (bool, error) {
-	ctx := context.Background()
-	pipe := rdb.Pipeline()
+func (s *redisStore) DeleteTokenByEmail(ctx context.Context, email, token string) (bool, error) {
+	pipe := s.client.Pipeline()
 
-	exists, err := EmailExists(email)
+	exists, err := s.EmailExists(ctx, email)
 	if err != nil {
 		return false, err
 	}
 
 	if !exists {
-		return false, errors.New("email not cached")
+		return false, errEmailNotCached
 	}
 
 	err = pipe.Del(ctx, tokenPrefix+email).Err()
@@ -195,8 +218,8 @@ func DeleteTokenByEmail(email, token string) (bool, error) {
 	return true, nil
 }
 
-func GetTTLByEmail(email string) (time.Duration, error) {
-	ttl, err := rdb.TTL(context.Background(), tokenPrefix+email).Result()
+func (s *redisStore) GetTTLByEmail(ctx context.Context, email string) (time.Duration, error) {
+	ttl, err := s.client.TTL(ctx, tokenPrefix+email).Result()
 	if err != nil {
 		return -1, err
 	}
@@ -208,8 +231,8 @@ type JWTModel struct {
 	Email    string `json:"email"`
 }
 
-func GenerateSecretId(email string) (string, error) {
-	existingSecretId, has, err := HasSecretId(email)
+func (s *redisStore) GenerateSecretId(ctx context.Context, email string) (string, error) {
+	existingSecretId, has, err := s.HasSecretId(ctx, email)
 	if err != nil {
 		return "", err
 	}
@@ -233,7 +256,7 @@ func GenerateSecretId(email string) (string, error) {
 		return "", err
 	}
 
-	err = rdb.Set(context.Background(), redisJwtPrefix+email, b, 86400*time.Second).Err()
+	err = s.client.Set(ctx, redisJwtPrefix+email, b, 86400*time.Second).Err()
 	if err != nil {
 		return "", err
 	}
@@ -241,13 +264,11 @@ func GenerateSecretId(email string) (string, error) {
 	return secretId.String(), nil
 }
 
-func VerifySecretId(email, secretId string) (bool, error) {
-	ctx := context.Background()
-
+func (s *redisStore) VerifySecretId(ctx context.Context, email, secretId string) (bool, error) {
 	//verify secretId
-	obj, err := rdb.Get(ctx, redisJwtPrefix+email).Result()
+	obj, err := s.client.Get(ctx, redisJwtPrefix+email).Result()
 	if err != nil || err == redis.Nil {
-		return false, errors.New("secretid is not valid")
+		return false, errInvalidSecretId
 	}
 
 	var kds JWTModel
@@ -258,12 +279,12 @@ func VerifySecretId(email, secretId string) (bool, error) {
 	return kds.SecretId == secretId, nil
 }
 
-func HasSecretId(email string) (string, bool, error) {
-	ctx := context.Background()
-
-	val, err := rdb.Get(ctx, redisJwtPrefix+email).Result()
+func (s *redisStore) HasSecretId(ctx context.Context, email string) (string, bool, error) {
+	val, err := s.client.Get(ctx, redisJwtPrefix+email).Result()
 	if err != nil || err == redis.Nil {
-		log.Print(err)
+		if err != redis.Nil {
+			logging.FromContext(ctx).Error("error while getting the secret id", zap.Error(err))
+		}
 		return "", false, nil
 	}
 
@@ -275,13 +296,13 @@ func HasSecretId(email string) (string, bool, error) {
 	return jwtModel.SecretId, true, nil
 }
 
-func InvalidateSecretId(email string) error {
-	err := rdb.Del(context.Background(), redisJwtPrefix+email).Err()
+func (s *redisStore) InvalidateSecretId(ctx context.Context, email string) error {
+	err := s.client.Del(ctx, redisJwtPrefix+email).Err()
 	return err
 }
 
-func GetFailedLoginAttempts(ctx context.Context, email string) (int, error) {
-	failedAttempts, err := rdb.Get(ctx, failedLoginAttemptsPrefix+email).Int()
+func (s *redisStore) GetFailedLoginAttempts(ctx context.Context, email string) (int, error) {
+	failedAttempts, err := s.client.Get(ctx, failedLoginAttemptsPrefix+email).Int()
 	if err == redis.Nil {
 		return 0, nil
 	}
@@ -292,8 +313,8 @@ func GetFailedLoginAttempts(ctx context.Context, email string) (int, error) {
 	return failedAttempts, nil
 }
 
-func HasFailedLoginAttempts(ctx context.Context, email string) (bool, error) {
-	exists, err := rdb.Exists(ctx, failedLoginAttemptsPrefix+email).Result()
+func (s *redisStore) HasFailedLoginAttempts(ctx context.Context, email string) (bool, error) {
+	exists, err := s.client.Exists(ctx, failedLoginAttemptsPrefix+email).Result()
 	if err != nil {
 		return false, err
 	}
@@ -301,56 +322,12 @@ func HasFailedLoginAttempts(ctx context.Context, email string) (bool, error) {
 	return exists == 1, nil
 }
 
-func UpdateFailedLoginAttempts(ctx context.Context, email string) error {
-	has, err := HasFailedLoginAttempts(ctx, email)
-	if err != nil {
-		return err
-	}
-
-	if has {
-		err = rdb.Incr(ctx, failedLoginAttemptsPrefix+email).Err()
-		if err != nil {
-			return err
-		}
-
-		//reset ttl
-		err = rdb.Expire(ctx, failedLoginAttemptsPrefix, 600*time.Second).Err()
-		if err != nil {
-			return err
-		}
-	} else {
-		err := rdb.Set(ctx, failedLoginAttemptsPrefix+email, 1, 600*time.Second).Err()
-		if err != nil {
-			return err
-		}
-	}
-	return err
-}
-
-func ClearFailedLoginAttempts(ctx context.Context, email string) error {
-	err := rdb.Del(ctx, failedLoginAttemptsPrefix+email).Err()
+func (s *redisStore) UpdateFailedLoginAttempts(ctx context.Context, email string) error {
+	_, err := s.incrFailures(ctx, failedLoginAttemptsPrefix+email)
 	return err
 }
 
-func ActivateResetPassword(ctx context.Context, email string) error {
-	err := rdb.Set(ctx, changePasswordPrefix+email, true, 60*time.Minute).Err()
-	return err
-}
-
-func CanResetPassword(ctx context.Context, email string) (bool, error) {
-	r, err := rdb.Get(ctx, changePasswordPrefix+email).Bool()
-	if err != nil && err == redis.Nil {
-		return false, nil
-	}
-
-	if err != nil {
-		return false, err
-	}
-
-	return r, nil
-}
-
-func RemoveResetPassword(ctx context.Context, email string) error {
-	err := rdb.Del(ctx, changePasswordPrefix+email).Err()
+func (s *redisStore) ClearFailedLoginAttempts(ctx context.Context, email string) error {
+	err := s.client.Del(ctx, failedLoginAttemptsPrefix+email).Err()
 	return err
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
 )
 
 var seededRand *rand.Rand = rand.New(
@@ -17,34 +18,34 @@ const charset = "abcdefghijklmnopqrstuvwxyz" +
 	"ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
 func TestCacheJWTToken(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 	token := StringWithCharset(245)
 
-	err := CacheJWT(email, token)
+	err := CacheJWT(context.Background(), email, token)
 	if err != nil {
 		t.Errorf("Error while caching JWT token %s", err)
 	}
 
-	exists, err := EmailExists(email)
+	exists, err := EmailExists(context.Background(), email)
 
 	Nil(t, err)
 	True(t, exists)
 }
 
 func TestGetTokenByEmail(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 	token := StringWithCharset(245)
 
-	err := CacheJWT(email, token)
+	err := CacheJWT(context.Background(), email, token)
 	if err != nil {
 		t.Errorf("Error while caching JWT token %s", err)
 	}
 
-	val, err := GetJWTByEmail(email)
+	val, err := GetJWTByEmail(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while getting Token by Email %s", err)
 	}
@@ -54,17 +55,17 @@ func TestGetTokenByEmail(t *testing.T) {
 }
 
 func TestDoesTokenExpireAfter1Day(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 	token := StringWithCharset(245)
 
-	err := CacheJWT(email, token)
+	err := CacheJWT(context.Background(), email, token)
 	if err != nil {
 		t.Errorf("Error while caching JWT token %s", err)
 	}
 
-	ttl, err := GetTTLByEmail(email)
+	ttl, err := GetTTLByEmail(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error getting the ttl from the key by email %s", err)
 	}
@@ -77,17 +78,17 @@ func TestDoesTokenExpireAfter1Day(t *testing.T) {
 }
 
 func TestGetEmailByJWT(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 	token := StringWithCharset(245)
 
-	err := CacheJWT(email, token)
+	err := CacheJWT(context.Background(), email, token)
 	if err != nil {
 		t.Errorf("Error while caching JWT Token %s", err)
 	}
 
-	val, err := GetEmailByJWT(token)
+	val, err := GetEmailByJWT(context.Background(), token)
 	if err != nil {
 		t.Errorf("Error while getting Email by Token: %s", err)
 	}
@@ -96,28 +97,28 @@ func TestGetEmailByJWT(t *testing.T) {
 }
 
 func TestDeleteToken(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	t.Run("TestDeleteToken", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 		token := StringWithCharset(245)
 
-		err := CacheJWT(email, token)
+		err := CacheJWT(context.Background(), email, token)
 		if err != nil {
 			t.Errorf("Error while caching JWT Token %s", err)
 		}
 
-		ok, err := DeleteTokenByEmail(email, token)
+		ok, err := DeleteTokenByEmail(context.Background(), email, token)
 		if err != nil || !ok {
 			t.Errorf("Error while deleting Token by Email: %s", err)
 		}
 
-		_, err = GetJWTByEmail(email)
+		_, err = GetJWTByEmail(context.Background(), email)
 		if err == nil {
 			t.Error("Token still cached")
 		}
 
-		_, err = GetEmailByJWT(token)
+		_, err = GetEmailByJWT(context.Background(), token)
 		if err == nil {
 			t.Error("Token is still cached")
 		}
@@ -130,17 +131,17 @@ func TestDeleteToken(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 		token := StringWithCharset(245)
 
-		ok, err := DeleteTokenByEmail(email, token)
+		ok, err := DeleteTokenByEmail(context.Background(), email, token)
 		if err == nil || ok {
 			t.Errorf("No Error thrown 4")
 		}
 
-		_, err = GetJWTByEmail(email)
+		_, err = GetJWTByEmail(context.Background(), email)
 		if err == nil {
 			t.Errorf("No Error thrown 3")
 		}
 
-		_, err = GetEmailByJWT(token)
+		_, err = GetEmailByJWT(context.Background(), token)
 		if err == nil {
 			t.Errorf("No Error thrown 2 ")
 		}
@@ -151,18 +152,18 @@ func TestDeleteToken(t *testing.T) {
 }
 
 func TestIsTokenValid(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	t.Run("TestIsTokenValid", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 		token := StringWithCharset(245)
 
-		err := CacheJWT(email, token)
+		err := CacheJWT(context.Background(), email, token)
 		if err != nil {
 			t.Errorf("Error while caching JWT Token %s", err)
 		}
 
-		valid, err := IsTokenValid(token)
+		valid, err := IsTokenValid(context.Background(), token)
 		if err != nil {
 			t.Errorf("Error while checking if token is valid %s", err)
 		}
@@ -174,24 +175,24 @@ func TestIsTokenValid(t *testing.T) {
 	t.Run("TestIsTokenValidWithInvalidToken", func(t *testing.T) {
 		token := StringWithCharset(245)
 
-		valid, _ := IsTokenValid(token)
+		valid, _ := IsTokenValid(context.Background(), token)
 
 		False(t, valid)
 	})
 }
 
 func TestGenerateSecretIdAndVerify(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	t.Run("TestGenerateSecretIdAndVerify", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 
-		secretId, err := GenerateSecretId(email)
+		secretId, err := GenerateSecretId(context.Background(), email)
 		if err != nil {
 			t.Errorf("Error while generating secret id: %s", err)
 		}
 
-		ok, err := VerifySecretId(email, secretId)
+		ok, err := VerifySecretId(context.Background(), email, secretId)
 		if err != nil {
 			t.Errorf("Error while verifying secert id: %s", err)
 		}
@@ -203,12 +204,12 @@ func TestGenerateSecretIdAndVerify(t *testing.T) {
 	t.Run("TestVerifySecretIdWithWrongIdWithExistingAccount", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 
-		_, err := GenerateSecretId(email)
+		_, err := GenerateSecretId(context.Background(), email)
 		if err != nil {
 			t.Errorf("Error while generating secret id: %s", err)
 		}
 
-		ok, err := VerifySecretId(email, "secretId")
+		ok, err := VerifySecretId(context.Background(), email, "secretId")
 		if err != nil {
 			t.Errorf("Error while verifying secert id: %s", err)
 		}
@@ -220,7 +221,7 @@ func TestGenerateSecretIdAndVerify(t *testing.T) {
 	t.Run("TestVerifySecretIdWithWrongIdWithoutAccount", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 
-		ok, err := VerifySecretId(email, "secretId")
+		ok, err := VerifySecretId(context.Background(), email, "secretId")
 		if err == nil {
 			t.Errorf("No error thrown even though the secretId is wrong and doesn't exist")
 		}
@@ -231,22 +232,22 @@ func TestGenerateSecretIdAndVerify(t *testing.T) {
 }
 
 func TestHasSecretId(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	t.Run("TestHasSecretId", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 
-		secretId, err := GenerateSecretId(email)
+		secretId, err := GenerateSecretId(context.Background(), email)
 		if err != nil {
 			t.Errorf("Error while generating secret id: %s", err)
 		}
 
-		ok, err := VerifySecretId(email, secretId)
+		ok, err := VerifySecretId(context.Background(), email, secretId)
 		if err != nil {
 			t.Errorf("Error while verifying secert id: %s", err)
 		}
 
-		key, has, err := HasSecretId(email)
+		key, has, err := HasSecretId(context.Background(), email)
 		if err != nil {
 			t.Errorf("Error while checking if the user still has a secretId: %s", err)
 		}
@@ -267,7 +268,7 @@ func TestHasSecretId(t *testing.T) {
 	t.Run("TestHasSecretIdWhenTheUserDoesntHaveOne", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 
-		_, has, _ := HasSecretId(email)
+		_, has, _ := HasSecretId(context.Background(), email)
 
 		if has {
 			t.Errorf("User doesnt have a SecretId but it says it has")
@@ -278,21 +279,21 @@ func TestHasSecretId(t *testing.T) {
 }
 
 func TestInvalidateSecretId(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 
-	secretId, err := GenerateSecretId(email)
+	secretId, err := GenerateSecretId(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while generating secret id: %s", err)
 	}
 
-	ok, err := VerifySecretId(email, secretId)
+	ok, err := VerifySecretId(context.Background(), email, secretId)
 	if err != nil {
 		t.Errorf("Error while verifying secert id: %s", err)
 	}
 
-	key, has, err := HasSecretId(email)
+	key, has, err := HasSecretId(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while checking if the user still has a secretId: %s", err)
 	}
@@ -305,12 +306,12 @@ func TestInvalidateSecretId(t *testing.T) {
 		t.Errorf("SecretId is not the same as the previously generated one")
 	}
 
-	err = InvalidateSecretId(email)
+	err = InvalidateSecretId(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while invalidating secretId: %s", err)
 	}
 
-	_, has2, err := HasSecretId(email)
+	_, has2, err := HasSecretId(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while checking if the user still has a secretId 2: %s", err)
 	}
@@ -325,33 +326,33 @@ func TestInvalidateSecretId(t *testing.T) {
 }
 
 func TestInvalidateJWTTokens(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	t.Run("Invalidate specific JWT Token", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 		token := StringWithCharset(245)
 
-		err := CacheJWT(email, token)
+		err := CacheJWT(context.Background(), email, token)
 		if err != nil {
 			t.Errorf("Error while caching JWT token %s", err)
 		}
 
-		exists, err := EmailExists(email)
+		exists, err := EmailExists(context.Background(), email)
 		if err != nil {
 			t.Errorf("Error while checking if the email exists: %s", err)
 		}
 
-		ok, err := DoesTokenBelongToEmail(email, token)
+		ok, err := DoesTokenBelongToEmail(context.Background(), email, token)
 		if err != nil {
 			t.Errorf("Error while checking if the token belongs to the email: %s", err)
 		}
 
-		err = InvalidateSpecificJWTToken(email, token)
+		err = InvalidateSpecificJWTToken(context.Background(), email, token)
 		if err != nil {
 			t.Errorf("Error while invalidating specific jwt token: %s", err)
 		}
 
-		ok2, _ := DoesTokenBelongToEmail(email, token)
+		ok2, _ := DoesTokenBelongToEmail(context.Background(), email, token)
 
 		True(t, exists)
 		True(t, ok)
@@ -359,23 +360,55 @@ func TestInvalidateJWTTokens(t *testing.T) {
 	})
 }
 
+func TestInvalidateAllJWTTokens(t *testing.T) {
+	Setup(config.MustLoad(nil))
+
+	email := StringWithCharset(100) + "@gmail.com"
+	token := StringWithCharset(245)
+
+	err := CacheJWT(context.Background(), email, token)
+	if err != nil {
+		t.Errorf("Error while caching JWT token %s", err)
+	}
+
+	_, err = GenerateSecretId(context.Background(), email)
+	if err != nil {
+		t.Errorf("Error while generating secret id: %s", err)
+	}
+
+	err = InvalidateAllJWTTokens(context.Background(), email)
+	if err != nil {
+		t.Errorf("Error while invalidating all jwt tokens: %s", err)
+	}
+
+	valid, err := IsTokenValid(context.Background(), token)
+	if err != nil {
+		t.Errorf("Error while checking if token is valid %s", err)
+	}
+
+	_, has, _ := HasSecretId(context.Background(), email)
+
+	False(t, valid)
+	False(t, has)
+}
+
 func TestDoesTokenBelongToEmail(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 	token := StringWithCharset(245)
 
-	err := CacheJWT(email, token)
+	err := CacheJWT(context.Background(), email, token)
 	if err != nil {
 		t.Errorf("Error while caching JWT token %s", err)
 	}
 
-	exists, err := EmailExists(email)
+	exists, err := EmailExists(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while checking if the email exists: %s", err)
 	}
 
-	ok, err := DoesTokenBelongToEmail(email, token)
+	ok, err := DoesTokenBelongToEmail(context.Background(), email, token)
 	if err != nil {
 		t.Errorf("Error while checking if the token belongs to the email: %s", err)
 	}
@@ -385,7 +418,7 @@ func TestDoesTokenBelongToEmail(t *testing.T) {
 }
 
 func TestGetFailedLoginAttemts(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	t.Run("Get Failed Login Attempts", func(t *testing.T) {
 		email := StringWithCharset(500)
@@ -427,7 +460,7 @@ func TestGetFailedLoginAttemts(t *testing.T) {
 }
 
 func TestHasFailedLoginAttempts(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	t.Run("Has Failed Login Attempts", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
@@ -460,7 +493,7 @@ func TestHasFailedLoginAttempts(t *testing.T) {
 }
 
 func TestClearFailedLoginAttempts(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 	ctx := context.Background()
@@ -489,85 +522,6 @@ func TestClearFailedLoginAttempts(t *testing.T) {
 	Equal(t, 0, attempts)
 }
 
-func TestActivateResetPassword(t *testing.T) {
-	Setup(false)
-
-	email := StringWithCharset(50000)
-
-	ctx := context.Background()
-	err := ActivateResetPassword(ctx, email)
-	if err != nil {
-		t.Errorf("Error while activating reset password: %s", err)
-	}
-
-	can, err := CanResetPassword(ctx, email)
-	if err != nil {
-		t.Errorf("Error while checking if the password can be reset: %s", err)
-	}
-
-	Nil(t, err)
-	True(t, can)
-}
-
-func TestCanResetPassword(t *testing.T) {
-	Setup(false)
-
-	t.Run("Can Reset Password", func(t *testing.T) {
-		email := StringWithCharset(50000)
-
-		ctx := context.Background()
-		err := ActivateResetPassword(ctx, email)
-		if err != nil {
-			t.Errorf("Error while activating reset password: %s", err)
-		}
-
-		can, err := CanResetPassword(ctx, email)
-		if err != nil {
-			t.Errorf("Error while checking if the password can be reset: %s", err)
-		}
-
-		Nil(t, err)
-		True(t, can)
-	})
-
-	t.Run("Can Reset Password when the entry doesn't exist", func(t *testing.T) {
-
-		ctx := context.Background()
-		can, err := CanResetPassword(ctx, "kdhfgbhjdfg")
-		if err != nil {
-			t.Errorf("Error while checking if the password can be reset: %s", err)
-		}
-
-		Nil(t, err)
-		False(t, can)
-	})
-}
-
-func TestRemoveResetPassword(t *testing.T) {
-	Setup(false)
-
-	email := StringWithCharset(50000)
-
-	ctx := context.Background()
-	err := ActivateResetPassword(ctx, email)
-	if err != nil {
-		t.Errorf("Error while activating reset password: %s", err)
-	}
-
-	err = RemoveResetPassword(ctx, email)
-	if err != nil {
-		t.Errorf("Error while removing reset password: %s", err)
-	}
-
-	can, err := CanResetPassword(ctx, email)
-	if err != nil {
-		t.Errorf("Error while checking if the password can be reset: %s", err)
-	}
-
-	Nil(t, err)
-	False(t, can)
-}
-
 func StringWithCharset(length int) string {
 	b := make([]byte, length)
 	for i := range b {
@@ -1,48 +1,63 @@
 package cache
 
 import (
-	"os"
+	"context"
 
 	"github.com/go-redis/redis/v8"
-	"github.com/joho/godotenv"
-	util "github.com/urento/shoppinglist/pkg"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/metrics"
+	"github.com/urento/shoppinglist/pkg/tracing"
 )
 
-var rdb *redis.Client
+var store Store = NewMemoryStore()
+
+type redisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore keeps the cache in redis, which is needed as soon as more than one instance is running
+func NewRedisStore(client *redis.Client) Store {
+	return &redisStore{client: client}
+}
+
+func (s *redisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
+
+// UseStore replaces the store behind the package functions, e.g. with NewMemoryStore in tests
+func UseStore(s Store) {
+	store = s
+}
 
 //TODO: Add Cache for Shoppinglists
 
-func Setup(auth_test bool) {
-	var err error
-	if util.PROD {
-		err = godotenv.Load()
-	} else if auth_test && !util.GITHUB_TESTING {
-		err = godotenv.Load("../.env")
-	} else if util.GITHUB_TESTING {
-		err = nil
-	} else if util.LOCAL_TESTING && !auth_test {
-		err = godotenv.Load("../../.env")
-	} else {
-		err = godotenv.Load()
-	}
+// Setup uses redis unless the memory driver is configured, then everything is kept in the process
+func Setup(cfg *config.Config) {
+	setupLockout(cfg.Lockout)
+	setupTOTP(cfg.TwoFactor)
 
-	if err != nil {
-		panic(err)
+	if cfg.Redis.Driver == "memory" {
+		UseStore(NewMemoryStore())
+		return
 	}
 
-	redisPassword := os.Getenv("REDIS_PASSWORD")
-	redisAddr := os.Getenv("REDIS_ADDR")
-
+	redisPassword := cfg.Redis.Password
 	if redisPassword == "testing" {
-		rdb = redis.NewClient(&redis.Options{
-			Addr: redisAddr,
-			DB:   0,
-		})
-	} else {
-		rdb = redis.NewClient(&redis.Options{
-			Addr:     redisAddr,
-			Password: redisPassword,
-			DB:       0,
-		})
+		redisPassword = ""
 	}
+
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: redisPassword,
+		DB:       cfg.Redis.DB,
+	})
+
+	rdb.AddHook(metrics.RedisHook{})
+	rdb.AddHook(tracing.RedisHook{})
+
+	UseStore(NewRedisStore(rdb))
 }
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// moves KEYS[1..2*ARGV[1]] pairwise from the old to the new key and deletes the remaining KEYS in one step
+var changeEmailKeys = redis.NewScript(`
+local renames = tonumber(ARGV[1])
+for i = 1, renames do
+	local from, to = KEYS[2 * i - 1], KEYS[2 * i]
+	if redis.call("EXISTS", from) == 1 then
+		redis.call("RENAME", from, to)
+	else
+		redis.call("DEL", to)
+	end
+end
+for i = 2 * renames + 1, #KEYS do
+	redis.call("DEL", KEYS[i])
+end
+return 1
+`)
+
+// ChangeEmail moves the keys that have to survive an email change (totp secret and step, failed logins and lockout)
+// to the new email and drops everything else, including all sessions of the old email
+func (s *redisStore) ChangeEmail(ctx context.Context, oldEmail, newEmail string) error {
+	token, err := s.client.Get(ctx, tokenPrefix+oldEmail).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	keys := []string{}
+	for _, prefix := range []string{totpPrefix, totpStepPrefix, failedLoginAttemptsPrefix, lockoutPrefix, backupCodeAttemptsPrefix} {
+		keys = append(keys, prefix+oldEmail, prefix+newEmail)
+	}
+	renames := len(keys) / 2
+
+	for _, prefix := range []string{redisJwtPrefix, tokenPrefix, userPrefix, totpPendingPrefix, mfaPendingPrefix, emailOTPPrefix, emailOTPAttemptsPrefix} {
+		keys = append(keys, prefix+oldEmail)
+	}
+
+	for _, ceremony := range webAuthnCeremonies {
+		keys = append(keys, webAuthnSessionPrefix+ceremony+":"+oldEmail)
+	}
+
+	keys = append(keys, userPrefix+newEmail)
+
+	if len(token) > 0 {
+		keys = append(keys, emailPrefix+token)
+	}
+
+	return changeEmailKeys.Run(ctx, s.client, keys, renames).Err()
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+func TestChangeEmail(t *testing.T) {
+	Setup(config.MustLoad(nil))
+
+	oldEmail := StringWithCharset(50) + "@gmail.com"
+	newEmail := StringWithCharset(50) + "@gmail.com"
+	token := StringWithCharset(60)
+	secret := StringWithCharset(32)
+
+	err := CacheJWT(context.Background(), oldEmail, token)
+	if err != nil {
+		t.Errorf("Error while caching jwt: %s", err)
+	}
+
+	err = CacheTOTPSecret(context.Background(), oldEmail, secret)
+	if err != nil {
+		t.Errorf("Error while caching totp secret: %s", err)
+	}
+
+	_, err = MarkTOTPStepUsed(context.Background(), oldEmail, 100, time.Minute)
+	if err != nil {
+		t.Errorf("Error while marking totp step: %s", err)
+	}
+
+	err = ChangeEmail(context.Background(), oldEmail, newEmail)
+	if err != nil {
+		t.Errorf("Error while changing email: %s", err)
+	}
+
+	totpSecret, err := GetTOTPSecret(context.Background(), newEmail)
+
+	Nil(t, err)
+	Equal(t, secret, totpSecret)
+
+	_, err = GetTOTPSecret(context.Background(), oldEmail)
+	NotNil(t, err)
+
+	ok, err := MarkTOTPStepUsed(context.Background(), newEmail, 100, time.Minute)
+	Nil(t, err)
+	False(t, ok)
+
+	valid, err := IsTokenValid(context.Background(), token)
+	Nil(t, err)
+	False(t, valid)
+
+	exists, err := EmailExists(context.Background(), oldEmail)
+	Nil(t, err)
+	False(t, exists)
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// CacheEmailOTP stores the hash of the code; the failed attempts are counted per user and not reset by a new code
+func (s *redisStore) CacheEmailOTP(ctx context.Context, email, hash string, ttl time.Duration) error {
+	return s.client.Set(ctx, emailOTPPrefix+email, hash, ttl).Err()
+}
+
+func (s *redisStore) GetEmailOTP(ctx context.Context, email string) (string, error) {
+	val, err := s.client.Get(ctx, emailOTPPrefix+email).Result()
+	if err != nil {
+		return "", errNoEmailOTP
+	}
+	return val, nil
+}
+
+func (s *redisStore) DeleteEmailOTP(ctx context.Context, email string) error {
+	err := s.client.Del(ctx, emailOTPPrefix+email).Err()
+	return err
+}
+
+// IncrEmailOTPAttempts counts a verification attempt and returns the number of attempts in the current window
+func (s *redisStore) IncrEmailOTPAttempts(ctx context.Context, email string, window time.Duration) (int64, error) {
+	return incrRateLimit.Run(ctx, s.client, []string{emailOTPAttemptsPrefix + email}, window.Milliseconds()).Int64()
+}
+
+func (s *redisStore) ClearEmailOTPAttempts(ctx context.Context, email string) error {
+	err := s.client.Del(ctx, emailOTPAttemptsPrefix+email).Err()
+	return err
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+var (
+	ipLoginAttemptsPrefix = "login_attempts_ip:"
+	lockoutPrefix         = "login_lock:"
+	ipLockoutPrefix       = "login_lock_ip:"
+	unlockTokenPrefix     = "unlock_token:"
+
+	backupCodeAttemptsPrefix = "backup_code_attempts:"
+)
+
+// every failure keeps the failures for another failure window; counting and extending happen in one step, so a key
+// can't be left without expiry
+var incrFailedLogins = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[1])
+return count
+`)
+
+type LockoutConfig struct {
+	AccountThreshold int
+	IPThreshold      int
+	BaseWindow       time.Duration
+	MaxWindow        time.Duration
+	FailureWindow    time.Duration
+}
+
+var lockout = LockoutConfig{
+	AccountThreshold: 5,
+	IPThreshold:      20,
+	BaseWindow:       time.Minute,
+	MaxWindow:        24 * time.Hour,
+	FailureWindow:    24 * time.Hour,
+}
+
+func setupLockout(cfg config.Lockout) {
+	lockout = LockoutConfig{
+		AccountThreshold: cfg.AccountThreshold,
+		IPThreshold:      cfg.IPThreshold,
+		BaseWindow:       cfg.BaseWindow(),
+		MaxWindow:        cfg.MaxWindow(),
+		FailureWindow:    cfg.FailureWindow(),
+	}
+}
+
+// RegisterFailedLogin counts the failure for the account and the ip and locks them once the threshold is reached.
+// Every further failure doubles the lockout window.
+func (s *redisStore) RegisterFailedLogin(ctx context.Context, email, ip string) error {
+	if len(email) > 0 {
+		failures, err := s.incrFailures(ctx, failedLoginAttemptsPrefix+email)
+		if err != nil {
+			return err
+		}
+
+		err = s.lock(ctx, lockoutPrefix+email, int(failures), lockout.AccountThreshold)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(ip) > 0 {
+		failures, err := s.incrFailures(ctx, ipLoginAttemptsPrefix+ip)
+		if err != nil {
+			return err
+		}
+
+		err = s.lock(ctx, ipLockoutPrefix+ip, int(failures), lockout.IPThreshold)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *redisStore) incrFailures(ctx context.Context, key string) (int64, error) {
+	return incrFailedLogins.Run(ctx, s.client, []string{key}, lockout.FailureWindow.Milliseconds()).Int64()
+}
+
+func (s *redisStore) lock(ctx context.Context, key string, failures, threshold int) error {
+	if failures < threshold {
+		return nil
+	}
+	return s.client.Set(ctx, key, failures, LockoutWindow(failures, threshold)).Err()
+}
+
+func LockoutWindow(failures, threshold int) time.Duration {
+	window := lockout.BaseWindow
+	for i := threshold; i < failures; i++ {
+		window *= 2
+		if window >= lockout.MaxWindow {
+			return lockout.MaxWindow
+		}
+	}
+	return window
+}
+
+// GetLockout returns how long the account or the ip is still locked
+func (s *redisStore) GetLockout(ctx context.Context, email, ip string) (time.Duration, error) {
+	var remaining time.Duration
+
+	for _, key := range []string{lockoutPrefix + email, ipLockoutPrefix + ip} {
+		ttl, err := s.client.TTL(ctx, key).Result()
+		if err != nil && err != redis.Nil {
+			return 0, err
+		}
+
+		if ttl > remaining {
+			remaining = ttl
+		}
+	}
+
+	return remaining, nil
+}
+
+// ClearLockout unlocks the account and the ip and resets their failed login attempts. ip can be empty
+func (s *redisStore) ClearLockout(ctx context.Context, email, ip string) error {
+	keys := []string{lockoutPrefix + email, failedLoginAttemptsPrefix + email}
+	if len(ip) > 0 {
+		keys = append(keys, ipLockoutPrefix+ip, ipLoginAttemptsPrefix+ip)
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
+
+// IncrBackupCodeAttempts counts a backup code attempt for the account and returns the attempts in the current window
+func (s *redisStore) IncrBackupCodeAttempts(ctx context.Context, email string, window time.Duration) (int64, error) {
+	return incrRateLimit.Run(ctx, s.client, []string{backupCodeAttemptsPrefix + email}, window.Milliseconds()).Int64()
+}
+
+// SaveUnlockToken remembers the hash of an unlock token that was sent, so it can only be used once
+func (s *redisStore) SaveUnlockToken(ctx context.Context, hash string, ttl time.Duration) error {
+	return s.client.Set(ctx, unlockTokenPrefix+hash, "1", ttl).Err()
+}
+
+// ConsumeUnlockToken reports if the token was sent and not used yet and marks it as used
+func (s *redisStore) ConsumeUnlockToken(ctx context.Context, hash string) (bool, error) {
+	deleted, err := s.client.Del(ctx, unlockTokenPrefix+hash).Result()
+	return deleted == 1, err
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+func TestRegisterFailedLogin(t *testing.T) {
+	Setup(config.MustLoad(nil))
+
+	ctx := context.Background()
+
+	t.Run("Lock Account after threshold", func(t *testing.T) {
+		email := StringWithCharset(100) + "@gmail.com"
+
+		for i := 0; i < lockout.AccountThreshold-1; i++ {
+			err := RegisterFailedLogin(ctx, email, "")
+			if err != nil {
+				t.Errorf("Error while registering failed login: %s", err)
+			}
+		}
+
+		before, err := GetLockout(ctx, email, "")
+		if err != nil {
+			t.Errorf("Error while getting lockout: %s", err)
+		}
+
+		err = RegisterFailedLogin(ctx, email, "")
+		if err != nil {
+			t.Errorf("Error while registering failed login: %s", err)
+		}
+
+		after, err := GetLockout(ctx, email, "")
+		if err != nil {
+			t.Errorf("Error while getting lockout: %s", err)
+		}
+
+		True(t, before <= 0)
+		True(t, after > 0)
+	})
+
+	t.Run("Lock IP after threshold", func(t *testing.T) {
+		ip := RandomIPAddress() + StringWithCharset(10)
+
+		for i := 0; i < lockout.IPThreshold; i++ {
+			err := RegisterFailedLogin(ctx, "", ip)
+			if err != nil {
+				t.Errorf("Error while registering failed login: %s", err)
+			}
+		}
+
+		remaining, err := GetLockout(ctx, StringWithCharset(100), ip)
+		if err != nil {
+			t.Errorf("Error while getting lockout: %s", err)
+		}
+
+		True(t, remaining > 0)
+	})
+
+	t.Run("Clear Lockout", func(t *testing.T) {
+		email := StringWithCharset(100) + "@gmail.com"
+
+		for i := 0; i < lockout.AccountThreshold; i++ {
+			err := RegisterFailedLogin(ctx, email, "")
+			if err != nil {
+				t.Errorf("Error while registering failed login: %s", err)
+			}
+		}
+
+		err := ClearLockout(ctx, email, "")
+		if err != nil {
+			t.Errorf("Error while clearing lockout: %s", err)
+		}
+
+		remaining, err := GetLockout(ctx, email, "")
+		if err != nil {
+			t.Errorf("Error while getting lockout: %s", err)
+		}
+
+		attempts, err := GetFailedLoginAttempts(ctx, email)
+		if err != nil {
+			t.Errorf("Error while getting failed login attempts: %s", err)
+		}
+
+		True(t, remaining <= 0)
+		Equal(t, 0, attempts)
+	})
+}
+
+func TestLockoutWindow(t *testing.T) {
+	Equal(t, lockout.BaseWindow, LockoutWindow(5, 5))
+	Equal(t, 2*lockout.BaseWindow, LockoutWindow(6, 5))
+	Equal(t, 8*lockout.BaseWindow, LockoutWindow(8, 5))
+	Equal(t, lockout.MaxWindow, LockoutWindow(500, 5))
+}
@@ -0,0 +1,752 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// sweepInterval is the number of writes after which expired entries are removed
+const sweepInterval = 1000
+
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// memoryStore keeps the cache in a map with the same keys and TTLs as redis. It's only shared within the process,
+// so it can't be used once more than one instance is running
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]entry
+	writes  int
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{entries: map[string]entry{}}
+}
+
+// the helpers below expect the caller to hold the lock
+
+func (s *memoryStore) get(key string) (string, bool) {
+	e, ok := s.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	if e.expired(time.Now()) {
+		delete(s.entries, key)
+		return "", false
+	}
+	return e.value, true
+}
+
+func (s *memoryStore) exists(key string) bool {
+	_, ok := s.get(key)
+	return ok
+}
+
+// set stores the value; a ttl of 0 keeps it until it's deleted
+func (s *memoryStore) set(key, value string, ttl time.Duration) {
+	e := entry{value: value}
+	if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl)
+	}
+	s.entries[key] = e
+
+	s.writes++
+	if s.writes%sweepInterval == 0 {
+		now := time.Now()
+		for key, e := range s.entries {
+			if e.expired(now) {
+				delete(s.entries, key)
+			}
+		}
+	}
+}
+
+func (s *memoryStore) del(keys ...string) int {
+	deleted := 0
+	for _, key := range keys {
+		if s.exists(key) {
+			deleted++
+		}
+		delete(s.entries, key)
+	}
+	return deleted
+}
+
+// ttl returns -2 for missing keys and -1 for keys without expiry like redis
+func (s *memoryStore) ttl(key string) time.Duration {
+	if !s.exists(key) {
+		return -2
+	}
+
+	e := s.entries[key]
+	if e.expiresAt.IsZero() {
+		return -1
+	}
+	return time.Until(e.expiresAt)
+}
+
+func (s *memoryStore) expire(key string, ttl time.Duration) {
+	if e, ok := s.entries[key]; ok && s.exists(key) {
+		e.expiresAt = time.Now().Add(ttl)
+		s.entries[key] = e
+	}
+}
+
+// incr keeps the expiry of an existing key; a new key doesn't expire
+func (s *memoryStore) incr(key string) (int64, error) {
+	value, ok := s.get(key)
+	if !ok {
+		s.set(key, "1", 0)
+		return 1, nil
+	}
+
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	e := s.entries[key]
+	e.value = strconv.FormatInt(i+1, 10)
+	s.entries[key] = e
+	return i + 1, nil
+}
+
+func (s *memoryStore) CacheJWT(ctx context.Context, email, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(tokenPrefix+email, token, 24*time.Hour)
+	s.set(emailPrefix+token, email, 24*time.Hour)
+	return nil
+}
+
+func (s *memoryStore) InvalidateSpecificJWTToken(ctx context.Context, email, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.del(emailPrefix+token, redisJwtPrefix+email, tokenPrefix+email)
+	return nil
+}
+
+func (s *memoryStore) InvalidateAllJWTTokens(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token, ok := s.get(tokenPrefix + email); ok {
+		s.del(emailPrefix + token)
+	}
+
+	s.del(tokenPrefix+email, redisJwtPrefix+email)
+	return nil
+}
+
+func (s *memoryStore) DoesTokenBelongToEmail(ctx context.Context, email, token string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	val, ok := s.get(tokenPrefix + email)
+	if !ok {
+		return false, redis.Nil
+	}
+	return val == token, nil
+}
+
+func (s *memoryStore) GetJWTByEmail(ctx context.Context, email string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	val, ok := s.get(tokenPrefix + email)
+	if !ok {
+		return "", errJWTNotCached
+	}
+	return val, nil
+}
+
+func (s *memoryStore) GetEmailByJWT(ctx context.Context, token string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	val, ok := s.get(emailPrefix + token)
+	if !ok {
+		return "", errJWTNotCached
+	}
+	return val, nil
+}
+
+func (s *memoryStore) EmailExists(ctx context.Context, email string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exists(tokenPrefix + email), nil
+}
+
+func (s *memoryStore) Check(ctx context.Context, email, token string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.get(tokenPrefix + email)
+	if !ok || t != token {
+		return false, nil
+	}
+
+	//update ttl
+	ttl := s.ttl(tokenPrefix+email) + 2*time.Hour
+	s.expire(tokenPrefix+email, ttl)
+	s.expire(redisJwtPrefix+email, ttl)
+	s.expire(emailPrefix+token, ttl)
+	return true, nil
+}
+
+func (s *memoryStore) IsTokenValid(ctx context.Context, token string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exists(emailPrefix + token), nil
+}
+
+func (s *memoryStore) DeleteTokenByEmail(ctx context.Context, email, token string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.exists(tokenPrefix + email) {
+		return false, errEmailNotCached
+	}
+
+	s.del(tokenPrefix+email, emailPrefix+token)
+	return true, nil
+}
+
+func (s *memoryStore) GetTTLByEmail(ctx context.Context, email string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ttl(tokenPrefix + email), nil
+}
+
+func (s *memoryStore) GenerateSecretId(ctx context.Context, email string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok, err := s.secretId(email); err != nil || ok {
+		return existing, err
+	}
+
+	secretId, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(JWTModel{Email: email, SecretId: secretId.String()})
+	if err != nil {
+		return "", err
+	}
+
+	s.set(redisJwtPrefix+email, string(b), 86400*time.Second)
+	return secretId.String(), nil
+}
+
+func (s *memoryStore) secretId(email string) (string, bool, error) {
+	val, ok := s.get(redisJwtPrefix + email)
+	if !ok {
+		return "", false, nil
+	}
+
+	var jwtModel JWTModel
+	if err := json.Unmarshal([]byte(val), &jwtModel); err != nil {
+		return "", false, err
+	}
+	return jwtModel.SecretId, true, nil
+}
+
+func (s *memoryStore) VerifySecretId(ctx context.Context, email, secretId string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok, err := s.secretId(email)
+	if err != nil {
+		return false, err
+	}
+
+	if !ok {
+		return false, errInvalidSecretId
+	}
+	return existing == secretId, nil
+}
+
+func (s *memoryStore) HasSecretId(ctx context.Context, email string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.secretId(email)
+}
+
+func (s *memoryStore) InvalidateSecretId(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.del(redisJwtPrefix + email)
+	return nil
+}
+
+func (s *memoryStore) CacheTOTPSecret(ctx context.Context, email, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(totpPrefix+email, secret, totpCacheTTL)
+	return nil
+}
+
+func (s *memoryStore) GetTOTPSecret(ctx context.Context, email string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	val, ok := s.get(totpPrefix + email)
+	if !ok {
+		return "not cached", errTOTPNotCached
+	}
+	return val, nil
+}
+
+func (s *memoryStore) DeleteTOTPSecret(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.del(totpPrefix + email)
+	return nil
+}
+
+func (s *memoryStore) IsTOTPSecretCached(ctx context.Context, email string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exists(totpPrefix + email), nil
+}
+
+func (s *memoryStore) CachePendingTOTPSecret(ctx context.Context, email, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(totpPendingPrefix+email, secret, totpEnrollmentTTL)
+	return nil
+}
+
+func (s *memoryStore) GetPendingTOTPSecret(ctx context.Context, email string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	val, ok := s.get(totpPendingPrefix + email)
+	if !ok {
+		return "", errNoPendingTOTP
+	}
+	return val, nil
+}
+
+func (s *memoryStore) DeletePendingTOTPSecret(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.del(totpPendingPrefix + email)
+	return nil
+}
+
+func (s *memoryStore) MarkTOTPStepUsed(ctx context.Context, email string, step int64, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.get(totpStepPrefix + email); ok {
+		lastStep, err := strconv.ParseInt(last, 10, 64)
+		if err != nil {
+			return false, err
+		}
+
+		if lastStep >= step {
+			return false, nil
+		}
+	}
+
+	s.set(totpStepPrefix+email, strconv.FormatInt(step, 10), ttl)
+	return true, nil
+}
+
+func (s *memoryStore) DeleteTOTPStep(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.del(totpStepPrefix + email)
+	return nil
+}
+
+func (s *memoryStore) GetAllTOTPSecrets(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets := map[string]string{}
+	for key := range s.entries {
+		if !strings.HasPrefix(key, totpPrefix) {
+			continue
+		}
+
+		if secret, ok := s.get(key); ok {
+			secrets[strings.TrimPrefix(key, totpPrefix)] = secret
+		}
+	}
+	return secrets, nil
+}
+
+func (s *memoryStore) CacheUser(ctx context.Context, user User) error {
+	b, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(userPrefix+user.EMail, string(b), 0)
+	return nil
+}
+
+func (s *memoryStore) GetUser(ctx context.Context, email string) (*User, error) {
+	s.mu.Lock()
+	val, ok := s.get(userPrefix + email)
+	s.mu.Unlock()
+	if !ok {
+		return nil, redis.Nil
+	}
+
+	var user User
+	if err := json.Unmarshal([]byte(val), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *memoryStore) GetTwoFactorAuthenticationStatus(ctx context.Context, email string) (bool, error) {
+	user, err := s.GetUser(ctx, email)
+	if err != nil {
+		return false, err
+	}
+	return user.TwoFactorAuthentication, nil
+}
+
+func (s *memoryStore) UpdateUser(ctx context.Context, user User) error {
+	return s.CacheUser(ctx, user)
+}
+
+func (s *memoryStore) DeleteUser(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.del(userPrefix + email)
+	return nil
+}
+
+func (s *memoryStore) IsUserCached(ctx context.Context, email string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exists(userPrefix + email), nil
+}
+
+func (s *memoryStore) failedLoginAttempts(email string) (int, error) {
+	val, ok := s.get(failedLoginAttemptsPrefix + email)
+	if !ok {
+		return 0, nil
+	}
+	return strconv.Atoi(val)
+}
+
+func (s *memoryStore) GetFailedLoginAttempts(ctx context.Context, email string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.failedLoginAttempts(email)
+}
+
+func (s *memoryStore) HasFailedLoginAttempts(ctx context.Context, email string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exists(failedLoginAttemptsPrefix + email), nil
+}
+
+// incrFailures counts the failure and resets the window of the key
+func (s *memoryStore) incrFailures(key string) (int64, error) {
+	failures, err := s.incr(key)
+	if err != nil {
+		return 0, err
+	}
+
+	s.expire(key, lockout.FailureWindow)
+	return failures, nil
+}
+
+func (s *memoryStore) UpdateFailedLoginAttempts(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.incrFailures(failedLoginAttemptsPrefix + email)
+	return err
+}
+
+func (s *memoryStore) ClearFailedLoginAttempts(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.del(failedLoginAttemptsPrefix + email)
+	return nil
+}
+
+func (s *memoryStore) RegisterFailedLogin(ctx context.Context, email, ip string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(email) > 0 {
+		failures, err := s.incrFailures(failedLoginAttemptsPrefix + email)
+		if err != nil {
+			return err
+		}
+		s.lock(lockoutPrefix+email, int(failures), lockout.AccountThreshold)
+	}
+
+	if len(ip) > 0 {
+		failures, err := s.incrFailures(ipLoginAttemptsPrefix + ip)
+		if err != nil {
+			return err
+		}
+		s.lock(ipLockoutPrefix+ip, int(failures), lockout.IPThreshold)
+	}
+
+	return nil
+}
+
+func (s *memoryStore) lock(key string, failures, threshold int) {
+	if failures >= threshold {
+		s.set(key, strconv.Itoa(failures), LockoutWindow(failures, threshold))
+	}
+}
+
+func (s *memoryStore) GetLockout(ctx context.Context, email, ip string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var remaining time.Duration
+	for _, key := range []string{lockoutPrefix + email, ipLockoutPrefix + ip} {
+		if ttl := s.ttl(key); ttl > remaining {
+			remaining = ttl
+		}
+	}
+	return remaining, nil
+}
+
+func (s *memoryStore) ClearLockout(ctx context.Context, email, ip string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.del(lockoutPrefix+email, failedLoginAttemptsPrefix+email)
+	if len(ip) > 0 {
+		s.del(ipLockoutPrefix+ip, ipLoginAttemptsPrefix+ip)
+	}
+	return nil
+}
+
+func (s *memoryStore) IncrBackupCodeAttempts(ctx context.Context, email string, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts, err := s.incr(backupCodeAttemptsPrefix + email)
+	if err != nil {
+		return 0, err
+	}
+
+	if attempts == 1 {
+		s.expire(backupCodeAttemptsPrefix+email, window)
+	}
+	return attempts, nil
+}
+
+func (s *memoryStore) SaveUnlockToken(ctx context.Context, hash string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(unlockTokenPrefix+hash, "1", ttl)
+	return nil
+}
+
+func (s *memoryStore) ConsumeUnlockToken(ctx context.Context, hash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.del(unlockTokenPrefix+hash) == 1, nil
+}
+
+func (s *memoryStore) CacheEmailOTP(ctx context.Context, email, hash string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(emailOTPPrefix+email, hash, ttl)
+	return nil
+}
+
+func (s *memoryStore) GetEmailOTP(ctx context.Context, email string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	val, ok := s.get(emailOTPPrefix + email)
+	if !ok {
+		return "", errNoEmailOTP
+	}
+	return val, nil
+}
+
+func (s *memoryStore) DeleteEmailOTP(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.del(emailOTPPrefix + email)
+	return nil
+}
+
+func (s *memoryStore) IncrEmailOTPAttempts(ctx context.Context, email string, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts, err := s.incr(emailOTPAttemptsPrefix + email)
+	if err != nil {
+		return 0, err
+	}
+
+	if attempts == 1 {
+		s.expire(emailOTPAttemptsPrefix+email, window)
+	}
+	return attempts, nil
+}
+
+func (s *memoryStore) ClearEmailOTPAttempts(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.del(emailOTPAttemptsPrefix + email)
+	return nil
+}
+
+func (s *memoryStore) CacheWebAuthnSession(ctx context.Context, email, ceremony string, session []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(webAuthnSessionPrefix+ceremony+":"+email, string(session), webAuthnSessionTTL)
+	return nil
+}
+
+func (s *memoryStore) GetWebAuthnSession(ctx context.Context, email, ceremony string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := webAuthnSessionPrefix + ceremony + ":" + email
+	val, ok := s.get(key)
+	if !ok {
+		return nil, errNoWebAuthnSession
+	}
+
+	s.del(key)
+	return []byte(val), nil
+}
+
+func (s *memoryStore) SetMFAPending(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(mfaPendingPrefix+email, "1", mfaPendingTTL)
+	return nil
+}
+
+func (s *memoryStore) IsMFAPending(ctx context.Context, email string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exists(mfaPendingPrefix + email), nil
+}
+
+func (s *memoryStore) ConsumeMFAPending(ctx context.Context, email string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.del(mfaPendingPrefix+email) == 1, nil
+}
+
+func (s *memoryStore) IncrRateLimit(ctx context.Context, ip string, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count, err := s.incr(rateLimitPrefix + ip)
+	if err != nil {
+		return 0, err
+	}
+
+	if count == 1 {
+		s.expire(rateLimitPrefix+ip, window)
+	}
+	return count, nil
+}
+
+func (s *memoryStore) ResetRateLimit(ctx context.Context, ip string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.del(rateLimitPrefix + ip)
+	return nil
+}
+
+func (s *memoryStore) ChangeEmail(ctx context.Context, oldEmail, newEmail string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, hasToken := s.get(tokenPrefix + oldEmail)
+
+	for _, prefix := range []string{totpPrefix, totpStepPrefix, failedLoginAttemptsPrefix, lockoutPrefix, backupCodeAttemptsPrefix} {
+		from, to := prefix+oldEmail, prefix+newEmail
+		if s.exists(from) {
+			s.entries[to] = s.entries[from]
+			delete(s.entries, from)
+		} else {
+			s.del(to)
+		}
+	}
+
+	for _, prefix := range []string{redisJwtPrefix, tokenPrefix, userPrefix, totpPendingPrefix, mfaPendingPrefix, emailOTPPrefix, emailOTPAttemptsPrefix} {
+		s.del(prefix + oldEmail)
+	}
+
+	for _, ceremony := range webAuthnCeremonies {
+		s.del(webAuthnSessionPrefix + ceremony + ":" + oldEmail)
+	}
+
+	s.del(userPrefix + newEmail)
+
+	if hasToken {
+		s.del(emailPrefix + token)
+	}
+	return nil
+}
+
+func (s *memoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
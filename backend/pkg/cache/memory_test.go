@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Expiry", func(t *testing.T) {
+		s := NewMemoryStore().(*memoryStore)
+		s.set("key", "value", 10*time.Millisecond)
+
+		True(t, s.exists("key"))
+		True(t, s.ttl("key") > 0)
+
+		time.Sleep(20 * time.Millisecond)
+
+		False(t, s.exists("key"))
+		Equal(t, time.Duration(-2), s.ttl("key"))
+	})
+
+	t.Run("JWT", func(t *testing.T) {
+		s := NewMemoryStore()
+		email := StringWithCharset(20) + "@gmail.com"
+		token := StringWithCharset(60)
+
+		err := s.CacheJWT(ctx, email, token)
+		if err != nil {
+			t.Errorf("Error while caching jwt: %s", err)
+		}
+
+		cachedEmail, err := s.GetEmailByJWT(ctx, token)
+		Nil(t, err)
+		Equal(t, email, cachedEmail)
+
+		ok, err := s.Check(ctx, email, token)
+		Nil(t, err)
+		True(t, ok)
+
+		err = s.InvalidateAllJWTTokens(ctx, email)
+		if err != nil {
+			t.Errorf("Error while invalidating jwt tokens: %s", err)
+		}
+
+		valid, err := s.IsTokenValid(ctx, token)
+		Nil(t, err)
+		False(t, valid)
+	})
+
+	t.Run("SecretId", func(t *testing.T) {
+		s := NewMemoryStore()
+		email := StringWithCharset(20) + "@gmail.com"
+
+		secretId, err := s.GenerateSecretId(ctx, email)
+		if err != nil {
+			t.Errorf("Error while generating secret id: %s", err)
+		}
+
+		again, _ := s.GenerateSecretId(ctx, email)
+		ok, err := s.VerifySecretId(ctx, email, secretId)
+
+		Nil(t, err)
+		True(t, ok)
+		Equal(t, secretId, again)
+
+		_, err = s.VerifySecretId(ctx, StringWithCharset(20), secretId)
+		NotNil(t, err)
+	})
+
+	t.Run("Lockout", func(t *testing.T) {
+		s := NewMemoryStore()
+		email := StringWithCharset(20) + "@gmail.com"
+
+		for i := 0; i < lockout.AccountThreshold; i++ {
+			err := s.RegisterFailedLogin(ctx, email, "")
+			if err != nil {
+				t.Errorf("Error while registering failed login: %s", err)
+			}
+		}
+
+		failures, _ := s.GetFailedLoginAttempts(ctx, email)
+		locked, _ := s.GetLockout(ctx, email, "")
+
+		Equal(t, lockout.AccountThreshold, failures)
+		True(t, locked > 0)
+
+		err := s.ClearLockout(ctx, email, "")
+		if err != nil {
+			t.Errorf("Error while clearing lockout: %s", err)
+		}
+
+		locked, _ = s.GetLockout(ctx, email, "")
+		True(t, locked <= 0)
+	})
+
+	t.Run("IPLockout", func(t *testing.T) {
+		s := NewMemoryStore()
+		email := StringWithCharset(20) + "@gmail.com"
+		ip := RandomIPAddress()
+
+		for i := 0; i < lockout.IPThreshold; i++ {
+			s.RegisterFailedLogin(ctx, "", ip)
+		}
+
+		locked, _ := s.GetLockout(ctx, email, ip)
+		True(t, locked > 0)
+
+		err := s.ClearLockout(ctx, email, ip)
+		if err != nil {
+			t.Errorf("Error while clearing lockout: %s", err)
+		}
+
+		locked, _ = s.GetLockout(ctx, email, ip)
+		True(t, locked <= 0)
+	})
+
+	t.Run("UnlockToken", func(t *testing.T) {
+		s := NewMemoryStore()
+		hash := StringWithCharset(64)
+
+		err := s.SaveUnlockToken(ctx, hash, time.Minute)
+		if err != nil {
+			t.Errorf("Error while saving unlock token: %s", err)
+		}
+
+		first, _ := s.ConsumeUnlockToken(ctx, hash)
+		second, _ := s.ConsumeUnlockToken(ctx, hash)
+		unknown, _ := s.ConsumeUnlockToken(ctx, StringWithCharset(64))
+
+		True(t, first)
+		False(t, second)
+		False(t, unknown)
+	})
+
+	t.Run("TOTPStep", func(t *testing.T) {
+		s := NewMemoryStore()
+		email := StringWithCharset(20) + "@gmail.com"
+
+		first, _ := s.MarkTOTPStepUsed(ctx, email, 100, time.Minute)
+		replay, _ := s.MarkTOTPStepUsed(ctx, email, 100, time.Minute)
+		next, _ := s.MarkTOTPStepUsed(ctx, email, 101, time.Minute)
+
+		True(t, first)
+		False(t, replay)
+		True(t, next)
+	})
+
+	t.Run("ChangeEmail", func(t *testing.T) {
+		s := NewMemoryStore()
+		oldEmail := StringWithCharset(20) + "@gmail.com"
+		newEmail := StringWithCharset(20) + "@gmail.com"
+		token := StringWithCharset(60)
+
+		s.CacheJWT(ctx, oldEmail, token)
+		s.CacheTOTPSecret(ctx, oldEmail, "secret")
+
+		err := s.ChangeEmail(ctx, oldEmail, newEmail)
+		if err != nil {
+			t.Errorf("Error while changing email: %s", err)
+		}
+
+		secret, err := s.GetTOTPSecret(ctx, newEmail)
+		Nil(t, err)
+		Equal(t, "secret", secret)
+
+		valid, _ := s.IsTokenValid(ctx, token)
+		False(t, valid)
+	})
+
+	t.Run("BackupCodeAttempts", func(t *testing.T) {
+		s := NewMemoryStore()
+		email := StringWithCharset(20) + "@gmail.com"
+
+		first, _ := s.IncrBackupCodeAttempts(ctx, email, time.Minute)
+		second, _ := s.IncrBackupCodeAttempts(ctx, email, time.Minute)
+		other, _ := s.IncrBackupCodeAttempts(ctx, StringWithCharset(20), time.Minute)
+
+		Equal(t, int64(1), first)
+		Equal(t, int64(2), second)
+		Equal(t, int64(1), other)
+	})
+
+	t.Run("EmailOTPAttempts", func(t *testing.T) {
+		s := NewMemoryStore()
+		email := StringWithCharset(20) + "@gmail.com"
+
+		s.CacheEmailOTP(ctx, email, "first", time.Minute)
+		s.IncrEmailOTPAttempts(ctx, email, time.Minute)
+		s.CacheEmailOTP(ctx, email, "second", time.Minute)
+		afterNewCode, _ := s.IncrEmailOTPAttempts(ctx, email, time.Minute)
+
+		s.DeleteEmailOTP(ctx, email)
+		afterDelete, _ := s.IncrEmailOTPAttempts(ctx, email, time.Minute)
+
+		s.ClearEmailOTPAttempts(ctx, email)
+		afterClear, _ := s.IncrEmailOTPAttempts(ctx, email, time.Minute)
+
+		Equal(t, int64(2), afterNewCode)
+		Equal(t, int64(3), afterDelete)
+		Equal(t, int64(1), afterClear)
+	})
+
+	t.Run("RateLimit", func(t *testing.T) {
+		s := NewMemoryStore()
+
+		first, _ := s.IncrRateLimit(ctx, "192.0.2.1", time.Minute)
+		second, _ := s.IncrRateLimit(ctx, "192.0.2.1", time.Minute)
+		other, _ := s.IncrRateLimit(ctx, "192.0.2.2", time.Minute)
+
+		Equal(t, int64(1), first)
+		Equal(t, int64(2), second)
+		Equal(t, int64(1), other)
+	})
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var rateLimitPrefix = "ratelimit:"
+
+// the window starts with the first request and isn't extended by the following ones
+var incrRateLimit = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// IncrRateLimit counts a request of the ip and returns the number of its requests in the current window
+func (s *redisStore) IncrRateLimit(ctx context.Context, ip string, window time.Duration) (int64, error) {
+	return incrRateLimit.Run(ctx, s.client, []string{rateLimitPrefix + ip}, window.Milliseconds()).Int64()
+}
+
+func (s *redisStore) ResetRateLimit(ctx context.Context, ip string) error {
+	err := s.client.Del(ctx, rateLimitPrefix+ip).Err()
+	return err
+}
@@ -0,0 +1,338 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	errJWTNotCached      = errors.New("jwt token not cached")
+	errEmailNotCached    = errors.New("email not cached")
+	errInvalidSecretId   = errors.New("secretid is not valid")
+	errTOTPNotCached     = errors.New("totp secret is not cached")
+	errNoPendingTOTP     = errors.New("no pending totp enrollment")
+	errNoEmailOTP        = errors.New("no email otp was sent")
+	errNoWebAuthnSession = errors.New("no pending webauthn ceremony")
+)
+
+// JWTStore keeps the jwt of every email and the email of every jwt
+type JWTStore interface {
+	CacheJWT(ctx context.Context, email, token string) error
+	InvalidateSpecificJWTToken(ctx context.Context, email, token string) error
+	InvalidateAllJWTTokens(ctx context.Context, email string) error
+	DoesTokenBelongToEmail(ctx context.Context, email, token string) (bool, error)
+	GetJWTByEmail(ctx context.Context, email string) (string, error)
+	GetEmailByJWT(ctx context.Context, token string) (string, error)
+	EmailExists(ctx context.Context, email string) (bool, error)
+	Check(ctx context.Context, email, token string) (bool, error)
+	IsTokenValid(ctx context.Context, token string) (bool, error)
+	DeleteTokenByEmail(ctx context.Context, email, token string) (bool, error)
+	GetTTLByEmail(ctx context.Context, email string) (time.Duration, error)
+}
+
+// SecretIdStore keeps the secret id that is part of every jwt of the email
+type SecretIdStore interface {
+	GenerateSecretId(ctx context.Context, email string) (string, error)
+	VerifySecretId(ctx context.Context, email, secretId string) (bool, error)
+	HasSecretId(ctx context.Context, email string) (string, bool, error)
+	InvalidateSecretId(ctx context.Context, email string) error
+}
+
+// TOTPStore caches the totp secrets and keeps the pending enrollments and the last used time-steps
+type TOTPStore interface {
+	CacheTOTPSecret(ctx context.Context, email, secret string) error
+	GetTOTPSecret(ctx context.Context, email string) (string, error)
+	DeleteTOTPSecret(ctx context.Context, email string) error
+	IsTOTPSecretCached(ctx context.Context, email string) (bool, error)
+	CachePendingTOTPSecret(ctx context.Context, email, secret string) error
+	GetPendingTOTPSecret(ctx context.Context, email string) (string, error)
+	DeletePendingTOTPSecret(ctx context.Context, email string) error
+	MarkTOTPStepUsed(ctx context.Context, email string, step int64, ttl time.Duration) (bool, error)
+	DeleteTOTPStep(ctx context.Context, email string) error
+	GetAllTOTPSecrets(ctx context.Context) (map[string]string, error)
+}
+
+// UserStore caches the account data
+type UserStore interface {
+	CacheUser(ctx context.Context, user User) error
+	GetUser(ctx context.Context, email string) (*User, error)
+	GetTwoFactorAuthenticationStatus(ctx context.Context, email string) (bool, error)
+	UpdateUser(ctx context.Context, user User) error
+	DeleteUser(ctx context.Context, email string) error
+	IsUserCached(ctx context.Context, email string) (bool, error)
+}
+
+// LoginAttemptStore counts failed logins and locks accounts and ips
+type LoginAttemptStore interface {
+	GetFailedLoginAttempts(ctx context.Context, email string) (int, error)
+	HasFailedLoginAttempts(ctx context.Context, email string) (bool, error)
+	UpdateFailedLoginAttempts(ctx context.Context, email string) error
+	ClearFailedLoginAttempts(ctx context.Context, email string) error
+	RegisterFailedLogin(ctx context.Context, email, ip string) error
+	GetLockout(ctx context.Context, email, ip string) (time.Duration, error)
+	ClearLockout(ctx context.Context, email, ip string) error
+	IncrBackupCodeAttempts(ctx context.Context, email string, window time.Duration) (int64, error)
+	SaveUnlockToken(ctx context.Context, hash string, ttl time.Duration) error
+	ConsumeUnlockToken(ctx context.Context, hash string) (bool, error)
+}
+
+// ChallengeStore keeps the short-lived state of password resets, email codes, webauthn ceremonies and pending second factors
+type ChallengeStore interface {
+	CacheEmailOTP(ctx context.Context, email, hash string, ttl time.Duration) error
+	GetEmailOTP(ctx context.Context, email string) (string, error)
+	DeleteEmailOTP(ctx context.Context, email string) error
+	IncrEmailOTPAttempts(ctx context.Context, email string, window time.Duration) (int64, error)
+	ClearEmailOTPAttempts(ctx context.Context, email string) error
+	CacheWebAuthnSession(ctx context.Context, email, ceremony string, session []byte) error
+	GetWebAuthnSession(ctx context.Context, email, ceremony string) ([]byte, error)
+	SetMFAPending(ctx context.Context, email string) error
+	IsMFAPending(ctx context.Context, email string) (bool, error)
+	ConsumeMFAPending(ctx context.Context, email string) (bool, error)
+}
+
+// RateLimitStore counts the requests of every ip
+type RateLimitStore interface {
+	IncrRateLimit(ctx context.Context, ip string, window time.Duration) (int64, error)
+	ResetRateLimit(ctx context.Context, ip string) error
+}
+
+// Store is everything that is cached; NewRedisStore and NewMemoryStore implement it
+type Store interface {
+	JWTStore
+	SecretIdStore
+	TOTPStore
+	UserStore
+	LoginAttemptStore
+	ChallengeStore
+	RateLimitStore
+	ChangeEmail(ctx context.Context, oldEmail, newEmail string) error
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+func CacheJWT(ctx context.Context, email, token string) error {
+	return store.CacheJWT(ctx, email, token)
+}
+
+func InvalidateSpecificJWTToken(ctx context.Context, email, token string) error {
+	return store.InvalidateSpecificJWTToken(ctx, email, token)
+}
+
+func InvalidateAllJWTTokens(ctx context.Context, email string) error {
+	return store.InvalidateAllJWTTokens(ctx, email)
+}
+
+func DoesTokenBelongToEmail(ctx context.Context, email, token string) (bool, error) {
+	return store.DoesTokenBelongToEmail(ctx, email, token)
+}
+
+func GetJWTByEmail(ctx context.Context, email string) (string, error) {
+	return store.GetJWTByEmail(ctx, email)
+}
+
+func GetEmailByJWT(ctx context.Context, token string) (string, error) {
+	return store.GetEmailByJWT(ctx, token)
+}
+
+func EmailExists(ctx context.Context, email string) (bool, error) {
+	return store.EmailExists(ctx, email)
+}
+
+func Check(ctx context.Context, email, token string) (bool, error) {
+	return store.Check(ctx, email, token)
+}
+
+func IsTokenValid(ctx context.Context, token string) (bool, error) {
+	return store.IsTokenValid(ctx, token)
+}
+
+func DeleteTokenByEmail(ctx context.Context, email, token string) (bool, error) {
+	return store.DeleteTokenByEmail(ctx, email, token)
+}
+
+func GetTTLByEmail(ctx context.Context, email string) (time.Duration, error) {
+	return store.GetTTLByEmail(ctx, email)
+}
+
+func GenerateSecretId(ctx context.Context, email string) (string, error) {
+	return store.GenerateSecretId(ctx, email)
+}
+
+func VerifySecretId(ctx context.Context, email, secretId string) (bool, error) {
+	return store.VerifySecretId(ctx, email, secretId)
+}
+
+func HasSecretId(ctx context.Context, email string) (string, bool, error) {
+	return store.HasSecretId(ctx, email)
+}
+
+func InvalidateSecretId(ctx context.Context, email string) error {
+	return store.InvalidateSecretId(ctx, email)
+}
+
+func CacheTOTPSecret(ctx context.Context, email, secret string) error {
+	return store.CacheTOTPSecret(ctx, email, secret)
+}
+
+func GetTOTPSecret(ctx context.Context, email string) (string, error) {
+	return store.GetTOTPSecret(ctx, email)
+}
+
+func DeleteTOTPSecret(ctx context.Context, email string) error {
+	return store.DeleteTOTPSecret(ctx, email)
+}
+
+func IsTOTPSecretCached(ctx context.Context, email string) (bool, error) {
+	return store.IsTOTPSecretCached(ctx, email)
+}
+
+func CachePendingTOTPSecret(ctx context.Context, email, secret string) error {
+	return store.CachePendingTOTPSecret(ctx, email, secret)
+}
+
+func GetPendingTOTPSecret(ctx context.Context, email string) (string, error) {
+	return store.GetPendingTOTPSecret(ctx, email)
+}
+
+func DeletePendingTOTPSecret(ctx context.Context, email string) error {
+	return store.DeletePendingTOTPSecret(ctx, email)
+}
+
+func MarkTOTPStepUsed(ctx context.Context, email string, step int64, ttl time.Duration) (bool, error) {
+	return store.MarkTOTPStepUsed(ctx, email, step, ttl)
+}
+
+func DeleteTOTPStep(ctx context.Context, email string) error {
+	return store.DeleteTOTPStep(ctx, email)
+}
+
+func GetAllTOTPSecrets(ctx context.Context) (map[string]string, error) {
+	return store.GetAllTOTPSecrets(ctx)
+}
+
+func (user User) CacheUser(ctx context.Context) error {
+	return store.CacheUser(ctx, user)
+}
+
+func GetUser(ctx context.Context, email string) (*User, error) {
+	return store.GetUser(ctx, email)
+}
+
+func GetTwoFactorAuthenticationStatus(ctx context.Context, email string) (bool, error) {
+	return store.GetTwoFactorAuthenticationStatus(ctx, email)
+}
+
+func UpdateUser(ctx context.Context, user User) error {
+	return store.UpdateUser(ctx, user)
+}
+
+func DeleteUser(ctx context.Context, email string) error {
+	return store.DeleteUser(ctx, email)
+}
+
+func IsUserCached(ctx context.Context, email string) (bool, error) {
+	return store.IsUserCached(ctx, email)
+}
+
+func GetFailedLoginAttempts(ctx context.Context, email string) (int, error) {
+	return store.GetFailedLoginAttempts(ctx, email)
+}
+
+func HasFailedLoginAttempts(ctx context.Context, email string) (bool, error) {
+	return store.HasFailedLoginAttempts(ctx, email)
+}
+
+func UpdateFailedLoginAttempts(ctx context.Context, email string) error {
+	return store.UpdateFailedLoginAttempts(ctx, email)
+}
+
+func ClearFailedLoginAttempts(ctx context.Context, email string) error {
+	return store.ClearFailedLoginAttempts(ctx, email)
+}
+
+func RegisterFailedLogin(ctx context.Context, email, ip string) error {
+	return store.RegisterFailedLogin(ctx, email, ip)
+}
+
+func GetLockout(ctx context.Context, email, ip string) (time.Duration, error) {
+	return store.GetLockout(ctx, email, ip)
+}
+
+func ClearLockout(ctx context.Context, email, ip string) error {
+	return store.ClearLockout(ctx, email, ip)
+}
+
+func IncrBackupCodeAttempts(ctx context.Context, email string, window time.Duration) (int64, error) {
+	return store.IncrBackupCodeAttempts(ctx, email, window)
+}
+
+func SaveUnlockToken(ctx context.Context, hash string, ttl time.Duration) error {
+	return store.SaveUnlockToken(ctx, hash, ttl)
+}
+
+func ConsumeUnlockToken(ctx context.Context, hash string) (bool, error) {
+	return store.ConsumeUnlockToken(ctx, hash)
+}
+
+func CacheEmailOTP(ctx context.Context, email, hash string, ttl time.Duration) error {
+	return store.CacheEmailOTP(ctx, email, hash, ttl)
+}
+
+func GetEmailOTP(ctx context.Context, email string) (string, error) {
+	return store.GetEmailOTP(ctx, email)
+}
+
+func DeleteEmailOTP(ctx context.Context, email string) error {
+	return store.DeleteEmailOTP(ctx, email)
+}
+
+func IncrEmailOTPAttempts(ctx context.Context, email string, window time.Duration) (int64, error) {
+	return store.IncrEmailOTPAttempts(ctx, email, window)
+}
+
+func ClearEmailOTPAttempts(ctx context.Context, email string) error {
+	return store.ClearEmailOTPAttempts(ctx, email)
+}
+
+func CacheWebAuthnSession(ctx context.Context, email, ceremony string, session []byte) error {
+	return store.CacheWebAuthnSession(ctx, email, ceremony, session)
+}
+
+func GetWebAuthnSession(ctx context.Context, email, ceremony string) ([]byte, error) {
+	return store.GetWebAuthnSession(ctx, email, ceremony)
+}
+
+func SetMFAPending(ctx context.Context, email string) error {
+	return store.SetMFAPending(ctx, email)
+}
+
+func IsMFAPending(ctx context.Context, email string) (bool, error) {
+	return store.IsMFAPending(ctx, email)
+}
+
+func ConsumeMFAPending(ctx context.Context, email string) (bool, error) {
+	return store.ConsumeMFAPending(ctx, email)
+}
+
+func IncrRateLimit(ctx context.Context, ip string, window time.Duration) (int64, error) {
+	return store.IncrRateLimit(ctx, ip, window)
+}
+
+func ResetRateLimit(ctx context.Context, ip string) error {
+	return store.ResetRateLimit(ctx, ip)
+}
+
+func ChangeEmail(ctx context.Context, oldEmail, newEmail string) error {
+	return store.ChangeEmail(ctx, oldEmail, newEmail)
+}
+
+// Ping checks if the cache can be reached, for the readiness check
+func Ping(ctx context.Context) error {
+	return store.Ping(ctx)
+}
+
+// Close releases the connections of the store, it can't be used afterwards
+func Close() error {
+	return store.Close()
+}
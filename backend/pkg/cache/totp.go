@@ -2,28 +2,102 @@ package cache
 
 import (
 	"context"
-	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/urento/shoppinglist/pkg/config"
 )
 
-func CacheTOTPSecret(email, secret string) error {
-	err := rdb.Set(context.Background(), totpPrefix+email, secret, 0).Err()
+// secrets are persisted in postgres, redis only caches them
+var totpCacheTTL = time.Hour
+
+// enrollments that aren't confirmed in time are abandoned
+var totpEnrollmentTTL = 15 * time.Minute
+
+func setupTOTP(cfg config.TwoFactor) {
+	totpEnrollmentTTL = cfg.TOTPEnrollmentTTL()
+}
+
+func (s *redisStore) CacheTOTPSecret(ctx context.Context, email, secret string) error {
+	err := s.client.Set(ctx, totpPrefix+email, secret, totpCacheTTL).Err()
 	return err
 }
 
-func GetTOTPSecret(email string) (string, error) {
-	val, err := rdb.Get(context.Background(), totpPrefix+email).Result()
+func (s *redisStore) GetTOTPSecret(ctx context.Context, email string) (string, error) {
+	val, err := s.client.Get(ctx, totpPrefix+email).Result()
 	if err != nil {
-		return "not cached", errors.New("totp secret is not cached")
+		return "not cached", errTOTPNotCached
 	}
 	return val, nil
 }
 
-func DeleteTOTPSecret(email string) error {
-	err := rdb.Del(context.Background(), totpPrefix+email).Err()
+func (s *redisStore) DeleteTOTPSecret(ctx context.Context, email string) error {
+	err := s.client.Del(ctx, totpPrefix+email).Err()
 	return err
 }
 
-func IsTOTPSecretCached(email string) (bool, error) {
-	exists, err := rdb.Exists(context.Background(), totpPrefix+email).Result()
+func (s *redisStore) IsTOTPSecretCached(ctx context.Context, email string) (bool, error) {
+	exists, err := s.client.Exists(ctx, totpPrefix+email).Result()
 	return exists == 1, err
 }
+
+func (s *redisStore) CachePendingTOTPSecret(ctx context.Context, email, secret string) error {
+	err := s.client.Set(ctx, totpPendingPrefix+email, secret, totpEnrollmentTTL).Err()
+	return err
+}
+
+func (s *redisStore) GetPendingTOTPSecret(ctx context.Context, email string) (string, error) {
+	val, err := s.client.Get(ctx, totpPendingPrefix+email).Result()
+	if err != nil {
+		return "", errNoPendingTOTP
+	}
+	return val, nil
+}
+
+func (s *redisStore) DeletePendingTOTPSecret(ctx context.Context, email string) error {
+	err := s.client.Del(ctx, totpPendingPrefix+email).Err()
+	return err
+}
+
+// only moves the step forward so a code can't be accepted twice, even by concurrent requests
+var markTOTPStep = redis.NewScript(`
+local last = redis.call("GET", KEYS[1])
+if last and tonumber(last) >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+// MarkTOTPStepUsed records the time-step of an accepted code and reports false if
+// the step (or a later one) was already used by the user
+func (s *redisStore) MarkTOTPStepUsed(ctx context.Context, email string, step int64, ttl time.Duration) (bool, error) {
+	ok, err := markTOTPStep.Run(ctx, s.client, []string{totpStepPrefix + email}, step, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return ok == 1, nil
+}
+
+func (s *redisStore) DeleteTOTPStep(ctx context.Context, email string) error {
+	err := s.client.Del(ctx, totpStepPrefix+email).Err()
+	return err
+}
+
+// GetAllTOTPSecrets returns every cached secret by email; it's only used to migrate them to postgres
+func (s *redisStore) GetAllTOTPSecrets(ctx context.Context) (map[string]string, error) {
+	secrets := map[string]string{}
+
+	iter := s.client.Scan(ctx, 0, totpPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		secret, err := s.client.Get(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		secrets[strings.TrimPrefix(key, totpPrefix)] = secret
+	}
+
+	return secrets, iter.Err()
+}
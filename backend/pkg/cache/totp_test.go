@@ -1,24 +1,27 @@
 package cache
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
 )
 
 func TestCacheAndGetTOTPSecret(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	t.Run("TestCacheAndGetTOTPSecret", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 		secret := StringWithCharset(100)
 
-		err := CacheTOTPSecret(email, secret)
+		err := CacheTOTPSecret(context.Background(), email, secret)
 		if err != nil {
 			t.Errorf("Error while caching TOTP Secret: %s", err)
 		}
 
-		totpSecret, err := GetTOTPSecret(email)
+		totpSecret, err := GetTOTPSecret(context.Background(), email)
 		if err != nil {
 			t.Errorf("Error while getting TOTP Secret: %s", err)
 		}
@@ -30,34 +33,34 @@ func TestCacheAndGetTOTPSecret(t *testing.T) {
 	t.Run("TestGetTOTPSecretThatDoesntExist", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 
-		_, err := GetTOTPSecret(email)
+		_, err := GetTOTPSecret(context.Background(), email)
 
 		Equal(t, "totp secret is not cached", err.Error())
 	})
 }
 
 func TestDeleteTOTPSecret(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 	secret := StringWithCharset(100)
 
-	err := CacheTOTPSecret(email, secret)
+	err := CacheTOTPSecret(context.Background(), email, secret)
 	if err != nil {
 		t.Errorf("Error while caching TOTP Secret: %s", err)
 	}
 
-	totpSecret, err := GetTOTPSecret(email)
+	totpSecret, err := GetTOTPSecret(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while getting TOTP Secret: %s", err)
 	}
 
-	err = DeleteTOTPSecret(email)
+	err = DeleteTOTPSecret(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while deleting TOTP Secret: %s", err)
 	}
 
-	_, delErr := GetTOTPSecret(email)
+	_, delErr := GetTOTPSecret(context.Background(), email)
 
 	Equal(t, secret, totpSecret)
 	Equal(t, nil, err)
@@ -65,23 +68,23 @@ func TestDeleteTOTPSecret(t *testing.T) {
 }
 
 func TestIsTOTPCached(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	t.Run("TestIsTOTPCached", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 		secret := StringWithCharset(100)
 
-		err := CacheTOTPSecret(email, secret)
+		err := CacheTOTPSecret(context.Background(), email, secret)
 		if err != nil {
 			t.Errorf("Error while caching TOTP Secret: %s", err)
 		}
 
-		totpSecret, err := GetTOTPSecret(email)
+		totpSecret, err := GetTOTPSecret(context.Background(), email)
 		if err != nil {
 			t.Errorf("Error while getting TOTP Secret: %s", err)
 		}
 
-		ok, err := IsTOTPSecretCached(email)
+		ok, err := IsTOTPSecretCached(context.Background(), email)
 		if err != nil {
 			t.Errorf("Error while checking if TOTP Secret is cached: %s", err)
 		}
@@ -93,11 +96,89 @@ func TestIsTOTPCached(t *testing.T) {
 	t.Run("TestIsTOTPCachedWhenItsNotCached", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
 
-		_, err := GetTOTPSecret(email)
+		_, err := GetTOTPSecret(context.Background(), email)
 
-		ok, _ := IsTOTPSecretCached(email)
+		ok, _ := IsTOTPSecretCached(context.Background(), email)
 
 		Equal(t, false, ok)
 		Equal(t, "totp secret is not cached", err.Error())
 	})
 }
+
+func TestPendingTOTPSecret(t *testing.T) {
+	Setup(config.MustLoad(nil))
+
+	t.Run("Cache and Get Pending TOTP Secret", func(t *testing.T) {
+		email := StringWithCharset(100) + "@gmail.com"
+		secret := StringWithCharset(32)
+
+		err := CachePendingTOTPSecret(context.Background(), email, secret)
+		if err != nil {
+			t.Errorf("Error while caching pending TOTP Secret: %s", err)
+		}
+
+		pending, err := GetPendingTOTPSecret(context.Background(), email)
+		if err != nil {
+			t.Errorf("Error while getting pending TOTP Secret: %s", err)
+		}
+
+		cached, err := IsTOTPSecretCached(context.Background(), email)
+		if err != nil {
+			t.Errorf("Error while checking if TOTP Secret is cached: %s", err)
+		}
+
+		Equal(t, secret, pending)
+		False(t, cached)
+	})
+
+	t.Run("Delete Pending TOTP Secret", func(t *testing.T) {
+		email := StringWithCharset(100) + "@gmail.com"
+
+		err := CachePendingTOTPSecret(context.Background(), email, StringWithCharset(32))
+		if err != nil {
+			t.Errorf("Error while caching pending TOTP Secret: %s", err)
+		}
+
+		err = DeletePendingTOTPSecret(context.Background(), email)
+		if err != nil {
+			t.Errorf("Error while deleting pending TOTP Secret: %s", err)
+		}
+
+		_, err = GetPendingTOTPSecret(context.Background(), email)
+
+		Equal(t, "no pending totp enrollment", err.Error())
+	})
+}
+
+func TestMarkTOTPStepUsed(t *testing.T) {
+	Setup(config.MustLoad(nil))
+
+	email := StringWithCharset(100) + "@gmail.com"
+
+	ok, err := MarkTOTPStepUsed(context.Background(), email, 100, time.Minute)
+	if err != nil {
+		t.Errorf("Error while marking totp step as used: %s", err)
+	}
+	True(t, ok)
+
+	t.Run("Reuse the same step", func(t *testing.T) {
+		ok, err := MarkTOTPStepUsed(context.Background(), email, 100, time.Minute)
+
+		Nil(t, err)
+		False(t, ok)
+	})
+
+	t.Run("Use an older step", func(t *testing.T) {
+		ok, err := MarkTOTPStepUsed(context.Background(), email, 99, time.Minute)
+
+		Nil(t, err)
+		False(t, ok)
+	})
+
+	t.Run("Use a later step", func(t *testing.T) {
+		ok, err := MarkTOTPStepUsed(context.Background(), email, 101, time.Minute)
+
+		Nil(t, err)
+		True(t, ok)
+	})
+}
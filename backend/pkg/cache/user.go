@@ -18,13 +18,13 @@ type User struct {
 	//TODO: Cache Notifications
 }
 
-func (user User) CacheUser() error {
+func (s *redisStore) CacheUser(ctx context.Context, user User) error {
 	b, err := json.Marshal(user)
 	if err != nil {
 		return err
 	}
 
-	err = rdb.Set(context.Background(), userPrefix+user.EMail, b, 0).Err()
+	err = s.client.Set(ctx, userPrefix+user.EMail, b, 0).Err()
 	if err != nil {
 		return err
 	}
@@ -32,10 +32,10 @@ func (user User) CacheUser() error {
 	return err
 }
 
-func GetUser(email string) (*User, error) {
+func (s *redisStore) GetUser(ctx context.Context, email string) (*User, error) {
 	var user User
 
-	u, err := rdb.Get(context.Background(), userPrefix+email).Result()
+	u, err := s.client.Get(ctx, userPrefix+email).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -49,8 +49,8 @@ func GetUser(email string) (*User, error) {
 	return &user, nil
 }
 
-func GetTwoFactorAuthenticationStatus(email string) (bool, error) {
-	user, err := GetUser(email)
+func (s *redisStore) GetTwoFactorAuthenticationStatus(ctx context.Context, email string) (bool, error) {
+	user, err := s.GetUser(ctx, email)
 	if err != nil {
 		return false, err
 	}
@@ -58,9 +58,8 @@ func GetTwoFactorAuthenticationStatus(email string) (bool, error) {
 	return user.TwoFactorAuthentication, nil
 }
 
-func UpdateUser(user User) error {
-	ctx := context.Background()
-	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+func (s *redisStore) UpdateUser(ctx context.Context, user User) error {
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
 		err := pipe.Del(ctx, userPrefix+user.EMail).Err()
 		if err != nil {
 			return err
@@ -84,13 +83,13 @@ func UpdateUser(user User) error {
 	return nil
 }
 
-func DeleteUser(email string) error {
-	err := rdb.Del(context.Background(), userPrefix+email).Err()
+func (s *redisStore) DeleteUser(ctx context.Context, email string) error {
+	err := s.client.Del(ctx, userPrefix+email).Err()
 	return err
 }
 
-func IsUserCached(email string) (bool, error) {
-	exists, err := rdb.Exists(context.Background(), userPrefix+email).Result()
+func (s *redisStore) IsUserCached(ctx context.Context, email string) (bool, error) {
+	exists, err := s.client.Exists(ctx, userPrefix+email).Result()
 	if err != nil {
 		return false, err
 	}
@@ -1,14 +1,16 @@
 package cache
 
 import (
+	"context"
 	"testing"
 
 	"github.com/alexedwards/argon2id"
 	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
 )
 
 func TestCacheUser(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 	username := StringWithCharset(100)
@@ -33,7 +35,7 @@ func TestCacheUser(t *testing.T) {
 		IPAddress:               ip,
 	}
 
-	err = u.CacheUser()
+	err = u.CacheUser(context.Background())
 	if err != nil {
 		t.Errorf("Error while caching user %s", err)
 	}
@@ -42,7 +44,7 @@ func TestCacheUser(t *testing.T) {
 }
 
 func TestIsUserCached(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	t.Run("Is User Cached", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
@@ -68,12 +70,12 @@ func TestIsUserCached(t *testing.T) {
 			IPAddress:               ip,
 		}
 
-		err = u.CacheUser()
+		err = u.CacheUser(context.Background())
 		if err != nil {
 			t.Errorf("Error while caching user %s", err)
 		}
 
-		cached, err := IsUserCached(email)
+		cached, err := IsUserCached(context.Background(), email)
 		if err != nil {
 			t.Errorf("Error while checking if the user is cached: %s", err)
 		}
@@ -83,14 +85,14 @@ func TestIsUserCached(t *testing.T) {
 	})
 
 	t.Run("Is User Cached when the user isn't cached", func(t *testing.T) {
-		cached, _ := IsUserCached("dfbgkdfgjdfg")
+		cached, _ := IsUserCached(context.Background(), "dfbgkdfgjdfg")
 
 		Equal(t, false, cached)
 	})
 }
 
 func TestGetUser(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	t.Run("TestGetUser", func(t *testing.T) {
 		email := StringWithCharset(100) + "@gmail.com"
@@ -116,12 +118,12 @@ func TestGetUser(t *testing.T) {
 			IPAddress:               ip,
 		}
 
-		err = u.CacheUser()
+		err = u.CacheUser(context.Background())
 		if err != nil {
 			t.Errorf("Error while caching user %s", err)
 		}
 
-		user, err := GetUser(email)
+		user, err := GetUser(context.Background(), email)
 		if err != nil {
 			t.Errorf("Error while getting user: %s", err)
 		}
@@ -136,14 +138,14 @@ func TestGetUser(t *testing.T) {
 	})
 
 	t.Run("TestGetUserThatDoesntExist", func(t *testing.T) {
-		_, err := GetUser("dkjfgbksdjhfgbkjdhfsgb@gmail.com")
+		_, err := GetUser(context.Background(), "dkjfgbksdjhfgbkjdhfsgb@gmail.com")
 
 		NotEqual(t, nil, err)
 	})
 }
 
 func TestUpdateUser(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 	username := StringWithCharset(100)
@@ -168,12 +170,12 @@ func TestUpdateUser(t *testing.T) {
 		IPAddress:               ip,
 	}
 
-	err = u.CacheUser()
+	err = u.CacheUser(context.Background())
 	if err != nil {
 		t.Errorf("Error while caching user %s", err)
 	}
 
-	user, err := GetUser(email)
+	user, err := GetUser(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while getting user: %s", err)
 	}
@@ -193,12 +195,12 @@ func TestUpdateUser(t *testing.T) {
 		IPAddress:               newIp,
 	}
 
-	err = UpdateUser(newUser)
+	err = UpdateUser(context.Background(), newUser)
 	if err != nil {
 		t.Errorf("Error while updating user: %s", err)
 	}
 
-	updatedUser, err := GetUser(email)
+	updatedUser, err := GetUser(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while getting updated user: %s", err)
 	}
@@ -219,7 +221,7 @@ func TestUpdateUser(t *testing.T) {
 }
 
 func TestDeleteUser(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 	username := StringWithCharset(100)
@@ -244,17 +246,17 @@ func TestDeleteUser(t *testing.T) {
 		IPAddress:               ip,
 	}
 
-	err = u.CacheUser()
+	err = u.CacheUser(context.Background())
 	if err != nil {
 		t.Errorf("Error while caching user: %s", err)
 	}
 
-	err = DeleteUser(email)
+	err = DeleteUser(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while deleting user: %s", err)
 	}
 
-	_, shouldErr := GetUser(email)
+	_, shouldErr := GetUser(context.Background(), email)
 	if shouldErr == nil {
 		t.Errorf("GetUser didn't throw an error after deleting")
 	}
@@ -264,7 +266,7 @@ func TestDeleteUser(t *testing.T) {
 }
 
 func TestGetTwoFactorAuthenticationStatus(t *testing.T) {
-	Setup(false)
+	Setup(config.MustLoad(nil))
 
 	email := StringWithCharset(100) + "@gmail.com"
 	username := StringWithCharset(100)
@@ -289,12 +291,12 @@ func TestGetTwoFactorAuthenticationStatus(t *testing.T) {
 		IPAddress:               ip,
 	}
 
-	err = u.CacheUser()
+	err = u.CacheUser(context.Background())
 	if err != nil {
 		t.Errorf("Error while caching user: %s", err)
 	}
 
-	status, err := GetTwoFactorAuthenticationStatus(email)
+	status, err := GetTwoFactorAuthenticationStatus(context.Background(), email)
 	if err != nil {
 		t.Errorf("Error while getting two factor authentication status: %s", err)
 	}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// challenges have to be answered within the timeout of the ceremony
+var webAuthnSessionTTL = 5 * time.Minute
+
+// webAuthnCeremonies are the ceremonies a session can be cached for
+var webAuthnCeremonies = []string{"registration", "login"}
+
+// how long a second factor can be provided after the password was checked
+var mfaPendingTTL = 5 * time.Minute
+
+// CacheWebAuthnSession stores the session data of a registration or login ceremony
+func (s *redisStore) CacheWebAuthnSession(ctx context.Context, email, ceremony string, session []byte) error {
+	err := s.client.Set(ctx, webAuthnSessionPrefix+ceremony+":"+email, session, webAuthnSessionTTL).Err()
+	return err
+}
+
+// GetWebAuthnSession returns the session data of the ceremony and deletes it so every challenge can only be used once
+func (s *redisStore) GetWebAuthnSession(ctx context.Context, email, ceremony string) ([]byte, error) {
+	key := webAuthnSessionPrefix + ceremony + ":" + email
+
+	var get *redis.StringCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		return pipe.Del(ctx, key).Err()
+	})
+	if err == redis.Nil {
+		return nil, errNoWebAuthnSession
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return get.Bytes()
+}
+
+// SetMFAPending marks that the user entered the correct password and still has to provide a second factor
+func (s *redisStore) SetMFAPending(ctx context.Context, email string) error {
+	err := s.client.Set(ctx, mfaPendingPrefix+email, "1", mfaPendingTTL).Err()
+	return err
+}
+
+func (s *redisStore) IsMFAPending(ctx context.Context, email string) (bool, error) {
+	exists, err := s.client.Exists(ctx, mfaPendingPrefix+email).Result()
+	return exists == 1, err
+}
+
+// ConsumeMFAPending removes the marker and reports if it was set
+func (s *redisStore) ConsumeMFAPending(ctx context.Context, email string) (bool, error) {
+	deleted, err := s.client.Del(ctx, mfaPendingPrefix+email).Result()
+	return deleted == 1, err
+}
@@ -0,0 +1,751 @@
+// Package config loads the settings of the server once at startup. Every value is read from, in increasing order
+// of precedence, the defaults below, an optional YAML file, the environment (including a .env file) and the
+// command line flags.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	Development = "development"
+	Production  = "production"
+)
+
+type Config struct {
+	// Environment is either development or production. Only development runs AutoMigrate and allows cookies
+	// without the Secure flag
+	Environment string    `yaml:"environment"`
+	ListenAddr  string    `yaml:"listen_addr"`
+	AppURL      string    `yaml:"app_url"`
+	Server      Server    `yaml:"server"`
+	Database    Database  `yaml:"database"`
+	Redis       Redis     `yaml:"redis"`
+	CORS        CORS      `yaml:"cors"`
+	Cookie      Cookie    `yaml:"cookie"`
+	JWT         JWT       `yaml:"jwt"`
+	RateLimit   RateLimit `yaml:"rate_limit"`
+	Metrics     Metrics   `yaml:"metrics"`
+	Log         Log       `yaml:"log"`
+	Tracing     Tracing   `yaml:"tracing"`
+	Mail        Mail      `yaml:"mail"`
+	Lockout     Lockout   `yaml:"lockout"`
+	TwoFactor   TwoFactor `yaml:"two_factor"`
+	WebAuthn    WebAuthn  `yaml:"webauthn"`
+	Password    Password  `yaml:"password"`
+	Reauth      Reauth    `yaml:"reauth"`
+	Account     Account   `yaml:"account"`
+}
+
+// Server holds the timeouts of the http server, all in seconds
+type Server struct {
+	ReadTimeoutSeconds  int `yaml:"read_timeout_seconds"`
+	WriteTimeoutSeconds int `yaml:"write_timeout_seconds"`
+	IdleTimeoutSeconds  int `yaml:"idle_timeout_seconds"`
+	// ShutdownTimeoutSeconds is how long in-flight requests may take after SIGTERM before they are cut off
+	ShutdownTimeoutSeconds int `yaml:"shutdown_timeout_seconds"`
+	// RequestTimeoutSeconds is the deadline of the context of every request, the database and redis calls of a
+	// request are cancelled once it passes
+	RequestTimeoutSeconds int `yaml:"request_timeout_seconds"`
+}
+
+type Database struct {
+	DSN string `yaml:"dsn"`
+	// MigrateOnStart applies the pending migrations in models.Setup, otherwise they have to be run with `migrate up`
+	MigrateOnStart bool `yaml:"migrate_on_start"`
+}
+
+type Redis struct {
+	// Driver is redis or memory. The memory driver only works with a single instance
+	Driver   string `yaml:"driver"`
+	Addr     string `yaml:"addr"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+}
+
+type CORS struct {
+	AllowOrigins []string `yaml:"allow_origins"`
+}
+
+type Cookie struct {
+	Domain string `yaml:"domain"`
+	// Secure is always set in production
+	Secure bool `yaml:"secure"`
+}
+
+type JWT struct {
+	Secret string `yaml:"secret"`
+}
+
+type RateLimit struct {
+	Requests      int `yaml:"requests"`
+	WindowSeconds int `yaml:"window_seconds"`
+}
+
+type Metrics struct {
+	// ListenAddr serves /metrics apart from the api, it should only be reachable from the internal network
+	ListenAddr string `yaml:"listen_addr"`
+	// RefreshSeconds is how often the totals of users and shoppinglists are counted
+	RefreshSeconds int `yaml:"refresh_seconds"`
+}
+
+type Log struct {
+	// Level is debug, info, warn or error. SQL statements are only logged with debug
+	Level string `yaml:"level"`
+	// Format is json or console
+	Format string `yaml:"format"`
+	// SlowQueryMillis is the duration after which queries are logged as warnings
+	SlowQueryMillis int `yaml:"slow_query_millis"`
+}
+
+type Tracing struct {
+	// Exporter is none, otlp or stdout. stdout prints every span and is only meant for local debugging
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the host:port of the OTLP/HTTP collector
+	Endpoint string `yaml:"endpoint"`
+	// Insecure sends the spans to the collector without TLS
+	Insecure    bool    `yaml:"insecure"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Mail is the smtp server the emails are sent with. Without a host the emails are only logged, which is only
+// allowed in development because they contain login codes and links
+type Mail struct {
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+	From         string `yaml:"from"`
+}
+
+// Lockout is the progressive login lockout. Accounts and ips are locked for BaseSeconds once they reach their
+// threshold of failed logins, every further failure doubles the window up to MaxSeconds
+type Lockout struct {
+	AccountThreshold int `yaml:"account_threshold"`
+	IPThreshold      int `yaml:"ip_threshold"`
+	BaseSeconds      int `yaml:"base_seconds"`
+	MaxSeconds       int `yaml:"max_seconds"`
+	// FailureWindowSeconds is how long failed logins are counted
+	FailureWindowSeconds int `yaml:"failure_window_seconds"`
+}
+
+type TwoFactor struct {
+	// EncryptionKey encrypts the TOTP secrets in the database, they can't be read anymore once it changes
+	EncryptionKey string `yaml:"encryption_key"`
+	// TOTPSkewSteps is how many 30 second steps before and after the current one are still accepted
+	TOTPSkewSteps int `yaml:"totp_skew_steps"`
+	// TOTPEnrollmentTTLSeconds is how long a new secret can be confirmed before the enrollment is abandoned
+	TOTPEnrollmentTTLSeconds int `yaml:"totp_enrollment_ttl_seconds"`
+	EmailOTPTTLSeconds       int `yaml:"email_otp_ttl_seconds"`
+	// EmailOTPMaxAttempts is how many email codes can be entered per attempt window, a new code doesn't reset them
+	EmailOTPMaxAttempts int `yaml:"email_otp_max_attempts"`
+	// EmailOTPAttemptWindowSeconds starts with the first attempt and isn't extended by the following ones
+	EmailOTPAttemptWindowSeconds int `yaml:"email_otp_attempt_window_seconds"`
+}
+
+// WebAuthn is the relying party the security keys are registered for, RPOrigin is the origin of the frontend
+type WebAuthn struct {
+	RPName   string `yaml:"rp_name"`
+	RPID     string `yaml:"rp_id"`
+	RPOrigin string `yaml:"rp_origin"`
+}
+
+type Password struct {
+	MinLength int `yaml:"min_length"`
+	// RequiredClasses is how many of lowercase letters, uppercase letters, numbers and symbols are required
+	RequiredClasses   int  `yaml:"required_classes"`
+	AllowEmail        bool `yaml:"allow_email"`
+	SkipBreachedCheck bool `yaml:"skip_breached_check"`
+	// BreachedFile is a list of breached password hashes that is checked in addition to the bundled one
+	BreachedFile string `yaml:"breached_file"`
+	Argon2       Argon2 `yaml:"argon2"`
+}
+
+// Argon2 are the parameters new password hashes are created with, weaker hashes are upgraded on login
+type Argon2 struct {
+	Memory      int `yaml:"memory"`
+	Iterations  int `yaml:"iterations"`
+	Parallelism int `yaml:"parallelism"`
+	SaltLength  int `yaml:"salt_length"`
+	KeyLength   int `yaml:"key_length"`
+}
+
+type Reauth struct {
+	// TTLSeconds is how long a re-authentication allows the sensitive routes
+	TTLSeconds int `yaml:"ttl_seconds"`
+	// Routes replace the default sensitive routes, e.g. "POST /api/v1/auth/update"
+	Routes []string `yaml:"routes"`
+}
+
+type Account struct {
+	// DeletionGraceDays is how long deactivated accounts can be reactivated before they are deleted
+	DeletionGraceDays       int `yaml:"deletion_grace_days"`
+	ResetPasswordTTLMinutes int `yaml:"reset_password_ttl_minutes"`
+	EmailChangeTTLMinutes   int `yaml:"email_change_ttl_minutes"`
+}
+
+func (s Server) ReadTimeout() time.Duration {
+	return time.Duration(s.ReadTimeoutSeconds) * time.Second
+}
+
+func (s Server) WriteTimeout() time.Duration {
+	return time.Duration(s.WriteTimeoutSeconds) * time.Second
+}
+
+func (s Server) IdleTimeout() time.Duration {
+	return time.Duration(s.IdleTimeoutSeconds) * time.Second
+}
+
+func (s Server) ShutdownTimeout() time.Duration {
+	return time.Duration(s.ShutdownTimeoutSeconds) * time.Second
+}
+
+func (s Server) RequestTimeout() time.Duration {
+	return time.Duration(s.RequestTimeoutSeconds) * time.Second
+}
+
+func (r RateLimit) Window() time.Duration {
+	return time.Duration(r.WindowSeconds) * time.Second
+}
+
+func (m Metrics) RefreshInterval() time.Duration {
+	return time.Duration(m.RefreshSeconds) * time.Second
+}
+
+func (l Log) SlowQueryThreshold() time.Duration {
+	return time.Duration(l.SlowQueryMillis) * time.Millisecond
+}
+
+func (l Lockout) BaseWindow() time.Duration {
+	return time.Duration(l.BaseSeconds) * time.Second
+}
+
+func (l Lockout) MaxWindow() time.Duration {
+	return time.Duration(l.MaxSeconds) * time.Second
+}
+
+func (l Lockout) FailureWindow() time.Duration {
+	return time.Duration(l.FailureWindowSeconds) * time.Second
+}
+
+func (t TwoFactor) TOTPEnrollmentTTL() time.Duration {
+	return time.Duration(t.TOTPEnrollmentTTLSeconds) * time.Second
+}
+
+func (t TwoFactor) EmailOTPTTL() time.Duration {
+	return time.Duration(t.EmailOTPTTLSeconds) * time.Second
+}
+
+func (t TwoFactor) EmailOTPAttemptWindow() time.Duration {
+	return time.Duration(t.EmailOTPAttemptWindowSeconds) * time.Second
+}
+
+func (r Reauth) TTL() time.Duration {
+	return time.Duration(r.TTLSeconds) * time.Second
+}
+
+func (a Account) DeletionGracePeriod() time.Duration {
+	return time.Duration(a.DeletionGraceDays) * 24 * time.Hour
+}
+
+func (a Account) ResetPasswordTTL() time.Duration {
+	return time.Duration(a.ResetPasswordTTLMinutes) * time.Minute
+}
+
+func (a Account) EmailChangeTTL() time.Duration {
+	return time.Duration(a.EmailChangeTTLMinutes) * time.Minute
+}
+
+func (c *Config) IsProduction() bool {
+	return c.Environment == Production
+}
+
+func (c *Config) IsDevelopment() bool {
+	return c.Environment == Development
+}
+
+func Default() *Config {
+	return &Config{
+		Environment: Production,
+		ListenAddr:  ":8080",
+		AppURL:      "http://localhost:3000",
+		Server: Server{
+			ReadTimeoutSeconds:     15,
+			WriteTimeoutSeconds:    30,
+			IdleTimeoutSeconds:     60,
+			ShutdownTimeoutSeconds: 20,
+			RequestTimeoutSeconds:  10,
+		},
+		Database: Database{
+			MigrateOnStart: true,
+		},
+		Redis: Redis{
+			Driver: "redis",
+			Addr:   "localhost:6379",
+		},
+		CORS: CORS{
+			AllowOrigins: []string{"http://localhost:3000"},
+		},
+		RateLimit: RateLimit{
+			Requests:      300,
+			WindowSeconds: 180,
+		},
+		Metrics: Metrics{
+			ListenAddr:     ":9100",
+			RefreshSeconds: 60,
+		},
+		Log: Log{
+			Level:           "info",
+			Format:          "json",
+			SlowQueryMillis: 200,
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			Endpoint:    "localhost:4318",
+			ServiceName: "shoppinglist",
+			SampleRatio: 1,
+		},
+		Mail: Mail{
+			SMTPPort: 587,
+		},
+		Lockout: Lockout{
+			AccountThreshold:     5,
+			IPThreshold:          20,
+			BaseSeconds:          60,
+			MaxSeconds:           86400,
+			FailureWindowSeconds: 86400,
+		},
+		TwoFactor: TwoFactor{
+			TOTPSkewSteps:                1,
+			TOTPEnrollmentTTLSeconds:     900,
+			EmailOTPTTLSeconds:           600,
+			EmailOTPMaxAttempts:          5,
+			EmailOTPAttemptWindowSeconds: 900,
+		},
+		WebAuthn: WebAuthn{
+			RPName:   "Shoppinglist",
+			RPID:     "localhost",
+			RPOrigin: "http://localhost:3000",
+		},
+		Password: Password{
+			MinLength:       8,
+			RequiredClasses: 2,
+			// the defaults of argon2id
+			Argon2: Argon2{
+				Memory:      64 * 1024,
+				Iterations:  1,
+				Parallelism: 2,
+				SaltLength:  16,
+				KeyLength:   32,
+			},
+		},
+		Reauth: Reauth{
+			TTLSeconds: 300,
+		},
+		Account: Account{
+			DeletionGraceDays:       30,
+			ResetPasswordTTLMinutes: 60,
+			EmailChangeTTLMinutes:   60,
+		},
+	}
+}
+
+// Load builds the config from the YAML file given with -config or CONFIG_FILE, the environment and args, and
+// validates it. args are the command line flags without the program name, tests pass nil
+func Load(args []string) (*Config, error) {
+	err := loadEnvFile()
+	if err != nil {
+		return nil, err
+	}
+
+	fs := flag.NewFlagSet("shoppinglist", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file")
+	listenAddr := fs.String("listen", "", "address to listen on, e.g. :8080")
+	environment := fs.String("environment", "", "development or production")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+
+	if len(*path) > 0 {
+		err = cfg.loadFile(*path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = cfg.loadEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*listenAddr) > 0 {
+		cfg.ListenAddr = *listenAddr
+	}
+	if len(*environment) > 0 {
+		cfg.Environment = *environment
+	}
+
+	if cfg.IsProduction() {
+		cfg.Cookie.Secure = true
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// MustLoad is Load for tests and commands that can't continue without a config
+func MustLoad(args []string) *Config {
+	cfg, err := Load(args)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+func (c *Config) Validate() error {
+	var errs []string
+
+	if c.Environment != Development && c.Environment != Production {
+		errs = append(errs, fmt.Sprintf("environment has to be %s or %s, got %q", Development, Production, c.Environment))
+	}
+	if len(c.ListenAddr) <= 0 {
+		errs = append(errs, "listen address is empty")
+	}
+	if len(c.Metrics.ListenAddr) <= 0 || c.Metrics.ListenAddr == c.ListenAddr {
+		errs = append(errs, "metrics listen address has to be set and differ from the listen address")
+	}
+	if c.Server.ReadTimeoutSeconds <= 0 || c.Server.WriteTimeoutSeconds <= 0 || c.Server.IdleTimeoutSeconds <= 0 {
+		errs = append(errs, "server timeouts have to be greater than 0")
+	}
+	if c.Server.ShutdownTimeoutSeconds <= 0 {
+		errs = append(errs, "shutdown timeout has to be greater than 0")
+	}
+	if c.Server.RequestTimeoutSeconds <= 0 || c.Server.RequestTimeoutSeconds > c.Server.WriteTimeoutSeconds {
+		errs = append(errs, "request timeout has to be greater than 0 and at most the write timeout")
+	}
+	if len(c.Database.DSN) <= 0 {
+		errs = append(errs, "database dsn is empty (DATABASE_DSN)")
+	}
+
+	switch c.Redis.Driver {
+	case "redis":
+		if len(c.Redis.Addr) <= 0 {
+			errs = append(errs, "redis address is empty (REDIS_ADDR)")
+		}
+	case "memory":
+	default:
+		errs = append(errs, fmt.Sprintf("cache driver has to be redis or memory, got %q", c.Redis.Driver))
+	}
+
+	for _, origin := range c.CORS.AllowOrigins {
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) <= 0 {
+			errs = append(errs, fmt.Sprintf("cors origin %q is not a valid http(s) origin", origin))
+		}
+	}
+
+	if len(c.JWT.Secret) <= 0 {
+		errs = append(errs, "jwt secret is empty (JwtSecret)")
+	}
+	if c.RateLimit.Requests <= 0 {
+		errs = append(errs, "rate limit requests have to be greater than 0")
+	}
+	if c.RateLimit.WindowSeconds <= 0 {
+		errs = append(errs, "rate limit window has to be greater than 0")
+	}
+	if c.Metrics.RefreshSeconds <= 0 {
+		errs = append(errs, "metrics refresh interval has to be greater than 0")
+	}
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Sprintf("log level has to be debug, info, warn or error, got %q", c.Log.Level))
+	}
+	if c.Log.Format != "json" && c.Log.Format != "console" {
+		errs = append(errs, fmt.Sprintf("log format has to be json or console, got %q", c.Log.Format))
+	}
+	if c.Log.SlowQueryMillis <= 0 {
+		errs = append(errs, "slow query threshold has to be greater than 0")
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if len(c.Tracing.Endpoint) <= 0 {
+			errs = append(errs, "otlp endpoint is empty (OTEL_EXPORTER_OTLP_ENDPOINT)")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("tracing exporter has to be none, otlp or stdout, got %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, "tracing sample ratio has to be between 0 and 1")
+	}
+
+	if len(c.Mail.SMTPHost) > 0 {
+		if c.Mail.SMTPPort <= 0 {
+			errs = append(errs, "smtp port has to be greater than 0")
+		}
+		if len(c.Mail.From) <= 0 {
+			errs = append(errs, "mail sender is empty (MAIL_FROM)")
+		}
+	}
+
+	if c.Lockout.AccountThreshold <= 0 || c.Lockout.IPThreshold <= 0 {
+		errs = append(errs, "lockout thresholds have to be greater than 0")
+	}
+	if c.Lockout.BaseSeconds <= 0 || c.Lockout.MaxSeconds < c.Lockout.BaseSeconds {
+		errs = append(errs, "lockout window has to be greater than 0 and at most the max window")
+	}
+	if c.Lockout.FailureWindowSeconds <= 0 {
+		errs = append(errs, "failure window has to be greater than 0")
+	}
+
+	if len(c.TwoFactor.EncryptionKey) <= 0 {
+		errs = append(errs, "encryption key is empty (TOTP_ENCRYPTION_KEY or ENCRYPTION_KEYSTRING)")
+	}
+	if c.TwoFactor.TOTPSkewSteps < 0 {
+		errs = append(errs, "totp skew steps can't be negative")
+	}
+	if c.TwoFactor.TOTPEnrollmentTTLSeconds <= 0 || c.TwoFactor.EmailOTPTTLSeconds <= 0 {
+		errs = append(errs, "totp enrollment and email code ttls have to be greater than 0")
+	}
+	if c.TwoFactor.EmailOTPMaxAttempts <= 0 || c.TwoFactor.EmailOTPAttemptWindowSeconds <= 0 {
+		errs = append(errs, "email code attempts and their window have to be greater than 0")
+	}
+
+	if len(c.WebAuthn.RPID) <= 0 || len(c.WebAuthn.RPOrigin) <= 0 {
+		errs = append(errs, "webauthn rp id and origin can't be empty")
+	}
+
+	if c.Password.MinLength <= 0 {
+		errs = append(errs, "password min length has to be greater than 0")
+	}
+	if c.Password.RequiredClasses < 0 || c.Password.RequiredClasses > 4 {
+		errs = append(errs, "required password character classes have to be between 0 and 4")
+	}
+	argon := c.Password.Argon2
+	if argon.Memory <= 0 || argon.Iterations <= 0 || argon.SaltLength <= 0 || argon.KeyLength <= 0 {
+		errs = append(errs, "argon2 parameters have to be greater than 0")
+	}
+	if argon.Parallelism <= 0 || argon.Parallelism > 255 {
+		errs = append(errs, "argon2 parallelism has to be between 1 and 255")
+	}
+
+	if c.Reauth.TTLSeconds <= 0 {
+		errs = append(errs, "reauth ttl has to be greater than 0")
+	}
+
+	if c.Account.DeletionGraceDays < 0 {
+		errs = append(errs, "account deletion grace period can't be negative")
+	}
+	if c.Account.ResetPasswordTTLMinutes <= 0 || c.Account.EmailChangeTTLMinutes <= 0 {
+		errs = append(errs, "reset password and email change ttls have to be greater than 0")
+	}
+
+	if len(errs) > 0 {
+		return errors.New("invalid config: " + strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func (c *Config) loadFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	err = yaml.UnmarshalStrict(b, c)
+	if err != nil {
+		return fmt.Errorf("error while parsing %s: %s", path, err)
+	}
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	setString(&c.Environment, "ENVIRONMENT")
+	setString(&c.ListenAddr, "LISTEN_ADDR")
+	setString(&c.Metrics.ListenAddr, "METRICS_LISTEN_ADDR")
+	setString(&c.AppURL, "APP_URL")
+	setString(&c.Database.DSN, "DATABASE_DSN")
+	setString(&c.Redis.Driver, "CACHE_DRIVER")
+	setString(&c.Redis.Addr, "REDIS_ADDR")
+	setString(&c.Redis.Password, "REDIS_PASSWORD")
+	setString(&c.Cookie.Domain, "DOMAIN")
+	setString(&c.JWT.Secret, "JwtSecret")
+	setString(&c.Log.Level, "LOG_LEVEL")
+	setString(&c.Log.Format, "LOG_FORMAT")
+	setString(&c.Tracing.Exporter, "TRACING_EXPORTER")
+	setString(&c.Tracing.Endpoint, "OTEL_EXPORTER_OTLP_ENDPOINT")
+	setString(&c.Tracing.ServiceName, "OTEL_SERVICE_NAME")
+	setString(&c.Mail.SMTPHost, "SMTP_HOST")
+	setString(&c.Mail.SMTPUsername, "SMTP_USERNAME")
+	setString(&c.Mail.SMTPPassword, "SMTP_PASSWORD")
+	setString(&c.Mail.From, "MAIL_FROM")
+	setString(&c.TwoFactor.EncryptionKey, "ENCRYPTION_KEYSTRING")
+	//a separate key for the totp secrets wins over the shared one
+	setString(&c.TwoFactor.EncryptionKey, "TOTP_ENCRYPTION_KEY")
+	setString(&c.WebAuthn.RPName, "WEBAUTHN_RP_NAME")
+	setString(&c.WebAuthn.RPID, "WEBAUTHN_RP_ID")
+	setString(&c.WebAuthn.RPOrigin, "WEBAUTHN_RP_ORIGIN")
+	setString(&c.Password.BreachedFile, "BREACHED_PASSWORDS_FILE")
+
+	setList(&c.CORS.AllowOrigins, "CORS_ALLOW_ORIGINS")
+	setList(&c.Reauth.Routes, "REAUTH_ROUTES")
+
+	for key, dst := range map[string]*bool{
+		"MIGRATE_ON_START":             &c.Database.MigrateOnStart,
+		"COOKIE_SECURE":                &c.Cookie.Secure,
+		"TRACING_INSECURE":             &c.Tracing.Insecure,
+		"PASSWORD_ALLOW_EMAIL":         &c.Password.AllowEmail,
+		"PASSWORD_SKIP_BREACHED_CHECK": &c.Password.SkipBreachedCheck,
+	} {
+		if err := setBool(dst, key); err != nil {
+			return err
+		}
+	}
+
+	for key, dst := range map[string]*int{
+		"READ_TIMEOUT_SECONDS":      &c.Server.ReadTimeoutSeconds,
+		"WRITE_TIMEOUT_SECONDS":     &c.Server.WriteTimeoutSeconds,
+		"IDLE_TIMEOUT_SECONDS":      &c.Server.IdleTimeoutSeconds,
+		"SHUTDOWN_TIMEOUT_SECONDS":  &c.Server.ShutdownTimeoutSeconds,
+		"REQUEST_TIMEOUT_SECONDS":   &c.Server.RequestTimeoutSeconds,
+		"REDIS_DB":                  &c.Redis.DB,
+		"RATE_LIMIT_REQUESTS":       &c.RateLimit.Requests,
+		"RATE_LIMIT_WINDOW_SECONDS": &c.RateLimit.WindowSeconds,
+		"METRICS_REFRESH_SECONDS":   &c.Metrics.RefreshSeconds,
+		"SLOW_QUERY_MILLIS":         &c.Log.SlowQueryMillis,
+		"SMTP_PORT":                 &c.Mail.SMTPPort,
+
+		"LOGIN_LOCKOUT_ACCOUNT_THRESHOLD": &c.Lockout.AccountThreshold,
+		"LOGIN_LOCKOUT_IP_THRESHOLD":      &c.Lockout.IPThreshold,
+		"LOGIN_LOCKOUT_BASE_SECONDS":      &c.Lockout.BaseSeconds,
+		"LOGIN_LOCKOUT_MAX_SECONDS":       &c.Lockout.MaxSeconds,
+		"LOGIN_FAILURE_WINDOW_SECONDS":    &c.Lockout.FailureWindowSeconds,
+		"TOTP_SKEW_STEPS":                 &c.TwoFactor.TOTPSkewSteps,
+		"TOTP_ENROLLMENT_TTL_SECONDS":     &c.TwoFactor.TOTPEnrollmentTTLSeconds,
+		"EMAIL_OTP_TTL_SECONDS":           &c.TwoFactor.EmailOTPTTLSeconds,
+		"EMAIL_OTP_MAX_ATTEMPTS":          &c.TwoFactor.EmailOTPMaxAttempts,
+		"EMAIL_OTP_WINDOW_SECONDS":        &c.TwoFactor.EmailOTPAttemptWindowSeconds,
+		"PASSWORD_MIN_LENGTH":             &c.Password.MinLength,
+		"PASSWORD_REQUIRED_CLASSES":       &c.Password.RequiredClasses,
+		"ARGON2_MEMORY":                   &c.Password.Argon2.Memory,
+		"ARGON2_ITERATIONS":               &c.Password.Argon2.Iterations,
+		"ARGON2_PARALLELISM":              &c.Password.Argon2.Parallelism,
+		"ARGON2_SALT_LENGTH":              &c.Password.Argon2.SaltLength,
+		"ARGON2_KEY_LENGTH":               &c.Password.Argon2.KeyLength,
+		"REAUTH_TTL_SECONDS":              &c.Reauth.TTLSeconds,
+		"ACCOUNT_DELETION_GRACE_DAYS":     &c.Account.DeletionGraceDays,
+		"RESET_PASSWORD_TTL_MINUTES":      &c.Account.ResetPasswordTTLMinutes,
+		"EMAIL_CHANGE_TTL_MINUTES":        &c.Account.EmailChangeTTLMinutes,
+	} {
+		if err := setInt(dst, key); err != nil {
+			return err
+		}
+	}
+
+	if val := os.Getenv("TRACING_SAMPLE_RATIO"); len(val) > 0 {
+		ratio, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("TRACING_SAMPLE_RATIO has to be a number, got %q", val)
+		}
+		c.Tracing.SampleRatio = ratio
+	}
+
+	return nil
+}
+
+func setString(dst *string, key string) {
+	if val := os.Getenv(key); len(val) > 0 {
+		*dst = val
+	}
+}
+
+// setList replaces dst with the comma separated values of key
+func setList(dst *[]string, key string) {
+	list := os.Getenv(key)
+	if len(list) <= 0 {
+		return
+	}
+
+	*dst = nil
+	for _, val := range strings.Split(list, ",") {
+		if val = strings.TrimSpace(val); len(val) > 0 {
+			*dst = append(*dst, val)
+		}
+	}
+}
+
+func setBool(dst *bool, key string) error {
+	val := os.Getenv(key)
+	if len(val) <= 0 {
+		return nil
+	}
+
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return fmt.Errorf("%s has to be true or false, got %q", key, val)
+	}
+	*dst = b
+	return nil
+}
+
+func setInt(dst *int, key string) error {
+	val := os.Getenv(key)
+	if len(val) <= 0 {
+		return nil
+	}
+
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		return fmt.Errorf("%s has to be a number, got %q", key, val)
+	}
+	*dst = i
+	return nil
+}
+
+// loadEnvFile loads ENV_FILE, or the first .env found in the working directory or one of its parents, so the
+// same file is used no matter which package the tests are run from. Variables that are already set win
+func loadEnvFile() error {
+	if path := os.Getenv("ENV_FILE"); len(path) > 0 {
+		return godotenv.Load(path)
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	for {
+		path := filepath.Join(dir, ".env")
+		if _, err := os.Stat(path); err == nil {
+			return godotenv.Load(path)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
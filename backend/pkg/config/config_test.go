@@ -0,0 +1,127 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+)
+
+func setEnv(t *testing.T, key, value string) {
+	old, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	err := ioutil.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatalf("Error while writing config file: %s", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	setEnv(t, "ENV_FILE", os.DevNull)
+	setEnv(t, "DATABASE_DSN", "host=localhost")
+	setEnv(t, "JwtSecret", "secret")
+	setEnv(t, "ENCRYPTION_KEYSTRING", "key")
+	setEnv(t, "TOTP_ENCRYPTION_KEY", "")
+	setEnv(t, "ENVIRONMENT", "")
+	setEnv(t, "LISTEN_ADDR", "")
+	setEnv(t, "METRICS_LISTEN_ADDR", "")
+	setEnv(t, "CORS_ALLOW_ORIGINS", "")
+	setEnv(t, "RATE_LIMIT_REQUESTS", "")
+
+	t.Run("TestDefaults", func(t *testing.T) {
+		cfg, err := Load(nil)
+		if err != nil {
+			t.Fatalf("Error while loading config: %s", err)
+		}
+
+		Equal(t, ":8080", cfg.ListenAddr)
+		Equal(t, ":9100", cfg.Metrics.ListenAddr)
+		Equal(t, []string{"http://localhost:3000"}, cfg.CORS.AllowOrigins)
+		Equal(t, 180*time.Second, cfg.RateLimit.Window())
+		True(t, cfg.IsProduction())
+		True(t, cfg.Cookie.Secure)
+	})
+
+	t.Run("TestPrecedence", func(t *testing.T) {
+		path := writeConfigFile(t, `
+environment: development
+listen_addr: ":9000"
+cors:
+  allow_origins: ["https://shoppinglist.example"]
+rate_limit:
+  requests: 50
+`)
+		setEnv(t, "RATE_LIMIT_REQUESTS", "100")
+
+		cfg, err := Load([]string{"-config", path, "-listen", ":9090"})
+		if err != nil {
+			t.Fatalf("Error while loading config: %s", err)
+		}
+
+		Equal(t, ":9090", cfg.ListenAddr)
+		Equal(t, 100, cfg.RateLimit.Requests)
+		Equal(t, []string{"https://shoppinglist.example"}, cfg.CORS.AllowOrigins)
+		True(t, cfg.IsDevelopment())
+		False(t, cfg.Cookie.Secure)
+	})
+
+	t.Run("TestAccountSettings", func(t *testing.T) {
+		setEnv(t, "TOTP_ENCRYPTION_KEY", "totp")
+		setEnv(t, "REAUTH_ROUTES", "POST /api/v1/auth/update, DELETE /api/v1/item/:id")
+		setEnv(t, "LOGIN_LOCKOUT_BASE_SECONDS", "30")
+
+		cfg, err := Load(nil)
+		if err != nil {
+			t.Fatalf("Error while loading config: %s", err)
+		}
+
+		Equal(t, "totp", cfg.TwoFactor.EncryptionKey)
+		Equal(t, []string{"POST /api/v1/auth/update", "DELETE /api/v1/item/:id"}, cfg.Reauth.Routes)
+		Equal(t, 30*time.Second, cfg.Lockout.BaseWindow())
+		Equal(t, 30*24*time.Hour, cfg.Account.DeletionGracePeriod())
+
+		setEnv(t, "TOTP_ENCRYPTION_KEY", "")
+		setEnv(t, "ENCRYPTION_KEYSTRING", "")
+
+		_, err = Load(nil)
+		Contains(t, err.Error(), "ENCRYPTION_KEYSTRING")
+	})
+
+	t.Run("TestUnknownKey", func(t *testing.T) {
+		path := writeConfigFile(t, "listen: \":9000\"\n")
+
+		_, err := Load([]string{"-config", path})
+		NotNil(t, err)
+	})
+
+	t.Run("TestInvalid", func(t *testing.T) {
+		setEnv(t, "DATABASE_DSN", "")
+		setEnv(t, "CORS_ALLOW_ORIGINS", "localhost:3000")
+		setEnv(t, "RATE_LIMIT_REQUESTS", "many")
+
+		_, err := Load(nil)
+		NotNil(t, err)
+
+		setEnv(t, "RATE_LIMIT_REQUESTS", "")
+
+		_, err = Load(nil)
+		Contains(t, err.Error(), "DATABASE_DSN")
+		Contains(t, err.Error(), "localhost:3000")
+	})
+}
@@ -1,7 +0,0 @@
-package util
-
-const (
-	PROD           = false
-	GITHUB_TESTING = true
-	LOCAL_TESTING  = false
-)
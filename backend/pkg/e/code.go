@@ -57,4 +57,58 @@ const (
 	ERROR_CHECKING_IF_TOTP_IS_ENABLED                       = 20033
 
 	ERROR_CHECKING_HAS_UNREAD_NOTIFICATIONS = 20034
+
+	ERROR_DEACTIVATING_ACCOUNT    = 20035
+	ERROR_REAUTHENTICATION_FAILED = 20036
+
+	ERROR_PASSWORD_TOO_SHORT                 = 20037
+	ERROR_PASSWORD_MISSING_CHARACTER_CLASSES = 20038
+	ERROR_PASSWORD_CONTAINS_EMAIL            = 20039
+	ERROR_PASSWORD_BREACHED                  = 20040
+
+	ERROR_GETTING_HASH_REPORT  = 20041
+	ERROR_GETTING_LOGIN_EVENTS = 20042
+
+	ERROR_TOO_MANY_LOGIN_ATTEMPTS = 20043
+	ERROR_SENDING_UNLOCK_EMAIL    = 20044
+	ERROR_UNLOCK_TOKEN_INVALID    = 20045
+
+	ERROR_BEGINNING_WEBAUTHN_REGISTRATION = 20046
+	ERROR_FINISHING_WEBAUTHN_REGISTRATION = 20047
+	ERROR_BEGINNING_WEBAUTHN_LOGIN        = 20048
+	ERROR_VERIFYING_WEBAUTHN_ASSERTION    = 20049
+	ERROR_GETTING_WEBAUTHN_CREDENTIALS    = 20050
+	ERROR_DELETING_WEBAUTHN_CREDENTIAL    = 20051
+	ERROR_CHECKING_IF_WEBAUTHN_IS_ENABLED = 20052
+
+	ERROR_SENDING_EMAIL_OTP     = 20053
+	ERROR_TOO_MANY_OTP_ATTEMPTS = 20054
+
+	ERROR_REAUTHENTICATION_REQUIRED = 20055
+
+	ERROR_REQUESTING_EMAIL_CHANGE    = 20056
+	ERROR_EMAIL_ALREADY_TAKEN        = 20057
+	ERROR_EMAIL_CHANGE_TOKEN_INVALID = 20058
+	ERROR_CHANGING_EMAIL             = 20059
+
+	ERROR_USER_NOT_FOUND                = 20060
+	ERROR_EMAIL_INVALID                 = 20061
+	ERROR_WRONG_PASSWORD                = 20062
+	ERROR_OFFSET_INVALID                = 20063
+	ERROR_WEBAUTHN_CREDENTIAL_NOT_FOUND = 20064
+
+	ERROR_GETTING_NOTIFICATIONS         = 20065
+	ERROR_DELETING_NOTIFICATION         = 20066
+	ERROR_MARKING_NOTIFICATIONS_AS_READ = 20067
+	ERROR_PARTICIPANT_ALREADY_INCLUDED  = 20068
+	ERROR_ADDING_PARTICIPANT            = 20069
+	ERROR_EDITING_ITEM                  = 20070
+	ERROR_GENERATING_BACKUP_CODES       = 20071
+	ERROR_GETTING_BACKUP_CODES          = 20072
+	ERROR_VERIFYING_BACKUP_CODE         = 20073
+	ERROR_GETTING_PARTICIPANTS          = 20074
+	ERROR_UPDATING_PARTICIPANT          = 20075
+	ERROR_ADDING_ITEM                   = 20076
+	ERROR_DELETING_ITEM                 = 20077
+	ERROR_BACKUP_CODES_ALREADY_EXIST    = 20078
 )
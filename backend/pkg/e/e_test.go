@@ -0,0 +1,77 @@
+package e
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "code.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Error while parsing code.go: %s", err)
+	}
+
+	constants := 0
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+
+		code, err := strconv.Atoi(spec.Values[0].(*ast.BasicLit).Value)
+		if err != nil {
+			t.Fatalf("Error while reading the value of %s: %s", spec.Names[0].Name, err)
+		}
+
+		constants++
+		assert.Equal(t, spec.Names[0].Name, names[code], "code %d is missing in the registry", code)
+		_, ok = MsgFlags[code]
+		assert.True(t, ok, "code %s has no message", spec.Names[0].Name)
+		return false
+	})
+
+	entries := Registry()
+	assert.Equal(t, constants, len(entries))
+	assert.Equal(t, SUCCESS, entries[0].Code)
+	assert.Equal(t, "ok", entries[0].Message)
+
+	data, err := RegistryJSON()
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"name": "ERROR_EMAIL_ALREADY_TAKEN"`)
+}
+
+func TestAppError(t *testing.T) {
+	cause := errors.New("connection refused")
+
+	t.Run("TestWrap", func(t *testing.T) {
+		err := Wrap(cause, http.StatusInternalServerError, ERROR_GET_LIST_FAIL)
+
+		assert.Equal(t, http.StatusInternalServerError, err.Status)
+		assert.Equal(t, GetMsg(ERROR_GET_LIST_FAIL), err.Message)
+		assert.True(t, errors.Is(err, cause))
+		assert.Contains(t, err.Error(), "connection refused")
+	})
+
+	t.Run("TestWrapKeepsAppError", func(t *testing.T) {
+		notFound := New(http.StatusNotFound, ERROR_LIST_DOES_NOT_EXIST)
+		err := Wrap(fmt.Errorf("loading list: %w", notFound), http.StatusInternalServerError, ERROR_GET_LIST_FAIL)
+
+		assert.Equal(t, notFound, err)
+	})
+
+	t.Run("TestWithDetail", func(t *testing.T) {
+		shared := New(http.StatusBadRequest, ERROR_EMAIL_INVALID)
+		err := shared.WithDetail("field", "email")
+
+		assert.Equal(t, "email", err.Details["field"])
+		assert.Nil(t, shared.Details)
+	})
+}
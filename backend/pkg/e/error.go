@@ -0,0 +1,58 @@
+package e
+
+import "errors"
+
+// AppError is an error that knows how it's answered: the http status, the code from this package with its message
+// and details for the client. Err is the cause, it's logged but never sent to the client
+type AppError struct {
+	Status  int
+	Code    int
+	Message string
+	Details map[string]string
+	Err     error
+}
+
+// New returns an AppError with the message of code
+func New(status, code int) *AppError {
+	return &AppError{
+		Status:  status,
+		Code:    code,
+		Message: GetMsg(code),
+	}
+}
+
+// Wrap returns an AppError caused by err. If err already is an AppError, e.g. one returned by the models, it's
+// returned unchanged because it describes the problem better than the caller can
+func Wrap(err error, status, code int) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	wrapped := New(status, code)
+	wrapped.Err = err
+	return wrapped
+}
+
+// WithDetail returns a copy with key set in the details, so shared errors like the ones of the models are never
+// changed
+func (err *AppError) WithDetail(key, value string) *AppError {
+	copied := *err
+	copied.Details = make(map[string]string, len(err.Details)+1)
+	for k, v := range err.Details {
+		copied.Details[k] = v
+	}
+	copied.Details[key] = value
+	return &copied
+}
+
+func (err *AppError) Error() string {
+	if err.Err != nil {
+		return err.Message + ": " + err.Err.Error()
+	}
+	return err.Message
+}
+
+func (err *AppError) Unwrap() error {
+	return err.Err
+}
@@ -8,6 +8,101 @@ var MsgFlags = map[int]string{
 	ERROR_RATELIMIT_TRY_LATER:     "ratelimit reached; try again in 1 minute",
 	ERROR_BINDING_JSON_DATA:       "error while binding json data to struct",
 	ERROR_GETTING_HTTPONLY_COOKIE: "error while getting cookie: cookie not found",
+	ERROR_DEACTIVATING_ACCOUNT:    "error while deactivating account",
+	ERROR_REAUTHENTICATION_FAILED: "re-authentication failed",
+
+	ERROR_LIST_DOES_NOT_EXIST:    "shoppinglist does not exist",
+	ERROR_CHECK_EXIST_LIST_FAIL:  "error while checking if the shoppinglist exists",
+	ERROR_ADD_LIST_FAIL:          "error while creating the shoppinglist",
+	ERROR_DELETE_LIST_FAIL:       "error while deleting the shoppinglist",
+	ERROR_EDIT_LIST_FAIL:         "error while editing the shoppinglist",
+	ERROR_COUNT_LIST_FAIL:        "error while counting the shoppinglists",
+	ERROR_GET_LISTS_FAIL:         "error while loading the shoppinglists",
+	ERROR_GET_LIST_FAIL:          "error while loading the shoppinglist",
+	ERROR_GETTING_LISTS_BY_OWNER: "error while loading your shoppinglists",
+	ERROR_OFFSET_INVALID:         "offset has to be a number",
+
+	ERROR_AUTH_CHECK_TOKEN_FAIL:    "wrong email or password",
+	ERROR_AUTH_CHECK_TOKEN_TIMEOUT: "session expired; please log in again",
+	ERROR_AUTH_TOKEN:               "error while creating the session",
+	ERROR_AUTH:                     "wrong email or password",
+	ERROR_CREATING_ACCOUNT:         "error while creating the account",
+	ERROR_SECRETID_IS_NOT_CORRECT:  "session is not valid anymore",
+	ERROR_UPDATING_USER:            "error while updating the user",
+	ERROR_ENCRYPTING_PASSWORD:      "error while hashing the password",
+	ERROR_WRONG_OLD_PASSWORD:       "old password is wrong",
+	ERROR_WRONG_PASSWORD:           "password is wrong",
+	ERROR_USER_NOT_FOUND:           "user does not exist",
+	ERROR_EMAIL_INVALID:            "email is not valid",
+	ERROR_USERNAME_TOO_LONG:        "username can only be a maximum of 32 characters long",
+
+	ERROR_RATE_LIMITER: "error while checking the ratelimit",
+
+	ERROR_GETTING_EMAIL_BY_JWT:          "error while loading the session",
+	ERROR_RETRIEVING_USER_DATA:          "error while loading the user",
+	ERROR_TOKEN_INVALID:                 "session is not valid",
+	ERROR_WHILE_INVALIDATING_TOKEN:      "error while logging out",
+	ERROR_LIST_DOES_NOT_BELONG_TO_TOKEN: "shoppinglist does not belong to you",
+	ERROR_ID_IS_INVALID:                 "id is not valid",
+
+	ERROR_SENDING_RESET_PASSWORD_EMAIL: "error while sending the reset password email",
+	ERROR_VERIFYING_VERIFICATION_ID:    "reset token is invalid or expired",
+	ERROR_GETTING_IP:                   "error while getting the ip address",
+	ERROR_SETTING_SESSION_TOKEN:        "error while setting the session cookie",
+
+	ERROR_CHANING_TWOFACTORAUTHENTICATION_STATUS:            "error while changing the two factor authentication",
+	ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE: "error while loading the two factor authentication status",
+	ERROR_INVALIDATING_JWT_TOKENS:                           "error while logging out the other sessions",
+	ERROR_JWT_TOKEN_DOES_NOT_BELONG_TO_EMAIL:                "session does not belong to this account",
+	ERROR_CONVERTING_KEY_TO_IMG:                             "error while creating the qr code",
+	ERROR_VERIFYING_OTP:                                     "code is wrong",
+	ERROR_CHECKING_IF_TOTP_IS_ENABLED:                       "error while checking the two factor authentication",
+
+	ERROR_CHECKING_HAS_UNREAD_NOTIFICATIONS: "error while loading the notifications",
+
+	ERROR_GETTING_HASH_REPORT:  "error while creating the password hash report",
+	ERROR_GETTING_LOGIN_EVENTS: "error while loading the login history",
+
+	ERROR_PASSWORD_TOO_SHORT:                 "password is too short",
+	ERROR_PASSWORD_MISSING_CHARACTER_CLASSES: "password does not contain enough character classes",
+	ERROR_PASSWORD_CONTAINS_EMAIL:            "password contains the email",
+	ERROR_PASSWORD_BREACHED:                  "password was found in a data breach",
+
+	ERROR_TOO_MANY_LOGIN_ATTEMPTS: "too many failed login attempts; try again later",
+	ERROR_UNLOCK_TOKEN_INVALID:    "unlock token is invalid or expired",
+	ERROR_SENDING_UNLOCK_EMAIL:    "error while sending the unlock email",
+
+	ERROR_BEGINNING_WEBAUTHN_REGISTRATION: "error while starting the security key registration",
+	ERROR_FINISHING_WEBAUTHN_REGISTRATION: "error while registering the security key",
+	ERROR_BEGINNING_WEBAUTHN_LOGIN:        "error while starting the security key login",
+	ERROR_VERIFYING_WEBAUTHN_ASSERTION:    "security key could not be verified",
+	ERROR_GETTING_WEBAUTHN_CREDENTIALS:    "error while loading the security keys",
+	ERROR_DELETING_WEBAUTHN_CREDENTIAL:    "error while deleting the security key",
+	ERROR_CHECKING_IF_WEBAUTHN_IS_ENABLED: "error while checking the security keys",
+	ERROR_WEBAUTHN_CREDENTIAL_NOT_FOUND:   "security key does not exist",
+
+	ERROR_SENDING_EMAIL_OTP:         "error while sending the code",
+	ERROR_TOO_MANY_OTP_ATTEMPTS:     "too many wrong codes; request a new one",
+	ERROR_REAUTHENTICATION_REQUIRED: "please enter your password again to continue",
+
+	ERROR_REQUESTING_EMAIL_CHANGE:       "error while requesting the email change",
+	ERROR_EMAIL_ALREADY_TAKEN:           "email is already used by another account",
+	ERROR_EMAIL_CHANGE_TOKEN_INVALID:    "email change link is invalid or expired",
+	ERROR_CHANGING_EMAIL:                "error while changing the email",
+	ERROR_GETTING_NOTIFICATIONS:         "error while getting the notifications",
+	ERROR_DELETING_NOTIFICATION:         "error while deleting the notification",
+	ERROR_MARKING_NOTIFICATIONS_AS_READ: "error while marking the notifications as read",
+	ERROR_PARTICIPANT_ALREADY_INCLUDED:  "participant is already included in the list",
+	ERROR_ADDING_PARTICIPANT:            "error while adding the participant",
+	ERROR_EDITING_ITEM:                  "error while editing the item",
+	ERROR_GENERATING_BACKUP_CODES:       "error while generating the backup codes",
+	ERROR_GETTING_BACKUP_CODES:          "error while getting the backup codes",
+	ERROR_VERIFYING_BACKUP_CODE:         "error while verifying the backup code",
+	ERROR_GETTING_PARTICIPANTS:          "error while getting the participants",
+	ERROR_UPDATING_PARTICIPANT:          "error while updating the participant",
+	ERROR_ADDING_ITEM:                   "error while adding the item",
+	ERROR_DELETING_ITEM:                 "error while deleting the item",
+	ERROR_BACKUP_CODES_ALREADY_EXIST:    "the user still has unused backup codes",
 }
 
 func GetMsg(code int) string {
@@ -0,0 +1,145 @@
+package e
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Entry is a code of the registry, the frontend uses the name to look up its translation and falls back to the
+// message
+type Entry struct {
+	Code    int    `json:"code"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// names has to contain every code, the test checks it against code.go
+var names = map[int]string{
+	SUCCESS:        "SUCCESS",
+	ERROR:          "ERROR",
+	INVALID_PARAMS: "INVALID_PARAMS",
+
+	ERROR_LIST_DOES_NOT_EXIST:    "ERROR_LIST_DOES_NOT_EXIST",
+	ERROR_CHECK_EXIST_LIST_FAIL:  "ERROR_CHECK_EXIST_LIST_FAIL",
+	ERROR_ADD_LIST_FAIL:          "ERROR_ADD_LIST_FAIL",
+	ERROR_DELETE_LIST_FAIL:       "ERROR_DELETE_LIST_FAIL",
+	ERROR_EDIT_LIST_FAIL:         "ERROR_EDIT_LIST_FAIL",
+	ERROR_COUNT_LIST_FAIL:        "ERROR_COUNT_LIST_FAIL",
+	ERROR_GET_LISTS_FAIL:         "ERROR_GET_LISTS_FAIL",
+	ERROR_GET_LIST_FAIL:          "ERROR_GET_LIST_FAIL",
+	ERROR_GETTING_LISTS_BY_OWNER: "ERROR_GETTING_LISTS_BY_OWNER",
+
+	ERROR_AUTH_CHECK_TOKEN_FAIL:    "ERROR_AUTH_CHECK_TOKEN_FAIL",
+	ERROR_AUTH_CHECK_TOKEN_TIMEOUT: "ERROR_AUTH_CHECK_TOKEN_TIMEOUT",
+	ERROR_AUTH_TOKEN:               "ERROR_AUTH_TOKEN",
+	ERROR_AUTH:                     "ERROR_AUTH",
+	ERROR_CREATING_ACCOUNT:         "ERROR_CREATING_ACCOUNT",
+	ERROR_SECRETID_IS_NOT_CORRECT:  "ERROR_SECRETID_IS_NOT_CORRECT",
+	ERROR_UPDATING_USER:            "ERROR_UPDATING_USER",
+	ERROR_ENCRYPTING_PASSWORD:      "ERROR_ENCRYPTING_PASSWORD",
+	ERROR_WRONG_OLD_PASSWORD:       "ERROR_WRONG_OLD_PASSWORD",
+
+	ERROR_RATE_LIMITER:        "ERROR_RATE_LIMITER",
+	ERROR_RATELIMIT_TRY_LATER: "ERROR_RATELIMIT_TRY_LATER",
+
+	ERROR_GETTING_EMAIL_BY_JWT:     "ERROR_GETTING_EMAIL_BY_JWT",
+	ERROR_RETRIEVING_USER_DATA:     "ERROR_RETRIEVING_USER_DATA",
+	ERROR_TOKEN_INVALID:            "ERROR_TOKEN_INVALID",
+	ERROR_WHILE_INVALIDATING_TOKEN: "ERROR_WHILE_INVALIDATING_TOKEN",
+
+	ERROR_LIST_DOES_NOT_BELONG_TO_TOKEN: "ERROR_LIST_DOES_NOT_BELONG_TO_TOKEN",
+	ERROR_ID_IS_INVALID:                 "ERROR_ID_IS_INVALID",
+
+	ERROR_NOT_AUTHORIZED:    "ERROR_NOT_AUTHORIZED",
+	ERROR_BINDING_JSON_DATA: "ERROR_BINDING_JSON_DATA",
+
+	ERROR_SENDING_RESET_PASSWORD_EMAIL: "ERROR_SENDING_RESET_PASSWORD_EMAIL",
+	ERROR_VERIFYING_VERIFICATION_ID:    "ERROR_VERIFYING_VERIFICATION_ID",
+
+	ERROR_GETTING_IP:              "ERROR_GETTING_IP",
+	ERROR_GETTING_HTTPONLY_COOKIE: "ERROR_GETTING_HTTPONLY_COOKIE",
+	ERROR_SETTING_SESSION_TOKEN:   "ERROR_SETTING_SESSION_TOKEN",
+
+	ERROR_USERNAME_TOO_LONG: "ERROR_USERNAME_TOO_LONG",
+
+	ERROR_CHANING_TWOFACTORAUTHENTICATION_STATUS:            "ERROR_CHANING_TWOFACTORAUTHENTICATION_STATUS",
+	ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE: "ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE",
+	ERROR_INVALIDATING_JWT_TOKENS:                           "ERROR_INVALIDATING_JWT_TOKENS",
+	ERROR_JWT_TOKEN_DOES_NOT_BELONG_TO_EMAIL:                "ERROR_JWT_TOKEN_DOES_NOT_BELONG_TO_EMAIL",
+	ERROR_CONVERTING_KEY_TO_IMG:                             "ERROR_CONVERTING_KEY_TO_IMG",
+	ERROR_VERIFYING_OTP:                                     "ERROR_VERIFYING_OTP",
+	ERROR_CHECKING_IF_TOTP_IS_ENABLED:                       "ERROR_CHECKING_IF_TOTP_IS_ENABLED",
+
+	ERROR_CHECKING_HAS_UNREAD_NOTIFICATIONS: "ERROR_CHECKING_HAS_UNREAD_NOTIFICATIONS",
+
+	ERROR_DEACTIVATING_ACCOUNT:    "ERROR_DEACTIVATING_ACCOUNT",
+	ERROR_REAUTHENTICATION_FAILED: "ERROR_REAUTHENTICATION_FAILED",
+
+	ERROR_PASSWORD_TOO_SHORT:                 "ERROR_PASSWORD_TOO_SHORT",
+	ERROR_PASSWORD_MISSING_CHARACTER_CLASSES: "ERROR_PASSWORD_MISSING_CHARACTER_CLASSES",
+	ERROR_PASSWORD_CONTAINS_EMAIL:            "ERROR_PASSWORD_CONTAINS_EMAIL",
+	ERROR_PASSWORD_BREACHED:                  "ERROR_PASSWORD_BREACHED",
+
+	ERROR_GETTING_HASH_REPORT:  "ERROR_GETTING_HASH_REPORT",
+	ERROR_GETTING_LOGIN_EVENTS: "ERROR_GETTING_LOGIN_EVENTS",
+
+	ERROR_TOO_MANY_LOGIN_ATTEMPTS: "ERROR_TOO_MANY_LOGIN_ATTEMPTS",
+	ERROR_SENDING_UNLOCK_EMAIL:    "ERROR_SENDING_UNLOCK_EMAIL",
+	ERROR_UNLOCK_TOKEN_INVALID:    "ERROR_UNLOCK_TOKEN_INVALID",
+
+	ERROR_BEGINNING_WEBAUTHN_REGISTRATION: "ERROR_BEGINNING_WEBAUTHN_REGISTRATION",
+	ERROR_FINISHING_WEBAUTHN_REGISTRATION: "ERROR_FINISHING_WEBAUTHN_REGISTRATION",
+	ERROR_BEGINNING_WEBAUTHN_LOGIN:        "ERROR_BEGINNING_WEBAUTHN_LOGIN",
+	ERROR_VERIFYING_WEBAUTHN_ASSERTION:    "ERROR_VERIFYING_WEBAUTHN_ASSERTION",
+	ERROR_GETTING_WEBAUTHN_CREDENTIALS:    "ERROR_GETTING_WEBAUTHN_CREDENTIALS",
+	ERROR_DELETING_WEBAUTHN_CREDENTIAL:    "ERROR_DELETING_WEBAUTHN_CREDENTIAL",
+	ERROR_CHECKING_IF_WEBAUTHN_IS_ENABLED: "ERROR_CHECKING_IF_WEBAUTHN_IS_ENABLED",
+
+	ERROR_SENDING_EMAIL_OTP:     "ERROR_SENDING_EMAIL_OTP",
+	ERROR_TOO_MANY_OTP_ATTEMPTS: "ERROR_TOO_MANY_OTP_ATTEMPTS",
+
+	ERROR_REAUTHENTICATION_REQUIRED: "ERROR_REAUTHENTICATION_REQUIRED",
+
+	ERROR_REQUESTING_EMAIL_CHANGE:    "ERROR_REQUESTING_EMAIL_CHANGE",
+	ERROR_EMAIL_ALREADY_TAKEN:        "ERROR_EMAIL_ALREADY_TAKEN",
+	ERROR_EMAIL_CHANGE_TOKEN_INVALID: "ERROR_EMAIL_CHANGE_TOKEN_INVALID",
+	ERROR_CHANGING_EMAIL:             "ERROR_CHANGING_EMAIL",
+
+	ERROR_USER_NOT_FOUND:                "ERROR_USER_NOT_FOUND",
+	ERROR_EMAIL_INVALID:                 "ERROR_EMAIL_INVALID",
+	ERROR_WRONG_PASSWORD:                "ERROR_WRONG_PASSWORD",
+	ERROR_OFFSET_INVALID:                "ERROR_OFFSET_INVALID",
+	ERROR_WEBAUTHN_CREDENTIAL_NOT_FOUND: "ERROR_WEBAUTHN_CREDENTIAL_NOT_FOUND",
+	ERROR_GETTING_NOTIFICATIONS:         "ERROR_GETTING_NOTIFICATIONS",
+	ERROR_DELETING_NOTIFICATION:         "ERROR_DELETING_NOTIFICATION",
+	ERROR_MARKING_NOTIFICATIONS_AS_READ: "ERROR_MARKING_NOTIFICATIONS_AS_READ",
+	ERROR_PARTICIPANT_ALREADY_INCLUDED:  "ERROR_PARTICIPANT_ALREADY_INCLUDED",
+	ERROR_ADDING_PARTICIPANT:            "ERROR_ADDING_PARTICIPANT",
+	ERROR_EDITING_ITEM:                  "ERROR_EDITING_ITEM",
+	ERROR_GENERATING_BACKUP_CODES:       "ERROR_GENERATING_BACKUP_CODES",
+	ERROR_GETTING_BACKUP_CODES:          "ERROR_GETTING_BACKUP_CODES",
+	ERROR_VERIFYING_BACKUP_CODE:         "ERROR_VERIFYING_BACKUP_CODE",
+	ERROR_GETTING_PARTICIPANTS:          "ERROR_GETTING_PARTICIPANTS",
+	ERROR_UPDATING_PARTICIPANT:          "ERROR_UPDATING_PARTICIPANT",
+	ERROR_ADDING_ITEM:                   "ERROR_ADDING_ITEM",
+	ERROR_DELETING_ITEM:                 "ERROR_DELETING_ITEM",
+	ERROR_BACKUP_CODES_ALREADY_EXIST:    "ERROR_BACKUP_CODES_ALREADY_EXIST",
+}
+
+// Registry returns every code sorted by its value
+func Registry() []Entry {
+	entries := make([]Entry, 0, len(names))
+	for code, name := range names {
+		entries = append(entries, Entry{Code: code, Name: name, Message: GetMsg(code)})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Code < entries[j].Code
+	})
+	return entries
+}
+
+// RegistryJSON is the registry as served on /api/errors and written by cmd/errorcodes
+func RegistryJSON() ([]byte, error) {
+	return json.MarshalIndent(Registry(), "", "  ")
+}
@@ -0,0 +1,126 @@
+package emailotp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/mailer"
+)
+
+var ErrTooManyAttempts = errors.New("too many wrong codes, request a new one")
+
+var (
+	codeTTL       = 10 * time.Minute
+	maxAttempts   = 5
+	attemptWindow = 15 * time.Minute
+)
+
+func Setup(cfg *config.Config) {
+	codeTTL = cfg.TwoFactor.EmailOTPTTL()
+	maxAttempts = cfg.TwoFactor.EmailOTPMaxAttempts
+	attemptWindow = cfg.TwoFactor.EmailOTPAttemptWindow()
+}
+
+// Send generates a new 6-digit code, stores its hash and emails the code to the user.
+// A previously sent code becomes invalid
+func Send(ctx context.Context, email string) error {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return err
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	err = cache.CacheEmailOTP(ctx, email, hash(email, code), codeTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Your login code is %s\n\nIt expires in %d minutes. If you didn't try to log in, change your password.", code, int(codeTTL.Minutes()))
+	return mailer.Send(email, "Your login code", body)
+}
+
+// Verify checks the code and deletes it once it was used or once too many wrong codes were entered. The attempts are
+// counted per user, so requesting new codes doesn't allow more guesses
+func Verify(ctx context.Context, email, code string) (bool, error) {
+	stored, err := cache.GetEmailOTP(ctx, email)
+	if err != nil {
+		return false, err
+	}
+
+	attempts, err := cache.IncrEmailOTPAttempts(ctx, email, attemptWindow)
+	if err != nil {
+		return false, err
+	}
+
+	if attempts > int64(maxAttempts) {
+		if err := cache.DeleteEmailOTP(ctx, email); err != nil {
+			return false, err
+		}
+		return false, ErrTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(hash(email, code))) != 1 {
+		return false, nil
+	}
+
+	if err := cache.DeleteEmailOTP(ctx, email); err != nil {
+		return false, err
+	}
+
+	return true, cache.ClearEmailOTPAttempts(ctx, email)
+}
+
+// Confirm switches the user to email codes once the first code was entered and returns newly generated backup codes
+func Confirm(ctx context.Context, email, code string) (pq.StringArray, error) {
+	ok, err := Verify(ctx, email, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, errors.New("otp is not valid")
+	}
+
+	err = models.SetTwoFactorMethod(ctx, email, models.TwoFactorMethodEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	err = models.SetTwoFactorAuthentication(ctx, email, true)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := models.GetUserIDByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.GenerateCodes(ctx, email, userId, true, true)
+}
+
+// Disable turns 2FA off and switches back to the default method
+func Disable(ctx context.Context, email string) error {
+	err := models.SetTwoFactorAuthentication(ctx, email, false)
+	if err != nil {
+		return err
+	}
+
+	return models.SetTwoFactorMethod(ctx, email, models.TwoFactorMethodTOTP)
+}
+
+func hash(email, code string) string {
+	sum := sha256.Sum256([]byte(email + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,187 @@
+package emailotp
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/mailer"
+	"github.com/urento/shoppinglist/pkg/util"
+)
+
+type testMailer struct {
+	to   string
+	body string
+}
+
+func (m *testMailer) Send(to, subject, body string) error {
+	m.to = to
+	m.body = body
+	return nil
+}
+
+func (m *testMailer) code() string {
+	return regexp.MustCompile(`\d{6}`).FindString(m.body)
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	cache.Setup(cfg)
+	Setup(cfg)
+
+	m := &testMailer{}
+	mailer.SetMailer(m)
+
+	email := util.RandomEmail()
+
+	err := Send(ctx, email)
+	if err != nil {
+		t.Errorf("Error while sending code: %s", err)
+	}
+
+	code := m.code()
+
+	t.Run("Code was sent to the user", func(t *testing.T) {
+		Equal(t, email, m.to)
+		Equal(t, 6, len(code))
+	})
+
+	t.Run("Code is stored hashed", func(t *testing.T) {
+		stored, err := cache.GetEmailOTP(ctx, email)
+
+		Nil(t, err)
+		NotEqual(t, code, stored)
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		ok, err := Verify(ctx, email, "abcdef")
+
+		Nil(t, err)
+		False(t, ok)
+	})
+
+	t.Run("Correct code", func(t *testing.T) {
+		ok, err := Verify(ctx, email, code)
+
+		Nil(t, err)
+		True(t, ok)
+	})
+
+	t.Run("Code can only be used once", func(t *testing.T) {
+		ok, err := Verify(ctx, email, code)
+
+		NotNil(t, err)
+		False(t, ok)
+	})
+}
+
+func TestVerifyTooManyAttempts(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	cache.Setup(cfg)
+	Setup(cfg)
+
+	m := &testMailer{}
+	mailer.SetMailer(m)
+
+	email := util.RandomEmail()
+
+	err := Send(ctx, email)
+	if err != nil {
+		t.Errorf("Error while sending code: %s", err)
+	}
+
+	for i := 0; i < maxAttempts; i++ {
+		_, err := Verify(ctx, email, "abcdef")
+		if err != nil {
+			t.Errorf("Error while verifying code: %s", err)
+		}
+	}
+
+	ok, err := Verify(ctx, email, m.code())
+
+	Equal(t, ErrTooManyAttempts, err)
+	False(t, ok)
+
+	t.Run("New code doesn't reset the attempts", func(t *testing.T) {
+		err := Send(ctx, email)
+		if err != nil {
+			t.Errorf("Error while sending code: %s", err)
+		}
+
+		ok, err := Verify(ctx, email, m.code())
+
+		Equal(t, ErrTooManyAttempts, err)
+		False(t, ok)
+	})
+}
+
+func TestConfirm(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	models.Setup(cfg)
+	util.Setup(cfg)
+	cache.Setup(cfg)
+	Setup(cfg)
+
+	m := &testMailer{}
+	mailer.SetMailer(m)
+
+	email := util.RandomEmail()
+	err := models.CreateAccount(ctx, email, util.StringWithCharset(20), util.StringWithCharset(20), "")
+	if err != nil {
+		t.Errorf("Error while creating account: %s", err)
+	}
+
+	err = Send(ctx, email)
+	if err != nil {
+		t.Errorf("Error while sending code: %s", err)
+	}
+
+	codes, err := Confirm(ctx, email, m.code())
+	if err != nil {
+		t.Errorf("Error while confirming email otp: %s", err)
+	}
+
+	enabled, err := models.IsTwoFactorEnabled(ctx, email)
+	if err != nil {
+		t.Errorf("Error while checking if 2fa is enabled: %s", err)
+	}
+
+	method, err := models.GetTwoFactorMethod(ctx, email)
+	if err != nil {
+		t.Errorf("Error while getting the 2fa method: %s", err)
+	}
+
+	Equal(t, 6, len(codes))
+	True(t, enabled)
+	Equal(t, models.TwoFactorMethodEmail, method)
+
+	t.Run("Disable", func(t *testing.T) {
+		err := Disable(ctx, email)
+		if err != nil {
+			t.Errorf("Error while disabling email otp: %s", err)
+		}
+
+		enabled, err := models.IsTwoFactorEnabled(ctx, email)
+		if err != nil {
+			t.Errorf("Error while checking if 2fa is enabled: %s", err)
+		}
+
+		method, err := models.GetTwoFactorMethod(ctx, email)
+		if err != nil {
+			t.Errorf("Error while getting the 2fa method: %s", err)
+		}
+
+		False(t, enabled)
+		Equal(t, models.TwoFactorMethodTOTP, method)
+	})
+}
@@ -0,0 +1,111 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+// masterKey encrypts the per-record data keys; it never encrypts data directly
+var masterKey []byte
+
+func Setup(cfg *config.Config) error {
+	key := cfg.TwoFactor.EncryptionKey
+	if len(key) <= 0 {
+		return errors.New("no encryption key configured")
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	masterKey = sum[:]
+	return nil
+}
+
+// Encrypt encrypts the plaintext with a new random data key and returns the data key encrypted with the master key
+func Encrypt(plaintext []byte) (string, string, error) {
+	if masterKey == nil {
+		return "", "", errors.New("envelope encryption is not set up")
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", "", err
+	}
+
+	encryptedKey, err := seal(masterKey, dataKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	ciphertext, err := seal(dataKey, plaintext)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(encryptedKey), base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func Decrypt(encryptedKey, ciphertext string) ([]byte, error) {
+	if masterKey == nil {
+		return nil, errors.New("envelope encryption is not set up")
+	}
+
+	k, err := base64.StdEncoding.DecodeString(encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := open(masterKey, k)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(dataKey, c)
+}
+
+// seal encrypts with AES-GCM and prepends the nonce
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
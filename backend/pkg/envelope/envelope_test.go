@@ -0,0 +1,50 @@
+package envelope
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+func TestEncryptAndDecrypt(t *testing.T) {
+	cfg := config.Default()
+	cfg.TwoFactor.EncryptionKey = "sdkjfhgbsdkjfgbsdkjfbgsdkjfg"
+	err := Setup(cfg)
+	if err != nil {
+		t.Errorf("Error while setting up envelope encryption: %s", err)
+	}
+
+	t.Run("Encrypt and Decrypt", func(t *testing.T) {
+		plaintext := []byte("JBSWY3DPEHPK3PXP")
+
+		encryptedKey, ciphertext, err := Encrypt(plaintext)
+		if err != nil {
+			t.Errorf("Error while encrypting: %s", err)
+		}
+
+		decrypted, err := Decrypt(encryptedKey, ciphertext)
+		if err != nil {
+			t.Errorf("Error while decrypting: %s", err)
+		}
+
+		Equal(t, plaintext, decrypted)
+		NotEqual(t, string(plaintext), ciphertext)
+	})
+
+	t.Run("Decrypt with wrong data key", func(t *testing.T) {
+		encryptedKey, _, err := Encrypt([]byte("first"))
+		if err != nil {
+			t.Errorf("Error while encrypting: %s", err)
+		}
+
+		_, ciphertext, err := Encrypt([]byte("second"))
+		if err != nil {
+			t.Errorf("Error while encrypting: %s", err)
+		}
+
+		_, err = Decrypt(encryptedKey, ciphertext)
+
+		NotNil(t, err)
+	})
+}
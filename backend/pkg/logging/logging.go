@@ -0,0 +1,104 @@
+// Package logging holds the structured logger of the server. Every request gets its own logger that carries the
+// request id, the route and the hashed email of the user, handlers get it with FromGin
+package logging
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/pkg/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type loggerKey struct{}
+
+var logger = newDefault()
+
+func newDefault() *zap.Logger {
+	l, err := zap.NewDevelopment()
+	if err != nil {
+		return zap.NewNop()
+	}
+	return l
+}
+
+// Setup replaces the default logger, which logs everything in the console format, with the configured one
+func Setup(cfg *config.Config) error {
+	var level zapcore.Level
+	err := level.UnmarshalText([]byte(cfg.Log.Level))
+	if err != nil {
+		return err
+	}
+
+	zapConfig := zap.NewProductionConfig()
+	if cfg.Log.Format == "console" {
+		zapConfig = zap.NewDevelopmentConfig()
+	}
+	zapConfig.Level = zap.NewAtomicLevelAt(level)
+
+	l, err := zapConfig.Build()
+	if err != nil {
+		return err
+	}
+
+	logger = l
+	return nil
+}
+
+// L is the logger for everything that doesn't belong to a request
+func L() *zap.Logger {
+	return logger
+}
+
+// Sync flushes the buffered entries, it's called before the server exits
+func Sync() {
+	_ = logger.Sync()
+}
+
+// WithContext stores the logger of a request in its context
+func WithContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext is the logger of the request the context belongs to, or L outside of requests
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+			return l
+		}
+	}
+	return logger
+}
+
+func FromGin(c *gin.Context) *zap.Logger {
+	return FromContext(c.Request.Context())
+}
+
+// AddFields adds fields to the logger of the request, e.g. the user once the session is verified
+func AddFields(c *gin.Context, fields ...zap.Field) {
+	l := FromGin(c).With(fields...)
+	c.Request = c.Request.WithContext(WithContext(c.Request.Context(), l))
+}
+
+// Error logs err with the fields of the request, nil errors are ignored
+func Error(c *gin.Context, err error) {
+	if err == nil {
+		return
+	}
+	FromGin(c).Error(err.Error(), zap.String("route", c.FullPath()))
+}
+
+// HashEmail identifies a user in the logs without writing the email address
+func HashEmail(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// User is the field for the user of a request
+func User(email string) zap.Field {
+	return zap.String("user", HashEmail(email))
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+)
+
+func observe(t *testing.T, level zapcore.Level) *observer.ObservedLogs {
+	core, logs := observer.New(level)
+
+	old := logger
+	logger = zap.New(core)
+	t.Cleanup(func() {
+		logger = old
+	})
+
+	return logs
+}
+
+func TestHashEmail(t *testing.T) {
+	assert.Equal(t, HashEmail("User@gmail.com"), HashEmail("user@gmail.com"))
+	assert.NotEqual(t, HashEmail("user@gmail.com"), HashEmail("other@gmail.com"))
+	assert.Equal(t, 16, len(HashEmail("user@gmail.com")))
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := observe(t, zapcore.InfoLevel)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		AddFields(c, zap.String("request_id", "1234"))
+	})
+	r.Use(Middleware())
+	r.GET("/list/:id", func(c *gin.Context) {
+		AddFields(c, User("user@gmail.com"))
+		Error(c, errors.New("list not found"))
+		Error(c, nil)
+		c.Status(http.StatusNotFound)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/list/1", nil))
+
+	entries := logs.AllUntimed()
+	assert.Equal(t, 2, len(entries))
+
+	assert.Equal(t, "list not found", entries[0].Message)
+	assert.Equal(t, "/list/:id", entries[0].ContextMap()["route"])
+	assert.Equal(t, "1234", entries[0].ContextMap()["request_id"])
+	assert.Equal(t, HashEmail("user@gmail.com"), entries[0].ContextMap()["user"])
+
+	assert.Equal(t, "request", entries[1].Message)
+	assert.Equal(t, int64(http.StatusNotFound), entries[1].ContextMap()["status"])
+	assert.Equal(t, "1234", entries[1].ContextMap()["request_id"])
+}
+
+func TestGormLogger(t *testing.T) {
+	logs := observe(t, zapcore.InfoLevel)
+	l := NewGormLogger(100 * time.Millisecond)
+	query := func() (string, int64) { return "SELECT 1", 1 }
+
+	l.Trace(context.Background(), time.Now(), query, nil)
+	l.Trace(context.Background(), time.Now(), query, gorm.ErrRecordNotFound)
+	assert.Equal(t, 0, logs.Len())
+
+	l.Trace(context.Background(), time.Now().Add(-time.Second), query, nil)
+	l.Trace(context.Background(), time.Now(), query, errors.New("syntax error"))
+
+	entries := logs.TakeAll()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "slow query", entries[0].Message)
+	assert.Equal(t, "query failed", entries[1].Message)
+}
@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Middleware logs every request once it's done, server errors as errors and everything else as info
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := zapcore.InfoLevel
+		if status >= 500 {
+			level = zapcore.ErrorLevel
+		}
+
+		if entry := FromGin(c).Check(level, "request"); entry != nil {
+			entry.Write(
+				zap.String("method", c.Request.Method),
+				zap.String("route", c.FullPath()),
+				zap.String("path", c.Request.URL.Path),
+				zap.Int("status", status),
+				zap.Duration("latency", time.Since(start)),
+				zap.String("ip", c.ClientIP()),
+				zap.Int("size", c.Writer.Size()),
+			)
+		}
+	}
+}
+
+// Recovery logs panics with the fields of the request and responds with 500
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		FromGin(c).Error("panic", zap.Any("panic", recovered), zap.String("route", c.FullPath()), zap.Stack("stack"))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
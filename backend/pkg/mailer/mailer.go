@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// Mailer delivers emails to users; the implementation can be swapped with SetMailer
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+var current Mailer = &LogMailer{}
+
+// Setup uses SMTP if a host is configured. Only logging the emails is refused in production, the logs would contain
+// login codes and reset links
+func Setup(cfg *config.Config) error {
+	if len(cfg.Mail.SMTPHost) <= 0 {
+		if !cfg.IsDevelopment() {
+			return errors.New("smtp host is empty (SMTP_HOST), emails can only be logged instead in development")
+		}
+
+		current = &LogMailer{}
+		return nil
+	}
+
+	current = &SMTPMailer{
+		Host:     cfg.Mail.SMTPHost,
+		Port:     strconv.Itoa(cfg.Mail.SMTPPort),
+		Username: cfg.Mail.SMTPUsername,
+		Password: cfg.Mail.SMTPPassword,
+		From:     cfg.Mail.From,
+	}
+	return nil
+}
+
+func SetMailer(m Mailer) {
+	current = m
+}
+
+func Send(to, subject, body string) error {
+	return current.Send(to, subject, body)
+}
+
+// LogMailer only logs the emails; it's used in development when no smtp server is configured and in tests
+type LogMailer struct{}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	logging.L().Info("email", zap.String("to", to), zap.String("subject", subject), zap.String("body", body))
+	return nil
+}
+
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return errors.New("invalid header value")
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s", m.From, to, subject, body)
+
+	var auth smtp.Auth
+	if len(m.Username) > 0 {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{to}, []byte(msg))
+}
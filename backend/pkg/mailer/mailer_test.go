@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+func TestSetup(t *testing.T) {
+	t.Cleanup(func() {
+		SetMailer(&LogMailer{})
+	})
+
+	t.Run("TestLogMailerInProduction", func(t *testing.T) {
+		cfg := config.Default()
+
+		NotNil(t, Setup(cfg))
+	})
+
+	t.Run("TestLogMailerInDevelopment", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Environment = config.Development
+
+		Nil(t, Setup(cfg))
+		IsType(t, &LogMailer{}, current)
+	})
+
+	t.Run("TestSMTP", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Mail.SMTPHost = "smtp.example.com"
+		cfg.Mail.From = "shoppinglist@example.com"
+
+		Nil(t, Setup(cfg))
+		Equal(t, &SMTPMailer{Host: "smtp.example.com", Port: "587", From: "shoppinglist@example.com"}, current)
+	})
+}
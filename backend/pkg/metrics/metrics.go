@@ -0,0 +1,121 @@
+// Package metrics collects the prometheus metrics of the server, they are exposed on /metrics
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "shoppinglist"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Handled http requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the http requests by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	rateLimitRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ratelimit_rejections_total",
+		Help:      "Requests rejected by the rate limiter.",
+	})
+
+	logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by method and result.",
+	}, []string{"method", "result"})
+
+	twoFactorVerifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "twofactor_verifications_total",
+		Help:      "Verified second factors by method and result.",
+	}, []string{"method", "result"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of the gorm queries by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	redisCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Duration of the redis commands, pipelines are observed as a whole.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5},
+	}, []string{"command"})
+
+	shoppinglists = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "shoppinglists",
+		Help:      "Total number of shoppinglists.",
+	})
+
+	users = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "users",
+		Help:      "Total number of users, including deactivated ones.",
+	})
+)
+
+// Handler serves the metrics in the prometheus text format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// Middleware observes every request by the route pattern, so /list/1 and /list/2 end up in the same series
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if len(route) <= 0 {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+func ObserveRateLimitRejection() {
+	rateLimitRejections.Inc()
+}
+
+func ObserveLogin(method string, success bool) {
+	logins.WithLabelValues(method, result(success)).Inc()
+}
+
+func ObserveTwoFactorVerification(method string, success bool) {
+	twoFactorVerifications.WithLabelValues(method, result(success)).Inc()
+}
+
+// SetTotals updates the domain gauges, they are refreshed periodically because counting is too expensive per scrape
+func SetTotals(totalShoppinglists, totalUsers int64) {
+	shoppinglists.Set(float64(totalShoppinglists))
+	users.Set(float64(totalUsers))
+}
+
+func result(success bool) string {
+	if success {
+		return "success"
+	}
+	return "failure"
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/list/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+	r.GET("/metrics", Handler())
+
+	for _, path := range []string{"/list/1", "/list/2", "/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	Equal(t, float64(2), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/list/:id", "200")))
+	Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "unmatched", "404")))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	Equal(t, http.StatusOK, w.Code)
+	True(t, strings.Contains(w.Body.String(), `shoppinglist_http_requests_total{method="GET",route="/list/:id",status="200"} 2`))
+}
+
+func TestCounters(t *testing.T) {
+	ObserveLogin("password", true)
+	ObserveLogin("password", false)
+	ObserveLogin("password", false)
+	ObserveTwoFactorVerification("totp", true)
+	SetTotals(3, 2)
+
+	Equal(t, float64(1), testutil.ToFloat64(logins.WithLabelValues("password", "success")))
+	Equal(t, float64(2), testutil.ToFloat64(logins.WithLabelValues("password", "failure")))
+	Equal(t, float64(1), testutil.ToFloat64(twoFactorVerifications.WithLabelValues("totp", "success")))
+	Equal(t, float64(3), testutil.ToFloat64(shoppinglists))
+	Equal(t, float64(2), testutil.ToFloat64(users))
+}
+
+func TestRedisHook(t *testing.T) {
+	hook := RedisHook{}
+	cmd := redis.NewStringCmd(context.Background(), "get", "key")
+
+	ctx, err := hook.BeforeProcess(context.Background(), cmd)
+	Nil(t, err)
+	Nil(t, hook.AfterProcess(ctx, cmd))
+
+	Equal(t, 1, testutil.CollectAndCount(redisCommandDuration))
+}
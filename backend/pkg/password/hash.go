@@ -0,0 +1,51 @@
+package password
+
+import (
+	"fmt"
+
+	"github.com/alexedwards/argon2id"
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+var hashParams = argon2id.DefaultParams
+
+func setupHashParams(cfg config.Argon2) {
+	hashParams = &argon2id.Params{
+		Memory:      uint32(cfg.Memory),
+		Iterations:  uint32(cfg.Iterations),
+		Parallelism: uint8(cfg.Parallelism),
+		SaltLength:  uint32(cfg.SaltLength),
+		KeyLength:   uint32(cfg.KeyLength),
+	}
+}
+
+func GetHashParams() argon2id.Params {
+	return *hashParams
+}
+
+// Hash hashes the password with the currently configured argon2id parameters
+func Hash(password string) (string, error) {
+	return argon2id.CreateHash(password, hashParams)
+}
+
+// NeedsRehash reports whether the hash was created with weaker parameters than the current configuration
+func NeedsRehash(hash string) (bool, error) {
+	params, _, _, err := argon2id.DecodeHash(hash)
+	if err != nil {
+		return false, err
+	}
+	return IsWeaker(params), nil
+}
+
+func IsWeaker(params *argon2id.Params) bool {
+	return params.Memory < hashParams.Memory ||
+		params.Iterations < hashParams.Iterations ||
+		params.Parallelism < hashParams.Parallelism ||
+		params.SaltLength < hashParams.SaltLength ||
+		params.KeyLength < hashParams.KeyLength
+}
+
+// FormatParams formats the parameters the same way they are stored in the hash
+func FormatParams(params *argon2id.Params) string {
+	return fmt.Sprintf("m=%d,t=%d,p=%d", params.Memory, params.Iterations, params.Parallelism)
+}
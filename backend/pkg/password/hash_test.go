@@ -0,0 +1,53 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/alexedwards/argon2id"
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/util"
+)
+
+func TestHashAndNeedsRehash(t *testing.T) {
+	Setup(config.Default())
+
+	pwd := util.StringWithCharset(20)
+
+	t.Run("Hash with current params", func(t *testing.T) {
+		hash, err := Hash(pwd)
+		if err != nil {
+			t.Errorf("Error while hashing password: %s", err)
+		}
+
+		needsRehash, err := NeedsRehash(hash)
+		if err != nil {
+			t.Errorf("Error while checking if the hash needs a rehash: %s", err)
+		}
+
+		False(t, needsRehash)
+	})
+
+	t.Run("Hash with weaker params", func(t *testing.T) {
+		weak := GetHashParams()
+		weak.Memory = weak.Memory / 2
+
+		hash, err := argon2id.CreateHash(pwd, &weak)
+		if err != nil {
+			t.Errorf("Error while hashing password: %s", err)
+		}
+
+		needsRehash, err := NeedsRehash(hash)
+		if err != nil {
+			t.Errorf("Error while checking if the hash needs a rehash: %s", err)
+		}
+
+		True(t, needsRehash)
+	})
+
+	t.Run("Invalid Hash", func(t *testing.T) {
+		_, err := NeedsRehash("not a hash")
+
+		NotNil(t, err)
+	})
+}
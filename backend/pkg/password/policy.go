@@ -0,0 +1,177 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// breached.txt contains sha1 hashes of breached passwords in the k-anonymity format
+// "PREFIX:SUFFIX" so the list can be swapped with a bigger (HIBP) range dump
+//
+//go:embed breached.txt
+var bundledBreachedList []byte
+
+type Policy struct {
+	MinLength       int
+	RequiredClasses int
+	ForbidEmail     bool
+	CheckBreached   bool
+}
+
+type PolicyError struct {
+	Code    int
+	Message string
+}
+
+func (err *PolicyError) Error() string {
+	return err.Message
+}
+
+var (
+	policy   = Policy{MinLength: 8, RequiredClasses: 2, ForbidEmail: true, CheckBreached: true}
+	breached = map[string]map[string]bool{}
+)
+
+func init() {
+	loadBreachedList(bytes.NewReader(bundledBreachedList))
+}
+
+func Setup(cfg *config.Config) {
+	policy = Policy{
+		MinLength:       cfg.Password.MinLength,
+		RequiredClasses: cfg.Password.RequiredClasses,
+		ForbidEmail:     !cfg.Password.AllowEmail,
+		CheckBreached:   !cfg.Password.SkipBreachedCheck,
+	}
+	setupHashParams(cfg.Password.Argon2)
+
+	//a bigger list can be provided in addition to the bundled one
+	path := cfg.Password.BreachedFile
+	if len(path) > 0 {
+		f, err := os.Open(path)
+		if err != nil {
+			logging.L().Error("error while opening the breached passwords file", zap.String("path", path), zap.Error(err))
+			return
+		}
+		defer f.Close()
+
+		loadBreachedList(f)
+	}
+}
+
+func GetPolicy() Policy {
+	return policy
+}
+
+// Validate checks the password against the configured policy and returns a *PolicyError if it's violated
+func Validate(email, password string) error {
+	if len([]rune(password)) < policy.MinLength {
+		return &PolicyError{
+			Code:    e.ERROR_PASSWORD_TOO_SHORT,
+			Message: fmt.Sprintf("password has to be at least %d characters long", policy.MinLength),
+		}
+	}
+
+	if countClasses(password) < policy.RequiredClasses {
+		return &PolicyError{
+			Code:    e.ERROR_PASSWORD_MISSING_CHARACTER_CLASSES,
+			Message: fmt.Sprintf("password has to contain at least %d of: lowercase letters, uppercase letters, numbers and symbols", policy.RequiredClasses),
+		}
+	}
+
+	if policy.ForbidEmail && containsEmail(email, password) {
+		return &PolicyError{
+			Code:    e.ERROR_PASSWORD_CONTAINS_EMAIL,
+			Message: "password can not contain your email",
+		}
+	}
+
+	if policy.CheckBreached && IsBreached(password) {
+		return &PolicyError{
+			Code:    e.ERROR_PASSWORD_BREACHED,
+			Message: "password was found in a data breach",
+		}
+	}
+
+	return nil
+}
+
+func IsBreached(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, ok := breached[hash[:5]]
+	if !ok {
+		return false
+	}
+	return suffixes[hash[5:]]
+}
+
+func countClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+func containsEmail(email, password string) bool {
+	if len(email) <= 0 {
+		return false
+	}
+
+	pwd := strings.ToLower(password)
+	email = strings.ToLower(email)
+	if strings.Contains(pwd, email) {
+		return true
+	}
+
+	//also check the local part of the email address
+	local := strings.Split(email, "@")[0]
+	return len(local) >= 3 && strings.Contains(pwd, local)
+}
+
+func loadBreachedList(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.Split(strings.TrimSpace(scanner.Text()), ":")
+
+		//supports "PREFIX:SUFFIX" and the full "HASH:COUNT" format of HIBP dumps
+		var prefix, suffix string
+		if len(parts[0]) == 40 {
+			prefix, suffix = parts[0][:5], parts[0][5:]
+		} else if len(parts[0]) == 5 && len(parts) >= 2 {
+			prefix, suffix = parts[0], parts[1]
+		} else {
+			continue
+		}
+
+		prefix = strings.ToUpper(prefix)
+		if _, ok := breached[prefix]; !ok {
+			breached[prefix] = map[string]bool{}
+		}
+		breached[prefix][strings.ToUpper(suffix)] = true
+	}
+}
@@ -0,0 +1,58 @@
+package password
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/util"
+)
+
+func TestValidate(t *testing.T) {
+	Setup(config.Default())
+
+	email := util.RandomEmail()
+
+	t.Run("Valid Password", func(t *testing.T) {
+		err := Validate(email, "sdfkjDFG73dfg")
+
+		Nil(t, err)
+	})
+
+	t.Run("Password too short", func(t *testing.T) {
+		err := Validate(email, "aB1")
+
+		Equal(t, e.ERROR_PASSWORD_TOO_SHORT, err.(*PolicyError).Code)
+	})
+
+	t.Run("Empty Password", func(t *testing.T) {
+		err := Validate(email, "")
+
+		Equal(t, e.ERROR_PASSWORD_TOO_SHORT, err.(*PolicyError).Code)
+	})
+
+	t.Run("Password with only one character class", func(t *testing.T) {
+		err := Validate(email, "dfkjghdfkjghdfg")
+
+		Equal(t, e.ERROR_PASSWORD_MISSING_CHARACTER_CLASSES, err.(*PolicyError).Code)
+	})
+
+	t.Run("Password contains email", func(t *testing.T) {
+		err := Validate("someone@gmail.com", "Someone1234!")
+
+		Equal(t, e.ERROR_PASSWORD_CONTAINS_EMAIL, err.(*PolicyError).Code)
+	})
+
+	t.Run("Breached Password", func(t *testing.T) {
+		err := Validate(email, "Password123")
+
+		Equal(t, e.ERROR_PASSWORD_BREACHED, err.(*PolicyError).Code)
+	})
+}
+
+func TestIsBreached(t *testing.T) {
+	True(t, IsBreached("password"))
+	True(t, IsBreached("P@ssw0rd"))
+	False(t, IsBreached(util.StringWithCharset(30)))
+}
@@ -2,35 +2,59 @@ package totp
 
 import (
 	"bytes"
+	"context"
+	"crypto/subtle"
 	"encoding/base64"
+	"errors"
 	"image/png"
-	"log"
 	"net/http"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/pquerna/otp/totp"
 	"github.com/urento/shoppinglist/models"
 	"github.com/urento/shoppinglist/pkg/app"
 	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
 	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/logging"
 	"github.com/xlzd/gotp"
+	"go.uber.org/zap"
 )
 
+// codes are generated for 30 second steps
+const stepDuration = 30
+
+// how many steps before and after the current one are still accepted
+var skewSteps = 1
+
+func Setup(cfg *config.Config) {
+	skewSteps = cfg.TwoFactor.TOTPSkewSteps
+}
+
 func Disable(email string, appGin *app.Gin) {
-	err := models.SetTwoFactorAuthentication(email, false)
+	err := models.SetTwoFactorAuthentication(appGin.C.Request.Context(), email, false)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_CHANING_TWOFACTORAUTHENTICATION_STATUS, map[string]string{"success": "false"})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_CHANING_TWOFACTORAUTHENTICATION_STATUS))
 		return
 	}
 
-	err = cache.DeleteTOTPSecret(email)
+	err = models.DeleteTOTPSecret(appGin.C.Request.Context(), email)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_CHANING_TWOFACTORAUTHENTICATION_STATUS, map[string]string{"success": "false"})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_CHANING_TWOFACTORAUTHENTICATION_STATUS))
 		return
 	}
 
+	err = cache.DeletePendingTOTPSecret(appGin.C.Request.Context(), email)
+	if err != nil {
+		logging.Error(appGin.C, err)
+	}
+
+	err = cache.DeleteTOTPStep(appGin.C.Request.Context(), email)
+	if err != nil {
+		logging.Error(appGin.C, err)
+	}
+
 	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{"success": "true", "verified": "true"})
 }
 
@@ -43,10 +67,10 @@ func Enable(email string, appGin *app.Gin) []byte {
 		return []byte(err.Error())
 	}
 
-	err = cache.CacheTOTPSecret(email, key.Secret())
+	//the secret only gets activated once the user confirms it with a valid code
+	err = cache.CachePendingTOTPSecret(appGin.C.Request.Context(), email, key.Secret())
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_CHANING_TWOFACTORAUTHENTICATION_STATUS, nil)
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_CHANING_TWOFACTORAUTHENTICATION_STATUS))
 		return []byte(err.Error())
 	}
 
@@ -54,8 +78,7 @@ func Enable(email string, appGin *app.Gin) []byte {
 	var buf bytes.Buffer
 	img, err := key.Image(200, 200)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_CONVERTING_KEY_TO_IMG, nil)
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_CONVERTING_KEY_TO_IMG))
 		return []byte(err.Error())
 	}
 
@@ -69,22 +92,103 @@ func GetQRCodeBase64String(email string, data []byte) string {
 	return imgBase64Str
 }
 
-func Verify(email, a string, enableAfter bool) (bool, error) {
-	timestamp := time.Now().Unix()
+// Result describes the time-step a code matched. Drift is the offset to the server's current step
+// and can be used to detect clients with drifting clocks
+type Result struct {
+	Valid bool
+	Step  int64
+	Drift int
+}
+
+func Verify(ctx context.Context, email, a string) (bool, error) {
+	res, err := VerifyStep(ctx, email, a)
+	return res.Valid, err
+}
+
+// VerifyStep checks the code against the allowed time-steps and rejects codes whose step was already used
+func VerifyStep(ctx context.Context, email, a string) (Result, error) {
+	secret, err := models.GetTOTPSecret(ctx, email)
+	if err != nil {
+		return Result{}, err
+	}
 
-	secret, err := cache.GetTOTPSecret(email)
+	return useCode(ctx, email, secret, a)
+}
+
+// Confirm activates the pending enrollment if the code is valid and returns newly generated backup codes
+func Confirm(ctx context.Context, email, a string) (pq.StringArray, error) {
+	secret, err := cache.GetPendingTOTPSecret(ctx, email)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	if enableAfter {
-		err := models.SetTwoFactorAuthentication(email, true)
-		if err != nil {
-			log.Print(err)
-			return false, err
-		}
+	res, err := useCode(ctx, email, secret, a)
+	if err != nil {
+		return nil, err
 	}
 
+	if !res.Valid {
+		return nil, errors.New("otp is not valid")
+	}
+
+	err = models.SaveTOTPSecret(ctx, email, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = models.SetTwoFactorAuthentication(ctx, email, true)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cache.DeletePendingTOTPSecret(ctx, email)
+	if err != nil {
+		logging.L().Error("error while deleting the pending totp secret", zap.Error(err))
+	}
+
+	userId, err := models.GetUserIDByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.GenerateCodes(ctx, email, userId, true, true)
+}
+
+func useCode(ctx context.Context, email, secret, a string) (Result, error) {
+	res := verifyCode(secret, a, time.Now().Unix())
+	if !res.Valid {
+		return res, nil
+	}
+
+	//the step has to be remembered as long as it could still be accepted
+	ttl := time.Duration(2*skewSteps+1) * stepDuration * time.Second
+	unused, err := cache.MarkTOTPStepUsed(ctx, email, res.Step, ttl)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if !unused {
+		return Result{Step: res.Step, Drift: res.Drift}, nil
+	}
+
+	if res.Drift != 0 {
+		logging.L().Info("totp code matched with drift", logging.User(email), zap.Int("drift", res.Drift))
+	}
+
+	return res, nil
+}
+
+func verifyCode(secret, a string, timestamp int64) Result {
 	otp := gotp.NewDefaultTOTP(secret)
-	return otp.Verify(a, int(timestamp)), nil
+	current := timestamp / stepDuration
+
+	for drift := -skewSteps; drift <= skewSteps; drift++ {
+		step := current + int64(drift)
+		code := otp.At(int(step * stepDuration))
+		if subtle.ConstantTimeCompare([]byte(code), []byte(a)) == 1 {
+			return Result{Valid: true, Step: step, Drift: drift}
+		}
+	}
+
+	return Result{}
 }
@@ -0,0 +1,149 @@
+package totp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/pkg/app"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/util"
+	"github.com/xlzd/gotp"
+)
+
+func TestConfirm(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	models.Setup(cfg)
+	util.Setup(cfg)
+	cache.Setup(cfg)
+
+	email := util.RandomEmail()
+	err := models.CreateAccount(ctx, email, util.StringWithCharset(20), util.StringWithCharset(20), "")
+	if err != nil {
+		t.Errorf("Error while creating account: %s", err)
+	}
+
+	Enable(email, &app.Gin{})
+
+	t.Run("Confirm with wrong code", func(t *testing.T) {
+		_, err := Confirm(ctx, email, "000000a")
+
+		enabled, err2 := models.IsTwoFactorEnabled(ctx, email)
+		if err2 != nil {
+			t.Errorf("Error while checking if 2fa is enabled: %s", err2)
+		}
+
+		has, err2 := models.HasTOTPSecret(ctx, email)
+		if err2 != nil {
+			t.Errorf("Error while checking if the user has a totp secret: %s", err2)
+		}
+
+		NotNil(t, err)
+		False(t, enabled)
+		False(t, has)
+	})
+
+	t.Run("Confirm with valid code", func(t *testing.T) {
+		secret, err := cache.GetPendingTOTPSecret(ctx, email)
+		if err != nil {
+			t.Errorf("Error while getting pending totp secret: %s", err)
+		}
+
+		code := gotp.NewDefaultTOTP(secret).At(int(time.Now().Unix()))
+		codes, err := Confirm(ctx, email, code)
+		if err != nil {
+			t.Errorf("Error while confirming totp enrollment: %s", err)
+		}
+
+		enabled, err := models.IsTwoFactorEnabled(ctx, email)
+		if err != nil {
+			t.Errorf("Error while checking if 2fa is enabled: %s", err)
+		}
+
+		_, err = cache.GetPendingTOTPSecret(ctx, email)
+
+		Equal(t, 6, len(codes))
+		True(t, enabled)
+		NotNil(t, err)
+	})
+}
+
+func TestVerifyStep(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	models.Setup(cfg)
+	util.Setup(cfg)
+	cache.Setup(cfg)
+	Setup(cfg)
+
+	email := util.RandomEmail()
+	err := models.CreateAccount(ctx, email, util.StringWithCharset(20), util.StringWithCharset(20), "")
+	if err != nil {
+		t.Errorf("Error while creating account: %s", err)
+	}
+
+	secret := gotp.RandomSecret(16)
+	err = models.SaveTOTPSecret(ctx, email, secret)
+	if err != nil {
+		t.Errorf("Error while saving totp secret: %s", err)
+	}
+
+	now := time.Now().Unix()
+
+	t.Run("Verify code from the previous step", func(t *testing.T) {
+		code := gotp.NewDefaultTOTP(secret).At(int(now - stepDuration))
+		res, err := VerifyStep(ctx, email, code)
+
+		Nil(t, err)
+		True(t, res.Valid)
+		Equal(t, now/stepDuration-1, res.Step)
+		Equal(t, -1, res.Drift)
+	})
+
+	t.Run("Verify current code", func(t *testing.T) {
+		code := gotp.NewDefaultTOTP(secret).At(int(now))
+		res, err := VerifyStep(ctx, email, code)
+
+		Nil(t, err)
+		True(t, res.Valid)
+		Equal(t, 0, res.Drift)
+	})
+
+	t.Run("Replay current code", func(t *testing.T) {
+		code := gotp.NewDefaultTOTP(secret).At(int(now))
+		res, err := VerifyStep(ctx, email, code)
+
+		Nil(t, err)
+		False(t, res.Valid)
+	})
+
+	t.Run("Verify code outside of the window", func(t *testing.T) {
+		code := gotp.NewDefaultTOTP(secret).At(int(now + 5*stepDuration))
+		res, err := VerifyStep(ctx, email, code)
+
+		Nil(t, err)
+		False(t, res.Valid)
+	})
+}
+
+func TestVerifyCode(t *testing.T) {
+	secret := gotp.RandomSecret(16)
+	now := time.Now().Unix()
+	otp := gotp.NewDefaultTOTP(secret)
+
+	skewSteps = 2
+	defer func() { skewSteps = 1 }()
+
+	res := verifyCode(secret, otp.At(int(now+2*stepDuration)), now)
+	True(t, res.Valid)
+	Equal(t, 2, res.Drift)
+
+	res = verifyCode(secret, otp.At(int(now+3*stepDuration)), now)
+	False(t, res.Valid)
+}
@@ -0,0 +1,23 @@
+package tracing
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Middleware starts a server span for every request and adds its trace id to the request logger, so the logs of a
+// request can be found from its trace
+func Middleware(serviceName string) gin.HandlersChain {
+	return gin.HandlersChain{
+		otelgin.Middleware(serviceName),
+		func(c *gin.Context) {
+			spanContext := trace.SpanContextFromContext(c.Request.Context())
+			if spanContext.IsValid() {
+				logging.AddFields(c, zap.String("trace_id", spanContext.TraceID().String()))
+			}
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	old := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(old)
+	})
+	return recorder
+}
+
+func TestRedisHook(t *testing.T) {
+	recorder := useRecorder(t)
+	hook := RedisHook{}
+
+	parent, span := tracer().Start(context.Background(), "request")
+
+	cmd := redis.NewStringCmd(parent, "get", "key")
+	ctx, err := hook.BeforeProcess(parent, cmd)
+	Nil(t, err)
+	cmd.SetErr(redis.Nil)
+	Nil(t, hook.AfterProcess(ctx, cmd))
+
+	failed := redis.NewStatusCmd(parent, "set", "key", "value")
+	ctx, _ = hook.BeforeProcess(parent, failed)
+	failed.SetErr(errors.New("connection refused"))
+	hook.AfterProcess(ctx, failed)
+
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+
+	Equal(t, "redis.get", spans[0].Name())
+	Equal(t, span.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	Equal(t, codes.Unset, spans[0].Status().Code)
+	Equal(t, "redis.set", spans[1].Name())
+	Equal(t, codes.Error, spans[1].Status().Code)
+}
+
+func TestSetupNone(t *testing.T) {
+	cfg := config.Default()
+
+	shutdown, err := Setup(context.Background(), cfg)
+	Nil(t, err)
+	Nil(t, shutdown(context.Background()))
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"context"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -15,12 +16,12 @@ type Claims struct {
 	jwt.StandardClaims
 }
 
-func GenerateToken(email string, refreshToken bool) (string, error) {
+func GenerateToken(ctx context.Context, email string, refreshToken bool) (string, error) {
 	nowTime := time.Now()
 	expireTime := nowTime.Add(24 * time.Hour)
 	refreshTokenExpireTime := nowTime.Add(168 * time.Hour) // 1 week in hours
 
-	secretId, err := cache.GenerateSecretId(email)
+	secretId, err := cache.GenerateSecretId(ctx, email)
 	if err != nil {
 		return "", err
 	}
@@ -60,7 +61,7 @@ func GenerateToken(email string, refreshToken bool) (string, error) {
 		return "", err
 	}
 
-	err = cache.CacheJWT(email, token)
+	err = cache.CacheJWT(ctx, email, token)
 	if err != nil {
 		return "", err
 	}
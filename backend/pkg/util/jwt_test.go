@@ -1,20 +1,24 @@
 package util
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	. "github.com/stretchr/testify/assert"
 	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
 )
 
 func TestGenerateTokenAndParse(t *testing.T) {
-	cache.Setup(false)
+	ctx := context.Background()
+
+	cache.Setup(config.MustLoad(nil))
 
 	t.Run("Generate Token and Parse", func(t *testing.T) {
 		email := RandomString(10) + "@gmail.com"
 
-		token, err := GenerateToken(email, false)
+		token, err := GenerateToken(ctx, email, false)
 		tasdg := time.Now()
 		if err != nil {
 			t.Errorf("Error while generating token: %s", err)
@@ -34,7 +38,7 @@ func TestGenerateTokenAndParse(t *testing.T) {
 	t.Run("Generate Refresh Token and Parse", func(t *testing.T) {
 		email := RandomString(10) + "@gmail.com"
 
-		token, err := GenerateToken(email, true)
+		token, err := GenerateToken(ctx, email, true)
 		tadsg := time.Now()
 		if err != nil {
 			t.Errorf("Error while generating token: %s", err)
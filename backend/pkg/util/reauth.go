@@ -0,0 +1,66 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const reauthPurpose = "reauth"
+
+type ReauthClaims struct {
+	Email   string `json:"email"`
+	Session string `json:"session"`
+	Purpose string `json:"purpose"`
+	jwt.StandardClaims
+}
+
+// GenerateReauthToken creates a short-lived token that proves the user recently entered the password or a code.
+// It's bound to the session so it can't be used together with another session
+func GenerateReauthToken(email, session string, ttl time.Duration) (string, error) {
+	claims := &ReauthClaims{
+		email,
+		hashSession(session),
+		reauthPurpose,
+		jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(ttl).Unix(),
+			IssuedAt:  time.Now().Unix(),
+			Issuer:    "shoppinglist",
+		},
+	}
+
+	tokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return tokenClaims.SignedString(jwtSecret)
+}
+
+// VerifyReauthToken checks that the token is valid and belongs to the email and the session
+func VerifyReauthToken(token, email, session string) error {
+	tokenClaims, err := jwt.ParseWithClaims(token, &ReauthClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return jwtSecret, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	claims, ok := tokenClaims.Claims.(*ReauthClaims)
+	if !ok || !tokenClaims.Valid || claims.Purpose != reauthPurpose {
+		return errors.New("reauth token is not valid")
+	}
+
+	if claims.Email != email || claims.Session != hashSession(session) {
+		return errors.New("reauth token belongs to another session")
+	}
+
+	return nil
+}
+
+func hashSession(session string) string {
+	sum := sha256.Sum256([]byte(session))
+	return hex.EncodeToString(sum[:])
+}
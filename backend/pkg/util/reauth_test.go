@@ -0,0 +1,61 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+func TestGenerateReauthTokenAndVerify(t *testing.T) {
+	Setup(config.MustLoad(nil))
+
+	email := RandomEmail()
+	session := StringWithCharset(100)
+
+	token, err := GenerateReauthToken(email, session, time.Minute)
+	if err != nil {
+		t.Errorf("Error while generating reauth token: %s", err)
+	}
+
+	t.Run("Verify Reauth Token", func(t *testing.T) {
+		err := VerifyReauthToken(token, email, session)
+
+		Nil(t, err)
+	})
+
+	t.Run("Verify Reauth Token with another Session", func(t *testing.T) {
+		err := VerifyReauthToken(token, email, StringWithCharset(100))
+
+		NotNil(t, err)
+	})
+
+	t.Run("Verify Reauth Token with another Email", func(t *testing.T) {
+		err := VerifyReauthToken(token, RandomEmail(), session)
+
+		NotNil(t, err)
+	})
+
+	t.Run("Verify expired Reauth Token", func(t *testing.T) {
+		expired, err := GenerateReauthToken(email, session, -time.Minute)
+		if err != nil {
+			t.Errorf("Error while generating reauth token: %s", err)
+		}
+
+		err = VerifyReauthToken(expired, email, session)
+
+		NotNil(t, err)
+	})
+
+	t.Run("Verify Unlock Token as Reauth Token", func(t *testing.T) {
+		unlock, err := GenerateUnlockToken(email)
+		if err != nil {
+			t.Errorf("Error while generating unlock token: %s", err)
+		}
+
+		err = VerifyReauthToken(unlock, email, session)
+
+		NotNil(t, err)
+	})
+}
@@ -0,0 +1,53 @@
+package util
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const unlockPurpose = "unlock"
+
+// UnlockTokenTTL is how long the link in the unlock email can be used
+const UnlockTokenTTL = time.Hour
+
+type UnlockClaims struct {
+	Email   string `json:"email"`
+	Purpose string `json:"purpose"`
+	jwt.StandardClaims
+}
+
+// GenerateUnlockToken creates a signed token that can be used to unlock the account within 1 hour
+func GenerateUnlockToken(email string) (string, error) {
+	claims := &UnlockClaims{
+		email,
+		unlockPurpose,
+		jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(UnlockTokenTTL).Unix(),
+			Issuer:    "shoppinglist",
+		},
+	}
+
+	tokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return tokenClaims.SignedString(jwtSecret)
+}
+
+func ParseUnlockToken(token string) (string, error) {
+	tokenClaims, err := jwt.ParseWithClaims(token, &UnlockClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return jwtSecret, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	claims, ok := tokenClaims.Claims.(*UnlockClaims)
+	if !ok || !tokenClaims.Valid || claims.Purpose != unlockPurpose {
+		return "", errors.New("unlock token is not valid")
+	}
+
+	return claims.Email, nil
+}
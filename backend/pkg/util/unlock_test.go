@@ -0,0 +1,34 @@
+package util
+
+import (
+	"testing"
+
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+func TestGenerateUnlockTokenAndParse(t *testing.T) {
+	Setup(config.MustLoad(nil))
+
+	t.Run("Generate Unlock Token and Parse", func(t *testing.T) {
+		email := RandomEmail()
+
+		token, err := GenerateUnlockToken(email)
+		if err != nil {
+			t.Errorf("Error while generating unlock token: %s", err)
+		}
+
+		parsed, err := ParseUnlockToken(token)
+		if err != nil {
+			t.Errorf("Error while parsing unlock token: %s", err)
+		}
+
+		Equal(t, email, parsed)
+	})
+
+	t.Run("Parse invalid Unlock Token", func(t *testing.T) {
+		_, err := ParseUnlockToken(StringWithCharset(100))
+
+		NotNil(t, err)
+	})
+}
@@ -3,76 +3,31 @@ package util
 import (
 	"errors"
 	"fmt"
-	"log"
 	"math"
 	"math/rand"
 	"net/mail"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
-	util "github.com/urento/shoppinglist/pkg"
+	"github.com/urento/shoppinglist/pkg/config"
 )
 
 const letterAndNumberBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
 
-func Setup() {
-	var err error
-	if IsTesting() {
-		if util.PROD {
-			err = godotenv.Load()
-		} else if util.GITHUB_TESTING {
-			err = nil
-		} else if util.LOCAL_TESTING {
-			err = godotenv.Load("../.env")
-		} else {
-			err = godotenv.Load()
-		}
-		if err != nil {
-			log.Fatal(err)
-		}
-	}
-	jwtSecret = []byte(os.Getenv("JwtSecret"))
-}
-
-func IsTesting() bool {
-	var err error
-	if util.PROD {
-		err = godotenv.Load()
-	} else if util.GITHUB_TESTING {
-		err = nil
-	} else if util.LOCAL_TESTING {
-		err = godotenv.Load("../.env")
-	} else {
-		err = godotenv.Load()
-	}
+var cookieDomain string
 
-	if err != nil {
-		fmt.Print(err.Error())
-	}
+var cookieSecure bool
 
-	return os.Getenv("TESTING") == "true"
+func Setup(cfg *config.Config) {
+	jwtSecret = []byte(cfg.JWT.Secret)
+	cookieDomain = cfg.Cookie.Domain
+	cookieSecure = cfg.Cookie.Secure
 }
 
-func IsProd() bool {
-	var err error
-	if util.PROD {
-		err = godotenv.Load()
-	} else if util.GITHUB_TESTING {
-		err = nil
-	} else if util.LOCAL_TESTING {
-		err = godotenv.Load("../.env")
-	} else {
-		err = godotenv.Load()
-	}
-
-	if err != nil {
-		fmt.Print(err.Error())
-	}
-
-	return os.Getenv("ENVIRONMENT") == "production"
+// SetCookie sets a http only cookie for the configured domain, maxAge < 0 deletes it
+func SetCookie(ctx *gin.Context, name, value string, maxAge int) {
+	ctx.SetCookie(name, value, maxAge, "/", cookieDomain, cookieSecure, true)
 }
 
 func GetCookie(ctx *gin.Context) (string, error) {
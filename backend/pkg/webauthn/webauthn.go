@@ -0,0 +1,258 @@
+package webauthn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
+)
+
+const (
+	ceremonyRegistration = "registration"
+	ceremonyLogin        = "login"
+)
+
+var web *webauthn.WebAuthn
+
+func Setup(cfg *config.Config) error {
+	w, err := webauthn.New(&webauthn.Config{
+		RPDisplayName:         cfg.WebAuthn.RPName,
+		RPID:                  cfg.WebAuthn.RPID,
+		RPOrigin:              cfg.WebAuthn.RPOrigin,
+		AttestationPreference: protocol.PreferNoAttestation,
+	})
+	if err != nil {
+		return err
+	}
+
+	web = w
+	return nil
+}
+
+// user adapts an account and its registered credentials to the webauthn library
+type user struct {
+	id          int
+	email       string
+	username    string
+	credentials []models.WebAuthnCredential
+}
+
+func (u *user) WebAuthnID() []byte {
+	return []byte(strconv.Itoa(u.id))
+}
+
+func (u *user) WebAuthnName() string {
+	return u.email
+}
+
+func (u *user) WebAuthnDisplayName() string {
+	if len(u.username) <= 0 {
+		return u.email
+	}
+	return u.username
+}
+
+func (u *user) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *user) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, c := range u.credentials {
+		credentials = append(credentials, webauthn.Credential{
+			ID:              c.CredentialID,
+			PublicKey:       c.PublicKey,
+			AttestationType: c.AttestationType,
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    c.AAGUID,
+				SignCount: c.SignCount,
+			},
+		})
+	}
+	return credentials
+}
+
+func (u *user) descriptors() []protocol.CredentialDescriptor {
+	descriptors := make([]protocol.CredentialDescriptor, 0, len(u.credentials))
+	for _, c := range u.credentials {
+		descriptors = append(descriptors, protocol.CredentialDescriptor{
+			Type:         protocol.PublicKeyCredentialType,
+			CredentialID: c.CredentialID,
+		})
+	}
+	return descriptors
+}
+
+func loadUser(ctx context.Context, email string) (*user, error) {
+	auth, err := models.GetUser(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if auth.ID <= 0 {
+		return nil, errors.New("user not found")
+	}
+
+	credentials, err := models.GetWebAuthnCredentials(ctx, auth.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user{id: auth.ID, email: email, username: auth.Username, credentials: credentials}, nil
+}
+
+// BeginRegistration creates the options for navigator.credentials.create()
+func BeginRegistration(ctx context.Context, email string) (*protocol.CredentialCreation, error) {
+	u, err := loadUser(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	options, session, err := web.BeginRegistration(u, webauthn.WithExclusions(u.descriptors()))
+	if err != nil {
+		return nil, err
+	}
+
+	err = saveSession(ctx, email, ceremonyRegistration, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// FinishRegistration verifies the attestation of the authenticator and stores the new credential
+func FinishRegistration(ctx context.Context, email, name string, response io.Reader) (*models.WebAuthnCredential, error) {
+	parsed, err := protocol.ParseCredentialCreationResponseBody(response)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := getSession(ctx, email, ceremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := loadUser(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := web.CreateCredential(u, *session, parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(name) <= 0 {
+		name = "Security Key " + strconv.Itoa(len(u.credentials)+1)
+	}
+
+	c := models.WebAuthnCredential{
+		UserID:          u.id,
+		Name:            name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+	}
+
+	err = models.CreateWebAuthnCredential(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// BeginLogin creates the options for navigator.credentials.get(). Passwordless logins require
+// the authenticator to verify the user (pin or biometrics) because it's the only factor
+func BeginLogin(ctx context.Context, email string, passwordless bool) (*protocol.CredentialAssertion, error) {
+	u, err := loadUser(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(u.credentials) <= 0 {
+		return nil, errors.New("user has no security keys")
+	}
+
+	verification := protocol.VerificationDiscouraged
+	if passwordless {
+		verification = protocol.VerificationRequired
+	}
+
+	options, session, err := web.BeginLogin(u, webauthn.WithUserVerification(verification))
+	if err != nil {
+		return nil, err
+	}
+
+	err = saveSession(ctx, email, ceremonyLogin, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// FinishLogin verifies the assertion and reports if the ceremony was started as a passwordless login
+func FinishLogin(ctx context.Context, email string, response io.Reader) (bool, error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBody(response)
+	if err != nil {
+		return false, err
+	}
+
+	session, err := getSession(ctx, email, ceremonyLogin)
+	if err != nil {
+		return false, err
+	}
+
+	u, err := loadUser(ctx, email)
+	if err != nil {
+		return false, err
+	}
+
+	credential, err := web.ValidateLogin(u, *session, parsed)
+	if err != nil {
+		return false, err
+	}
+
+	//a counter that didn't increase means the key might have been cloned
+	if credential.Authenticator.CloneWarning {
+		return false, errors.New("signature counter did not increase")
+	}
+
+	err = models.UpdateWebAuthnSignCount(ctx, u.id, credential.ID, credential.Authenticator.SignCount, time.Now().Unix())
+	if err != nil {
+		return false, err
+	}
+
+	return session.UserVerification == protocol.VerificationRequired, nil
+}
+
+func saveSession(ctx context.Context, email, ceremony string, session *webauthn.SessionData) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return cache.CacheWebAuthnSession(ctx, email, ceremony, data)
+}
+
+func getSession(ctx context.Context, email, ceremony string) (*webauthn.SessionData, error) {
+	data, err := cache.GetWebAuthnSession(ctx, email, ceremony)
+	if err != nil {
+		return nil, err
+	}
+
+	var session webauthn.SessionData
+	err = json.Unmarshal(data, &session)
+	return &session, err
+}
@@ -0,0 +1,316 @@
+package webauthn
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/fxamacker/cbor/v2"
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/util"
+)
+
+// softwareAuthenticator behaves like a security key with a single ES256 credential and "none" attestation
+type softwareAuthenticator struct {
+	origin       string
+	credentialId []byte
+	key          *ecdsa.PrivateKey
+	signCount    uint32
+}
+
+func newSoftwareAuthenticator(t *testing.T, origin string) *softwareAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error while generating key: %s", err)
+	}
+
+	credentialId := make([]byte, 16)
+	_, err = rand.Read(credentialId)
+	if err != nil {
+		t.Fatalf("Error while generating credential id: %s", err)
+	}
+
+	return &softwareAuthenticator{origin: origin, credentialId: credentialId, key: key}
+}
+
+func (a *softwareAuthenticator) authData(rpId string, attested bool) []byte {
+	rpIdHash := sha256.Sum256([]byte(rpId))
+
+	//user present and user verified
+	flags := byte(0x01 | 0x04)
+	if attested {
+		flags |= 0x40
+	}
+
+	a.signCount++
+
+	var buf bytes.Buffer
+	buf.Write(rpIdHash[:])
+	buf.WriteByte(flags)
+	binary.Write(&buf, binary.BigEndian, a.signCount)
+
+	if attested {
+		buf.Write(make([]byte, 16))
+		binary.Write(&buf, binary.BigEndian, uint16(len(a.credentialId)))
+		buf.Write(a.credentialId)
+
+		coseKey, _ := cbor.Marshal(map[int]interface{}{
+			1:  2,
+			3:  -7,
+			-1: 1,
+			-2: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+			-3: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+		})
+		buf.Write(coseKey)
+	}
+
+	return buf.Bytes()
+}
+
+func (a *softwareAuthenticator) clientData(ceremony string, challenge protocol.Challenge) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge.String(),
+		"origin":    a.origin,
+	})
+	return data
+}
+
+// create answers navigator.credentials.create()
+func (a *softwareAuthenticator) create(t *testing.T, options *protocol.CredentialCreation) []byte {
+	attestationObject, err := cbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authData(options.Response.RelyingParty.ID, true),
+	})
+	if err != nil {
+		t.Fatalf("Error while encoding attestation object: %s", err)
+	}
+
+	response, _ := json.Marshal(map[string]interface{}{
+		"id":    encode(a.credentialId),
+		"rawId": encode(a.credentialId),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    encode(a.clientData("webauthn.create", options.Response.Challenge)),
+			"attestationObject": encode(attestationObject),
+		},
+	})
+	return response
+}
+
+// get answers navigator.credentials.get()
+func (a *softwareAuthenticator) get(t *testing.T, options *protocol.CredentialAssertion, userHandle []byte) []byte {
+	authData := a.authData(options.Response.RelyingPartyID, false)
+	clientData := a.clientData("webauthn.get", options.Response.Challenge)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("Error while signing assertion: %s", err)
+	}
+
+	response, _ := json.Marshal(map[string]interface{}{
+		"id":    encode(a.credentialId),
+		"rawId": encode(a.credentialId),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    encode(clientData),
+			"authenticatorData": encode(authData),
+			"signature":         encode(signature),
+			"userHandle":        encode(userHandle),
+		},
+	})
+	return response
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestSoftwareAuthenticator(t *testing.T) {
+	err := Setup(config.Default())
+	if err != nil {
+		t.Fatalf("Error while setting up webauthn: %s", err)
+	}
+
+	u := &user{id: 1, email: util.RandomEmail()}
+	authenticator := newSoftwareAuthenticator(t, web.Config.RPOrigin)
+
+	options, session, err := web.BeginRegistration(u)
+	if err != nil {
+		t.Fatalf("Error while beginning registration: %s", err)
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(authenticator.create(t, options)))
+	if err != nil {
+		t.Fatalf("Error while parsing attestation: %s", err)
+	}
+
+	credential, err := web.CreateCredential(u, *session, parsed)
+	if err != nil {
+		t.Fatalf("Error while creating credential: %s", err)
+	}
+
+	Equal(t, authenticator.credentialId, credential.ID)
+
+	u.credentials = append(u.credentials, models.WebAuthnCredential{
+		CredentialID: credential.ID,
+		PublicKey:    credential.PublicKey,
+		SignCount:    credential.Authenticator.SignCount,
+	})
+
+	t.Run("Login with registered credential", func(t *testing.T) {
+		options, session, err := web.BeginLogin(u, webauthn.WithUserVerification(protocol.VerificationRequired))
+		if err != nil {
+			t.Fatalf("Error while beginning login: %s", err)
+		}
+
+		parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(authenticator.get(t, options, u.WebAuthnID())))
+		if err != nil {
+			t.Fatalf("Error while parsing assertion: %s", err)
+		}
+
+		credential, err := web.ValidateLogin(u, *session, parsed)
+
+		Nil(t, err)
+		False(t, credential.Authenticator.CloneWarning)
+	})
+
+	t.Run("Login with answer for another challenge", func(t *testing.T) {
+		options, _, err := web.BeginLogin(u)
+		if err != nil {
+			t.Fatalf("Error while beginning login: %s", err)
+		}
+		_, session, err := web.BeginLogin(u)
+		if err != nil {
+			t.Fatalf("Error while beginning login: %s", err)
+		}
+
+		parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(authenticator.get(t, options, u.WebAuthnID())))
+		if err != nil {
+			t.Fatalf("Error while parsing assertion: %s", err)
+		}
+
+		_, err = web.ValidateLogin(u, *session, parsed)
+
+		NotNil(t, err)
+	})
+
+	t.Run("Login from another origin", func(t *testing.T) {
+		phishing := *authenticator
+		phishing.origin = "https://shoppinglist.example"
+
+		options, session, err := web.BeginLogin(u)
+		if err != nil {
+			t.Fatalf("Error while beginning login: %s", err)
+		}
+
+		parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(phishing.get(t, options, u.WebAuthnID())))
+		if err != nil {
+			t.Fatalf("Error while parsing assertion: %s", err)
+		}
+
+		_, err = web.ValidateLogin(u, *session, parsed)
+
+		NotNil(t, err)
+	})
+}
+
+func TestRegistrationAndLogin(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := config.MustLoad(nil)
+	models.Setup(cfg)
+	util.Setup(cfg)
+	cache.Setup(cfg)
+	err := Setup(cfg)
+	if err != nil {
+		t.Fatalf("Error while setting up webauthn: %s", err)
+	}
+
+	email := util.RandomEmail()
+	err = models.CreateAccount(ctx, email, util.StringWithCharset(20), util.StringWithCharset(20), "")
+	if err != nil {
+		t.Errorf("Error while creating account: %s", err)
+	}
+
+	userId, err := models.GetUserIDByEmail(ctx, email)
+	if err != nil {
+		t.Errorf("Error while getting user id: %s", err)
+	}
+
+	authenticator := newSoftwareAuthenticator(t, web.Config.RPOrigin)
+
+	creation, err := BeginRegistration(ctx, email)
+	if err != nil {
+		t.Fatalf("Error while beginning registration: %s", err)
+	}
+
+	credential, err := FinishRegistration(ctx, email, "", bytes.NewReader(authenticator.create(t, creation)))
+	if err != nil {
+		t.Fatalf("Error while finishing registration: %s", err)
+	}
+
+	has, err := models.HasWebAuthnCredential(ctx, userId)
+	if err != nil {
+		t.Errorf("Error while checking if the user has security keys: %s", err)
+	}
+
+	True(t, has)
+	Equal(t, "Security Key 1", credential.Name)
+
+	t.Run("Passwordless Login", func(t *testing.T) {
+		assertion, err := BeginLogin(ctx, email, true)
+		if err != nil {
+			t.Fatalf("Error while beginning login: %s", err)
+		}
+
+		passwordless, err := FinishLogin(ctx, email, bytes.NewReader(authenticator.get(t, assertion, nil)))
+
+		Nil(t, err)
+		True(t, passwordless)
+	})
+
+	t.Run("Second Factor Login", func(t *testing.T) {
+		assertion, err := BeginLogin(ctx, email, false)
+		if err != nil {
+			t.Fatalf("Error while beginning login: %s", err)
+		}
+
+		passwordless, err := FinishLogin(ctx, email, bytes.NewReader(authenticator.get(t, assertion, nil)))
+
+		Nil(t, err)
+		False(t, passwordless)
+	})
+
+	t.Run("Replay Assertion", func(t *testing.T) {
+		assertion, err := BeginLogin(ctx, email, false)
+		if err != nil {
+			t.Fatalf("Error while beginning login: %s", err)
+		}
+
+		response := authenticator.get(t, assertion, nil)
+		_, err = FinishLogin(ctx, email, bytes.NewReader(response))
+		if err != nil {
+			t.Fatalf("Error while finishing login: %s", err)
+		}
+
+		_, err = FinishLogin(ctx, email, bytes.NewReader(response))
+
+		NotNil(t, err)
+	})
+}
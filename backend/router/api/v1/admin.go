@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/pkg/app"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/e"
+)
+
+func GetHashParamsReport(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	token, err := GetCookie(c)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED))
+		return
+	}
+
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
+	if err != nil || len(email) <= 0 {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT))
+		return
+	}
+
+	rank, err := stores.Auth.GetRank(c.Request.Context(), email)
+	if err != nil || rank != "admin" {
+		appGin.Error(e.Wrap(err, http.StatusForbidden, e.ERROR_NOT_AUTHORIZED))
+		return
+	}
+
+	report, err := models.GetHashParamsReport(c.Request.Context())
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_HASH_REPORT))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, report)
+}
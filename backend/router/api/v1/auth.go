@@ -1,20 +1,24 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"log"
 	"net/http"
-	"os"
 	"strconv"
+	"strings"
 
-	"github.com/alexedwards/argon2id"
 	"github.com/astaxie/beego/validation"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 	"github.com/urento/shoppinglist/models"
 	"github.com/urento/shoppinglist/pkg/app"
 	"github.com/urento/shoppinglist/pkg/cache"
 	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/emailotp"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"github.com/urento/shoppinglist/pkg/metrics"
+	"github.com/urento/shoppinglist/pkg/password"
 	"github.com/urento/shoppinglist/pkg/totp"
 	"github.com/urento/shoppinglist/pkg/util"
 )
@@ -32,28 +36,24 @@ func Check(c *gin.Context) {
 
 	token, err := GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
 	if len(token) <= 0 {
-		appGin.Response(http.StatusBadRequest, e.INVALID_PARAMS, map[string]string{"success": "false"})
+		appGin.Error(e.New(http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
-	email, err := cache.GetEmailByJWT(token)
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil || len(email) <= 0 {
-		appGin.Response(http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{"success": "false"})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	check, err := cache.Check(email, token)
+	check, err := cache.Check(c.Request.Context(), email, token)
 	if !check || err != nil {
-		appGin.Response(http.StatusBadRequest, e.ERROR_TOKEN_INVALID, map[string]string{"success": "false"})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_TOKEN_INVALID))
 		return
 	}
 
@@ -65,28 +65,24 @@ func GetUser(c *gin.Context) {
 
 	token, err := GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
 	if len(token) <= 0 {
-		appGin.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		appGin.Error(e.New(http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
-	email, err := cache.GetEmailByJWT(token)
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil || len(email) <= 0 {
-		appGin.Response(http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	data, err := models.GetUser(email)
+	data, err := stores.Auth.GetUser(c.Request.Context(), email)
 	if err != nil {
-		appGin.Response(http.StatusBadRequest, e.ERROR_RETRIEVING_USER_DATA, nil)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_RETRIEVING_USER_DATA))
 		return
 	}
 
@@ -107,77 +103,63 @@ func UpdateUser(c *gin.Context) {
 
 	token, err := GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
 	var data UpdateUserStruct
 
 	if err := c.BindJSON(&data); err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA, nil)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
-	email, err := cache.GetEmailByJWT(token)
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_AUTH_CHECK_TOKEN_FAIL))
 		return
 	}
 
 	b, err := json.Marshal(data)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_BINDING_JSON_DATA, map[string]string{
-			"success": "false",
-			"message": "error decoding struct",
-		})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
 	var lokifdgh models.Auth
 	if err := json.Unmarshal([]byte(b), &lokifdgh); err != nil {
-		appGin.Response(http.StatusInternalServerError, e.ERROR_BINDING_JSON_DATA, map[string]string{
-			"success": "false",
-			"message": "error decoding struct 2",
-		})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
+	//the email is keyed everywhere, so it can only be changed through RequestEmailChange
+	lokifdgh.EMail = ""
+
 	if data.WithPassword {
 		//TODO: maybe even check the cache and not postgres
-		ok, err := models.CheckPassword(email, data.OldPassword)
+		ok, err := stores.Auth.CheckPassword(c.Request.Context(), email, data.OldPassword)
 		if !ok || err != nil {
-			appGin.Response(http.StatusBadRequest, e.ERROR_WRONG_OLD_PASSWORD, map[string]string{
-				"success": "false",
-				"message": "Old Password is incorrect!",
-			})
+			appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_WRONG_OLD_PASSWORD))
 			return
 		}
 
-		passwordHash, err := argon2id.CreateHash(lokifdgh.Password, argon2id.DefaultParams)
+		if err := password.Validate(email, lokifdgh.Password); err != nil {
+			PasswordPolicyResponse(&appGin, err)
+			return
+		}
+
+		passwordHash, err := password.Hash(lokifdgh.Password)
 		if err != nil {
-			appGin.Response(http.StatusInternalServerError, e.ERROR_ENCRYPTING_PASSWORD, map[string]string{
-				"success": "false",
-				"message": "error encrypting password",
-			})
+			appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_ENCRYPTING_PASSWORD))
 			return
 		}
 
 		lokifdgh.Password = passwordHash
 	}
 
-	err = lokifdgh.UpdateUser(email)
+	err = lokifdgh.UpdateUser(c.Request.Context(), email)
 	if err != nil {
-		appGin.Response(http.StatusInternalServerError, e.ERROR_UPDATING_USER, map[string]string{
-			"success": "false",
-			"message": "error updating user",
-		})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_UPDATING_USER))
 		return
 	}
 
@@ -198,8 +180,7 @@ func Login(c *gin.Context) {
 	var user LoginUser
 
 	if err := c.BindJSON(&user); err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA, nil)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
@@ -210,72 +191,107 @@ func Login(c *gin.Context) {
 	a := Auth{Email: email, Password: password}
 	ok, err := valid.Valid(&a)
 	if !ok {
-		log.Print(err)
 		app.MarkErrors(valid.Errors)
-		appGin.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
 	// if the user doesnt have a valid token in cache = generate new one
-	exists, err := models.CheckAuth(email, password, ip)
-	if err != nil && err.Error() == "too many failed login attempts" {
-		appGin.Response(http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL, map[string]string{
-			"success": "false",
-			"error":   "too many failed login attempts",
-		})
+	exists, err := models.CheckAuth(c.Request.Context(), email, password, ip)
+	//successful logins are recorded once the second factor is verified, or below if there is none
+	if err != nil || !exists {
+		metrics.ObserveLogin(models.LoginMethodPassword, false)
+		if recordErr := models.RecordLogin(c.Request.Context(), email, ip, c.Request.UserAgent(), models.LoginMethodPassword, false); recordErr != nil {
+			logging.Error(c, recordErr)
+		}
+	}
+
+	var lockedErr *models.LockedError
+	if errors.As(err, &lockedErr) {
+		c.Header("Retry-After", strconv.Itoa(int(lockedErr.Remaining.Seconds())))
+		appGin.Error(e.New(http.StatusTooManyRequests, e.ERROR_TOO_MANY_LOGIN_ATTEMPTS).WithDetail("error", "too many failed login attempts").WithDetail("retry_after", strconv.Itoa(int(lockedErr.Remaining.Seconds()))))
 		return
 	}
 	if err != nil {
-		appGin.Response(http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL, map[string]string{
-			"success": "false",
-			"error":   "wrong email or password",
-		})
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_AUTH_CHECK_TOKEN_FAIL))
 		return
 	}
 
 	if !exists {
-		appGin.Response(http.StatusUnauthorized, e.ERROR_AUTH, map[string]string{
-			"success": "false",
-			"error":   "wrong email or password",
-		})
+		appGin.Error(e.New(http.StatusUnauthorized, e.ERROR_AUTH))
 		return
 	}
 
-	has, err := cache.IsTOTPSecretCached(email)
+	has, err := models.HasTOTPSecret(c.Request.Context(), email)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusUnauthorized, e.ERROR_CHECKING_IF_TOTP_IS_ENABLED, map[string]string{
-			"success": "false",
-			"error":   "error while getting totp",
-		})
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_CHECKING_IF_TOTP_IS_ENABLED))
 		return
 	}
 
-	if has {
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
+		return
+	}
+
+	hasWebAuthn, err := models.HasWebAuthnCredential(c.Request.Context(), userId)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_CHECKING_IF_WEBAUTHN_IS_ENABLED))
+		return
+	}
+
+	enabled, err := stores.Auth.IsTwoFactorEnabled(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_CHECKING_IF_TOTP_IS_ENABLED))
+		return
+	}
+
+	method, err := stores.Auth.GetTwoFactorMethod(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_CHECKING_IF_TOTP_IS_ENABLED))
+		return
+	}
+
+	emailOTP := enabled && method == models.TwoFactorMethodEmail
+	if emailOTP {
+		err = emailotp.Send(c.Request.Context(), email)
+		if err != nil {
+			appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_SENDING_EMAIL_OTP))
+			return
+		}
+	}
+
+	if has || emailOTP || hasWebAuthn {
+		//the second factor has to be provided in the next few minutes
+		err = cache.SetMFAPending(c.Request.Context(), email)
+		if err != nil {
+			appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_AUTH))
+			return
+		}
+
 		appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
-			"success": "true",
-			"otp":     "true",
+			"success":  "true",
+			"otp":      strconv.FormatBool(has || emailOTP),
+			"method":   method,
+			"webauthn": strconv.FormatBool(hasWebAuthn),
 		})
 		return
 	}
 
-	token, err := util.GenerateToken(email, false)
+	metrics.ObserveLogin(models.LoginMethodPassword, true)
+	if recordErr := models.RecordLogin(c.Request.Context(), email, ip, c.Request.UserAgent(), models.LoginMethodPassword, true); recordErr != nil {
+		logging.Error(c, recordErr)
+	}
+
+	token, err := util.GenerateToken(c.Request.Context(), email, false)
 	if err != nil {
-		appGin.Response(http.StatusInternalServerError, e.ERROR_AUTH_TOKEN, map[string]string{
-			"success": "false",
-			"error":   "error while generating jwt",
-		})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_AUTH_TOKEN))
 		return
 	}
 
 	err = SetCookie(c, token)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_SETTING_SESSION_TOKEN, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-			"otp":     "false",
-		})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_SETTING_SESSION_TOKEN).WithDetail("otp", "false"))
 		return
 	}
 
@@ -286,6 +302,48 @@ func Login(c *gin.Context) {
 	})
 }
 
+type UnlockRequest struct {
+	Email string `json:"email"`
+	Token string `json:"token"`
+}
+
+func RequestUnlock(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	var data UnlockRequest
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
+		return
+	}
+
+	err := models.SendUnlockEmail(c.Request.Context(), data.Email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_SENDING_UNLOCK_EMAIL))
+		return
+	}
+
+	//always respond with success so the route can't be used to check which emails are registered
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{"success": "true"})
+}
+
+func Unlock(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	var data UnlockRequest
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
+		return
+	}
+
+	err := models.UnlockAccount(c.Request.Context(), data.Token, c.ClientIP())
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_UNLOCK_TOKEN_INVALID))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{"success": "true"})
+}
+
 type LogoutSettings struct {
 	LogoutEveryone bool `json:"logout_everyone"`
 }
@@ -295,41 +353,30 @@ func Logout(c *gin.Context) {
 
 	token, err := GetCookie(c)
 	if err != nil {
-		appGin.Response(http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED, map[string]string{
-			"success": "false",
-			"message": "You have to be logged in to log out!",
-		})
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED))
 		return
 	}
 
 	var logoutSettings LogoutSettings
 
 	if err := c.BindJSON(&logoutSettings); err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA, nil)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
-	email, err := cache.GetEmailByJWT(token)
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil || len(email) <= 0 {
-		appGin.Response(http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"message": "jwt token is invalid",
-		})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
 	//if you want to invalidate all jwt tokens and log everyone out
 	if logoutSettings.LogoutEveryone {
-		/*err := cache.InvalidateAllJWTTokens(email)
+		err := cache.InvalidateAllJWTTokens(c.Request.Context(), email)
 		if err != nil {
-			log.Print(err)
-			appGin.Response(http.StatusInternalServerError, e.ERROR_INVALIDATING_JWT_TOKENS, map[string]string{
-				"success": "false",
-				"message": "error while invalidating jwt token",
-			})
-		}*/
-		//TODO
+			appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_INVALIDATING_JWT_TOKENS))
+			return
+		}
 
 		RemoveCookie(c)
 
@@ -340,20 +387,15 @@ func Logout(c *gin.Context) {
 	}
 
 	if len(token) <= 0 {
-		appGin.Response(http.StatusBadRequest, e.INVALID_PARAMS, map[string]string{
-			"success": "false",
-		})
+		appGin.Error(e.New(http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
 	//if its just a normal logout
-	ok, err := cache.DeleteTokenByEmail(email, token)
+	ok, err := cache.DeleteTokenByEmail(c.Request.Context(), email, token)
 	if err != nil || !ok {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_WHILE_INVALIDATING_TOKEN, map[string]string{
-			"success": strconv.FormatBool(ok),
-			"error":   "error while invalidating token",
-		})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_WHILE_INVALIDATING_TOKEN))
+		return
 	}
 
 	RemoveCookie(c)
@@ -372,65 +414,155 @@ func InvalidateSpecificJWTToken(c *gin.Context) {
 
 	token, err := GetCookie(c)
 	if err != nil {
-		appGin.Response(http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED, map[string]string{
-			"success": "false",
-			"message": "You have to be logged in to log out!",
-		})
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED))
 		return
 	}
 
 	var jwtTokenSettings InvalidateSpecificJWTTokenStruct
 
 	if err := c.BindJSON(&jwtTokenSettings); err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA, nil)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
-	email, err := cache.GetEmailByJWT(token)
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil || len(email) <= 0 {
-		appGin.Response(http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"message": "jwt token is invalid",
-		})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	ok, err := cache.DoesTokenBelongToEmail(email, jwtTokenSettings.JWTToken)
+	ok, err := cache.DoesTokenBelongToEmail(c.Request.Context(), email, jwtTokenSettings.JWTToken)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_JWT_TOKEN_DOES_NOT_BELONG_TO_EMAIL, map[string]string{
-			"success": "false",
-			"message": "JWT Token does not belong to your account!",
-		})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_JWT_TOKEN_DOES_NOT_BELONG_TO_EMAIL))
 		return
 	}
 
 	if !ok {
-		appGin.Response(http.StatusBadRequest, e.ERROR_JWT_TOKEN_DOES_NOT_BELONG_TO_EMAIL, map[string]string{
-			"success": "false",
-			"message": "JWT Token does not belong to your account!",
-		})
+		appGin.Error(e.New(http.StatusBadRequest, e.ERROR_JWT_TOKEN_DOES_NOT_BELONG_TO_EMAIL))
 		return
 	}
 
-	err = cache.InvalidateSpecificJWTToken(email, jwtTokenSettings.JWTToken)
+	err = cache.InvalidateSpecificJWTToken(c.Request.Context(), email, jwtTokenSettings.JWTToken)
 	if err != nil {
-		appGin.Response(http.StatusInternalServerError, e.ERROR_INVALIDATING_JWT_TOKENS, map[string]string{
-			"success": "false",
-			"message": "An error occurred while invalidating a specific JWT Token!",
-		})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_INVALIDATING_JWT_TOKENS))
 		return
 	}
 
-	appGin.Response(http.StatusBadRequest, e.ERROR_JWT_TOKEN_DOES_NOT_BELONG_TO_EMAIL, map[string]string{
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
 		"success": "true",
 	})
 }
 
 func RemoveCookie(ctx *gin.Context) {
-	domain := os.Getenv("DOMAIN")
-	ctx.SetCookie("token", "", -1, "/", domain, util.IsProd(), true)
+	util.SetCookie(ctx, "token", "", -1)
+}
+
+type DeactivateAccountRequest struct {
+	Password string `json:"password"`
+	OTP      string `json:"otp"`
+}
+
+func DeactivateAccount(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	token, err := GetCookie(c)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED))
+		return
+	}
+
+	var data DeactivateAccountRequest
+
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
+		return
+	}
+
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
+	if err != nil || len(email) <= 0 {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT))
+		return
+	}
+
+	//the user has to authenticate again before deactivating the account
+	ok, err := stores.Auth.CheckPassword(c.Request.Context(), email, data.Password)
+	if err != nil || !ok {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_REAUTHENTICATION_FAILED))
+		return
+	}
+
+	enabled, err := stores.Auth.IsTwoFactorEnabled(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_CHECKING_IF_TOTP_IS_ENABLED))
+		return
+	}
+
+	if enabled {
+		if sendEmailCodeIfMissing(&appGin, email, data.OTP) {
+			return
+		}
+
+		ok, err := verifySecondFactor(c.Request.Context(), email, data.OTP)
+		if err != nil || !ok {
+			appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_REAUTHENTICATION_FAILED))
+			return
+		}
+	}
+
+	err = models.DeactivateAccount(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_DEACTIVATING_ACCOUNT))
+		return
+	}
+
+	err = cache.InvalidateAllJWTTokens(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_INVALIDATING_JWT_TOKENS))
+		return
+	}
+
+	RemoveCookie(c)
+
+	deletionDate, err := models.GetDeletionDate(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_DEACTIVATING_ACCOUNT))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
+		"success":       "true",
+		"deletion_date": strconv.FormatInt(deletionDate.Unix(), 10),
+	})
+}
+
+func GetLoginEvents(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	token, err := GetCookie(c)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED))
+		return
+	}
+
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
+	if err != nil || len(email) <= 0 {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT))
+		return
+	}
+
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
+		return
+	}
+
+	events, err := models.GetLoginEvents(c.Request.Context(), userId)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_LOGIN_EVENTS))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, events)
 }
 
 type RegisterUser struct {
@@ -446,43 +578,36 @@ func CreateAccount(c *gin.Context) {
 	var user RegisterUser
 
 	if err := c.BindJSON(&user); err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA, nil)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
 	email := user.Email
 	username := user.Username
-	password := user.Password
+	pwd := user.Password
 
 	if len(username) > 32 {
-		appGin.Response(http.StatusBadRequest, e.ERROR_USERNAME_TOO_LONG, map[string]string{
-			"success": "false",
-			"error":   "username can not be longer than 32 characters",
-		})
+		appGin.Error(e.New(http.StatusBadRequest, e.ERROR_USERNAME_TOO_LONG).WithDetail("error", "username can not be longer than 32 characters"))
 		return
 	}
 
 	ip := c.ClientIP()
 
-	a := Auth{Email: email, Username: username, Password: password}
+	a := Auth{Email: email, Username: username, Password: pwd}
 	ok, _ := valid.Valid(a)
 	if !ok {
 		app.MarkErrors(valid.Errors)
-		appGin.Response(http.StatusBadRequest, e.INVALID_PARAMS, map[string]string{
-			"error": "validation error",
-		})
+		appGin.Error(e.New(http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
-	err := models.CreateAccount(email, username, password, ip)
+	err := stores.Auth.CreateAccount(c.Request.Context(), email, username, pwd, ip)
 	if err != nil {
-		log.Print(err)
+		if PasswordPolicyResponse(&appGin, err) {
+			return
+		}
 		app.MarkErrors(valid.Errors)
-		appGin.Response(http.StatusBadRequest, e.ERROR_CREATING_ACCOUNT, map[string]string{
-			"error":   "email is already being used",
-			"success": "false",
-		})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_CREATING_ACCOUNT))
 		return
 	}
 
@@ -505,41 +630,35 @@ func UpdateEmailVerified(c *gin.Context) {
 type TwoFactorAuthentictionUpdate struct {
 	OTP    string `json:"otp"`
 	Status bool   `json:"status"`
+	Method string `json:"method"`
 }
 
 func UpdateTwoFactorAuthentication(c *gin.Context) {
 	appGin := app.Gin{C: c}
 	token, err := GetCookie(c)
 	if err != nil {
-		appGin.Response(http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED, map[string]string{
-			"success":  "false",
-			"message":  "You have to be logged in to log out!",
-			"verified": "false",
-		})
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED).WithDetail("verified", "false"))
 		return
 	}
 
 	var data TwoFactorAuthentictionUpdate
 
 	if err := c.BindJSON(&data); err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA, map[string]string{"success": "false", "verified": "false"})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA).WithDetail("verified", "false"))
 		return
 	}
 
-	email, err := cache.GetEmailByJWT(token)
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{"success": "false", "verified": "false"})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT).WithDetail("verified", "false"))
 		return
 	}
 
 	//check if the two factor authentication status is the same as in the cache; if yes = dont process the request
 	//TODO: UNCOMMENT ONCE I IMPLEMENT USER CACHING
-	/*currentStatus, err := cache.GetTwoFactorAuthenticationStatus(email)
+	/*currentStatus, err := cache.GetTwoFactorAuthenticationStatus(c.Request.Context(), email)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE, nil)
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE))
 		return
 	}
 
@@ -551,10 +670,9 @@ func UpdateTwoFactorAuthentication(c *gin.Context) {
 		return
 	}*/
 
-	enabled, err := models.IsTwoFactorEnabled(email)
+	enabled, err := stores.Auth.IsTwoFactorEnabled(c.Request.Context(), email)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE, map[string]string{"success": "false", "verified": "false"})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE).WithDetail("verified", "false"))
 		return
 	}
 
@@ -567,6 +685,22 @@ func UpdateTwoFactorAuthentication(c *gin.Context) {
 		return
 	}
 
+	if !enabled && data.Method == models.TwoFactorMethodEmail {
+		//the method is only switched after the user entered the code from the email
+		err = emailotp.Send(c.Request.Context(), email)
+		if err != nil {
+			appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_SENDING_EMAIL_OTP).WithDetail("verified", "false"))
+			return
+		}
+
+		appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
+			"success":  "true",
+			"verified": "true",
+			"method":   models.TwoFactorMethodEmail,
+		})
+		return
+	}
+
 	if !enabled {
 		bytes := totp.Enable(email, &appGin)
 		qrCode := totp.GetQRCodeBase64String(email, bytes)
@@ -579,20 +713,74 @@ func UpdateTwoFactorAuthentication(c *gin.Context) {
 		return
 	}
 
-	ok, err := totp.Verify(email, data.OTP, false)
+	if sendEmailCodeIfMissing(&appGin, email, data.OTP) {
+		return
+	}
+
+	method, err := stores.Auth.GetTwoFactorMethod(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE).WithDetail("verified", "false"))
+		return
+	}
+
+	ok, err := verifySecondFactor(c.Request.Context(), email, data.OTP)
 	if err != nil || !ok {
-		appGin.Response(http.StatusBadRequest, e.ERROR_VERIFYING_OTP, map[string]string{"success": "false", "message": "OTP was wrong"})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_VERIFYING_OTP))
+		return
+	}
+
+	if method == models.TwoFactorMethodEmail {
+		err = emailotp.Disable(c.Request.Context(), email)
+		if err != nil {
+			appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_CHANING_TWOFACTORAUTHENTICATION_STATUS))
+			return
+		}
+
+		appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{"success": "true", "verified": "true"})
 		return
 	}
 
 	totp.Disable(email, &appGin)
 }
 
+// verifySecondFactor checks the code with the 2FA method the user enabled
+func verifySecondFactor(ctx context.Context, email, otp string) (bool, error) {
+	method, err := stores.Auth.GetTwoFactorMethod(ctx, email)
+	if err != nil {
+		return false, err
+	}
+
+	if method == models.TwoFactorMethodEmail {
+		return emailotp.Verify(ctx, email, otp)
+	}
+	return totp.Verify(ctx, email, otp)
+}
+
+// sendEmailCodeIfMissing emails a code to users with email 2FA that didn't provide one yet and reports if it responded
+func sendEmailCodeIfMissing(appGin *app.Gin, email, otp string) bool {
+	if len(otp) > 0 {
+		return false
+	}
+
+	method, err := stores.Auth.GetTwoFactorMethod(appGin.C.Request.Context(), email)
+	if err != nil || method != models.TwoFactorMethodEmail {
+		return false
+	}
+
+	err = emailotp.Send(appGin.C.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_SENDING_EMAIL_OTP))
+		return true
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{"success": "false", "otp_sent": "true"})
+	return true
+}
+
 type VerifyTOTP struct {
-	Email       string `json:"email"`
-	OTP         string `json:"otp"`
-	LoginAfter  bool   `json:"login_after"`
-	EnableAfter bool   `json:"enable_after"`
+	Email      string `json:"email"`
+	OTP        string `json:"otp"`
+	LoginAfter bool   `json:"login_after"`
 }
 
 func VerifyTwoFactorAuthentication(c *gin.Context) {
@@ -601,63 +789,148 @@ func VerifyTwoFactorAuthentication(c *gin.Context) {
 	var data VerifyTOTP
 
 	if err := c.BindJSON(&data); err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA, map[string]string{"success": "false", "verified": "false"})
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA).WithDetail("verified", "false"))
 		return
 	}
 
 	email := data.Email
 
-	enabled, err := models.IsTwoFactorEnabled(email)
+	//the route doesn't need a session, so the password has to be checked first
+	pending, err := cache.IsMFAPending(c.Request.Context(), email)
+	if err != nil || !pending {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED).WithDetail("verified", "false"))
+		return
+	}
+
+	enabled, err := stores.Auth.IsTwoFactorEnabled(c.Request.Context(), email)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE, map[string]string{"success": "false", "verified": "false"})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE).WithDetail("verified", "false"))
 		return
 	}
 
-	if !enabled && !data.EnableAfter {
-		appGin.Response(http.StatusInternalServerError, e.ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE, map[string]string{
-			"success":  "false",
-			"message":  "TOTP not activated!",
-			"verified": "false",
-		})
+	if !enabled {
+		appGin.Error(e.New(http.StatusInternalServerError, e.ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE).WithDetail("verified", "false"))
+		return
+	}
+
+	method, err := stores.Auth.GetTwoFactorMethod(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_TWOFACTORAUTHENTICATION_STATUS_FROM_CACHE).WithDetail("verified", "false"))
+		return
+	}
+
+	var ok bool
+	var drift int
+	loginMethod := models.LoginMethodTOTP
+	if method == models.TwoFactorMethodEmail {
+		loginMethod = models.LoginMethodEmailOTP
+		ok, err = emailotp.Verify(c.Request.Context(), email, data.OTP)
+	} else {
+		var res totp.Result
+		res, err = totp.VerifyStep(c.Request.Context(), email, data.OTP)
+		ok, drift = res.Valid, res.Drift
+	}
+
+	if data.LoginAfter {
+		metrics.ObserveLogin(loginMethod, err == nil && ok)
+		if recordErr := models.RecordLogin(c.Request.Context(), email, c.ClientIP(), c.Request.UserAgent(), loginMethod, err == nil && ok); recordErr != nil {
+			logging.Error(c, recordErr)
+		}
+	}
+
+	metrics.ObserveTwoFactorVerification(loginMethod, err == nil && ok)
+
+	if errors.Is(err, emailotp.ErrTooManyAttempts) {
+		appGin.Error(e.New(http.StatusTooManyRequests, e.ERROR_TOO_MANY_OTP_ATTEMPTS).WithDetail("verified", "false"))
 		return
 	}
 
-	ok, err := totp.Verify(email, data.OTP, data.EnableAfter)
 	if err != nil || !ok {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_VERIFYING_OTP, map[string]string{"success": "false", "verified": "false"})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_VERIFYING_OTP).WithDetail("verified", "false"))
 		return
 	}
 
 	if data.LoginAfter && ok {
-		token, err := util.GenerateToken(email, false)
+		//a verified code can only be used for one session
+		pending, err := cache.ConsumeMFAPending(c.Request.Context(), email)
+		if err != nil || !pending {
+			appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED).WithDetail("verified", "false"))
+			return
+		}
+
+		token, err := util.GenerateToken(c.Request.Context(), email, false)
 		if err != nil {
-			appGin.Response(http.StatusInternalServerError, e.ERROR_AUTH_TOKEN, nil)
+			appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_AUTH_TOKEN))
 			return
 		}
 
 		err = SetCookie(c, token)
 		if err != nil {
-			log.Print(err)
-			appGin.Response(http.StatusInternalServerError, e.ERROR_SETTING_SESSION_TOKEN, map[string]string{
-				"error":    err.Error(),
-				"token":    token,
-				"success":  "false",
-				"verified": "false",
-			})
+			appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_SETTING_SESSION_TOKEN).WithDetail("verified", "false"))
 			return
 		}
 		appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
 			"success":  "true",
 			"verified": "true",
 			"token":    token,
+			"drift":    strconv.Itoa(drift),
 		})
 		return
 	}
 
-	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{"success": "true", "verified": "true"})
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{"success": "true", "verified": "true", "drift": strconv.Itoa(drift)})
+}
+
+type ConfirmTOTP struct {
+	OTP    string `json:"otp"`
+	Method string `json:"method"`
+}
+
+func ConfirmTwoFactorAuthentication(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	token, err := GetCookie(c)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED).WithDetail("verified", "false"))
+		return
+	}
+
+	var data ConfirmTOTP
+
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA).WithDetail("verified", "false"))
+		return
+	}
+
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT).WithDetail("verified", "false"))
+		return
+	}
+
+	confirmEnrollment(&appGin, email, data.OTP, data.Method)
+}
+
+// confirmEnrollment enables 2FA only after the user proved that the authenticator app or the email works
+func confirmEnrollment(appGin *app.Gin, email, otp, method string) {
+	var codes pq.StringArray
+	var err error
+	if method == models.TwoFactorMethodEmail {
+		codes, err = emailotp.Confirm(appGin.C.Request.Context(), email, otp)
+	} else {
+		codes, err = totp.Confirm(appGin.C.Request.Context(), email, otp)
+	}
+
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_VERIFYING_OTP).WithDetail("verified", "false"))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
+		"success":  "true",
+		"verified": "true",
+		"codes":    strings.Join(codes, ","),
+	})
 }
 
 type ResetPasswordRequest struct {
@@ -670,46 +943,47 @@ func ResetPasswordFromUser(c *gin.Context) {
 	var form ResetPasswordRequest
 
 	if err := c.BindJSON(&form); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA, map[string]string{"success": "false", "verified": "false"})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA).WithDetail("verified", "false"))
 		return
 	}
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	email, err := cache.GetEmailByJWT(token)
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	err = models.ResetPasswordFromUser(email, form.Password, form.OldPassword, true)
+	err = models.ResetPasswordFromUser(c.Request.Context(), email, form.Password, form.OldPassword, true)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while resetting password from user",
-		})
+		if PasswordPolicyResponse(&appG, err) {
+			return
+		}
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_UPDATING_USER))
 		return
 	}
 
 	appG.Response(http.StatusOK, e.SUCCESS, map[string]string{"success": "true"})
 }
 
+// PasswordPolicyResponse responds with the violated rule if err is a password policy error
+func PasswordPolicyResponse(appGin *app.Gin, err error) bool {
+	var policyErr *password.PolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	appGin.Error(e.New(http.StatusBadRequest, policyErr.Code).WithDetail("error", policyErr.Message))
+	return true
+}
+
 func SetCookie(ctx *gin.Context, token string) error {
-	domain := os.Getenv("DOMAIN")
-	ctx.SetCookie("token", token, 24*60*60, "/", domain, util.IsProd(), true)
+	util.SetCookie(ctx, "token", token, 24*60*60)
 	return nil
 }
 
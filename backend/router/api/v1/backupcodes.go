@@ -1,8 +1,8 @@
 package api
 
 import (
-	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -10,6 +10,8 @@ import (
 	"github.com/urento/shoppinglist/pkg/app"
 	"github.com/urento/shoppinglist/pkg/cache"
 	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"github.com/urento/shoppinglist/pkg/metrics"
 	"github.com/urento/shoppinglist/pkg/util"
 )
 
@@ -23,25 +25,19 @@ func VerifyBackupCode(c *gin.Context) {
 
 	var f VerifyBackupCodes
 	if err := c.BindJSON(&f); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-			"ok":      "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA).WithDetail("ok", "false"))
 		return
 	}
 
-	//TODO: add custom ratelimiter and/or some other form of security
+	resetToken, err := models.VerifyCodeForReset(c.Request.Context(), f.Owner, f.Code)
+	ok := len(resetToken) > 0
+	metrics.ObserveLogin(models.LoginMethodBackupCode, err == nil && ok)
+	if recordErr := models.RecordLogin(c.Request.Context(), f.Owner, c.ClientIP(), c.Request.UserAgent(), models.LoginMethodBackupCode, err == nil && ok); recordErr != nil {
+		logging.Error(c, recordErr)
+	}
 
-	ok, err := models.VerifyCode(f.Owner, f.Code)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while verifying backupcode",
-			"success": "false",
-			"ok":      "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_VERIFYING_BACKUP_CODE).WithDetail("ok", "false"))
 		return
 	}
 
@@ -54,9 +50,11 @@ func VerifyBackupCode(c *gin.Context) {
 		return
 	}
 
+	//the token allows one password change with ChangePassword
 	appG.Response(http.StatusOK, e.SUCCESS, map[string]string{
 		"success": "true",
 		"ok":      "true",
+		"token":   resetToken,
 	})
 }
 
@@ -65,35 +63,25 @@ func GenerateCodes(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	userId, err := models.GetUserIDByEmail(owner)
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
 		return
 	}
 
-	codes, err := models.GenerateCodes(owner, userId, false, true)
+	codes, err := models.GenerateCodes(c.Request.Context(), owner, userId, false, true)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"success": "false",
-			"error":   "error while regenerating codes",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GENERATING_BACKUP_CODES))
 		return
 	}
 
@@ -108,35 +96,25 @@ func RegenerateCodes(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	userId, err := models.GetUserIDByEmail(owner)
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
 		return
 	}
 
-	codes, err := models.GenerateCodes(owner, userId, true, true)
+	codes, err := models.GenerateCodes(c.Request.Context(), owner, userId, true, true)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"success": "false",
-			"error":   "error while regenerating codes",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GENERATING_BACKUP_CODES))
 		return
 	}
 
@@ -151,34 +129,23 @@ func GetBackupCodes(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	has, err := models.HasCodes(owner)
+	has, err := models.HasCodes(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"success": "false",
-			"error":   "error while checking if the owner has backupcodes",
-			"has":     "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_BACKUP_CODES).WithDetail("has", "false"))
 		return
 	}
 
 	if !has {
-		log.Print(err)
 		appG.Response(http.StatusOK, e.SUCCESS, map[string]string{
 			"success": "false",
 			"has":     "false",
@@ -186,20 +153,16 @@ func GetBackupCodes(c *gin.Context) {
 		return
 	}
 
-	codes, err := models.GetCodes(owner)
+	//the codes are only stored as hashes, so only the number of unused codes can be shown
+	remaining, err := models.CountRemainingCodes(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"success": "false",
-			"error":   "error while getting codes",
-			"has":     "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_BACKUP_CODES).WithDetail("has", "false"))
 		return
 	}
 
 	appG.Response(http.StatusOK, e.SUCCESS, map[string]string{
-		"success": "true",
-		"codes":   strings.Join(codes, ","),
-		"has":     "true",
+		"success":   "true",
+		"remaining": strconv.FormatInt(remaining, 10),
+		"has":       "true",
 	})
 }
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/pkg/app"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/logging"
+)
+
+type RequestEmailChangeRequest struct {
+	Email string `json:"email" valid:"Required;Email"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" valid:"Required"`
+}
+
+func RequestEmailChange(c *gin.Context) {
+	appGin := app.Gin{C: c}
+	valid := validation.Validation{}
+
+	email, ok := emailFromCookie(&appGin)
+	if !ok {
+		return
+	}
+
+	var data RequestEmailChangeRequest
+
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
+		return
+	}
+
+	ok, err := valid.Valid(&data)
+	if !ok {
+		app.MarkErrors(valid.Errors)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.INVALID_PARAMS))
+		return
+	}
+
+	err = models.RequestEmailChange(c.Request.Context(), email, data.Email)
+	if errors.Is(err, models.ErrEmailTaken) {
+		appGin.Error(e.New(http.StatusConflict, e.ERROR_EMAIL_ALREADY_TAKEN))
+		return
+	}
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_REQUESTING_EMAIL_CHANGE))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
+		"success": "true",
+		"message": "a confirmation link was sent to the new email",
+	})
+}
+
+// ConfirmEmailChange is opened from the link sent to the new address, so it doesn't need a session
+func ConfirmEmailChange(c *gin.Context) {
+	appGin := app.Gin{C: c}
+	valid := validation.Validation{}
+
+	var data ConfirmEmailChangeRequest
+
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
+		return
+	}
+
+	ok, err := valid.Valid(&data)
+	if !ok {
+		app.MarkErrors(valid.Errors)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.INVALID_PARAMS))
+		return
+	}
+
+	newEmail, err := models.ConfirmEmailChange(c.Request.Context(), data.Token)
+	switch {
+	case errors.Is(err, models.ErrInvalidEmailChangeToken):
+		appGin.Error(e.New(http.StatusBadRequest, e.ERROR_EMAIL_CHANGE_TOKEN_INVALID))
+		return
+	case errors.Is(err, models.ErrEmailTaken):
+		appGin.Error(e.New(http.StatusConflict, e.ERROR_EMAIL_ALREADY_TAKEN))
+		return
+	case err != nil && len(newEmail) <= 0:
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_CHANGING_EMAIL))
+		return
+	case err != nil:
+		//the email was already changed, only the cleanup afterwards failed
+		logging.Error(c, err)
+	}
+
+	RemoveCookie(c)
+
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
+		"success": "true",
+		"email":   newEmail,
+	})
+}
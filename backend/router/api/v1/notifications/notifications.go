@@ -1,12 +1,10 @@
 package notifications_v1
 
 import (
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/urento/shoppinglist/models"
 	"github.com/urento/shoppinglist/pkg/app"
 	"github.com/urento/shoppinglist/pkg/cache"
 	"github.com/urento/shoppinglist/pkg/e"
@@ -18,32 +16,25 @@ func HasUnreadNotifications(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	userId, err := models.GetUserIDByEmail(owner)
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
 		return
 	}
 
-	has, err := models.HasUnreadNotifications(userId)
+	has, err := stores.Notifications.HasUnreadNotifications(c.Request.Context(), userId)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_CHECKING_HAS_UNREAD_NOTIFICATIONS, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_CHECKING_HAS_UNREAD_NOTIFICATIONS))
 		return
 	}
 
@@ -58,35 +49,25 @@ func MarkAllNotificationsAsRead(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	userId, err := models.GetUserIDByEmail(owner)
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
 		return
 	}
 
-	err = models.MarkAllNotificationsAsRead(userId)
+	err = stores.Notifications.MarkAllNotificationsAsRead(c.Request.Context(), userId)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while marking all notifications as read",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_MARKING_NOTIFICATIONS_AS_READ))
 		return
 	}
 
@@ -98,35 +79,25 @@ func GetNotifications(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	userId, err := models.GetUserIDByEmail(owner)
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
 		return
 	}
 
-	notifications, err := models.GetNotifications(userId)
+	notifications, err := stores.Notifications.GetNotifications(c.Request.Context(), userId)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while getting notifications",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_NOTIFICATIONS))
 		return
 	}
 
@@ -142,46 +113,32 @@ func DeleteNotification(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	userId, err := models.GetUserIDByEmail(owner)
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
 		return
 	}
 
 	var f NotificationRequest
 
 	if err := c.BindJSON(&f); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
-	err = models.DeleteNotification(userId, f.NotificationId)
+	err = stores.Notifications.DeleteNotification(c.Request.Context(), userId, f.NotificationId)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"success": "false",
-			"error":   "error while deleting notification",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_DELETING_NOTIFICATION))
 		return
 	}
 
@@ -193,45 +150,32 @@ func GetNotification(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	userId, err := models.GetUserIDByEmail(owner)
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
 		return
 	}
 
 	notificationId := c.Param("notification_id")
 	notificationIdAsInt, err := strconv.Atoi(notificationId)
 	if err != nil {
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_ID_IS_INVALID))
 		return
 	}
 
-	notification, err := models.GetNotification(userId, notificationIdAsInt)
+	notification, err := stores.Notifications.GetNotification(c.Request.Context(), userId, notificationIdAsInt)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"success": "false",
-			"error":   "error while getting notification",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_NOTIFICATIONS))
 		return
 	}
 
@@ -0,0 +1,95 @@
+package notifications_v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/models/memory"
+	"github.com/urento/shoppinglist/pkg/app"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/util"
+)
+
+type notificationsResponse struct {
+	Code int                   `json:"code"`
+	Data []models.Notification `json:"data"`
+}
+
+func TestNotifications(t *testing.T) {
+	ctx := context.Background()
+
+	gin.SetMode(gin.TestMode)
+
+	stores := memory.NewStores()
+	UseStores(stores)
+	cache.UseStore(cache.NewMemoryStore())
+
+	email := "user@gmail.com"
+	token := util.RandomString(80)
+
+	err := stores.Auth.CreateAccount(ctx, email, "user", "WbHu9+kLr!3xZpQa", "")
+	if err != nil {
+		t.Fatalf("Error while creating account: %s", err)
+	}
+
+	err = cache.CacheJWT(ctx, email, token)
+	if err != nil {
+		t.Fatalf("Error while caching jwt: %s", err)
+	}
+
+	userId, _ := stores.Auth.GetUserIDByEmail(ctx, email)
+	err = stores.Notifications.CreateNotification(ctx, models.Notification{UserID: userId, Title: "Hello", Text: "World"})
+	if err != nil {
+		t.Fatalf("Error while creating notification: %s", err)
+	}
+
+	r := gin.New()
+	r.Use(app.Errors())
+	r.GET("/notifications", GetNotifications)
+	r.GET("/notifications/n/hasunread", HasUnreadNotifications)
+	r.POST("/notifications/n/markall", MarkAllNotificationsAsRead)
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.AddCookie(&http.Cookie{Name: "token", Value: token})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("TestGetNotifications", func(t *testing.T) {
+		w := request(http.MethodGet, "/notifications")
+
+		var response notificationsResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Error while parsing response: %s", err)
+		}
+
+		Equal(t, http.StatusOK, w.Code)
+		Equal(t, 1, len(response.Data))
+		Equal(t, "Hello", response.Data[0].Title)
+	})
+
+	t.Run("TestMarkAllNotificationsAsRead", func(t *testing.T) {
+		w := request(http.MethodPost, "/notifications/n/markall")
+		Equal(t, http.StatusOK, w.Code)
+
+		w = request(http.MethodGet, "/notifications/n/hasunread")
+		Equal(t, http.StatusOK, w.Code)
+		Contains(t, w.Body.String(), `"has":"false"`)
+	})
+
+	t.Run("TestWithoutSession", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/notifications", nil))
+
+		Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,10 @@
+package notifications_v1
+
+import "github.com/urento/shoppinglist/models"
+
+var stores = models.NewGormStores()
+
+// UseStores sets the stores the handlers read and write through, e.g. the in-memory ones in tests
+func UseStores(s models.Stores) {
+	stores = s
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/middleware/reauth"
+	"github.com/urento/shoppinglist/pkg/app"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"github.com/urento/shoppinglist/pkg/util"
+)
+
+type ReauthenticateRequest struct {
+	Password string `json:"password"`
+	OTP      string `json:"otp"`
+}
+
+// Reauthenticate checks the password or, if 2FA is enabled, a code and sets the short-lived reauth cookie
+// that the sensitive routes require
+func Reauthenticate(c *gin.Context) {
+	appGin := app.Gin{C: c}
+	ctx := c.Request.Context()
+
+	token, err := GetCookie(c)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED))
+		return
+	}
+
+	var data ReauthenticateRequest
+
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
+		return
+	}
+
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
+	if err != nil || len(email) <= 0 {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT))
+		return
+	}
+
+	remaining, err := cache.GetLockout(ctx, email, c.ClientIP())
+	if err != nil {
+		logging.Error(c, err)
+	}
+
+	if remaining > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(remaining.Seconds())))
+		appGin.Error(e.New(http.StatusTooManyRequests, e.ERROR_TOO_MANY_LOGIN_ATTEMPTS).WithDetail("retry_after", strconv.Itoa(int(remaining.Seconds()))))
+		return
+	}
+
+	var ok bool
+	if len(data.Password) > 0 {
+		ok, err = stores.Auth.CheckPassword(c.Request.Context(), email, data.Password)
+	} else {
+		enabled, enabledErr := stores.Auth.IsTwoFactorEnabled(c.Request.Context(), email)
+		if enabledErr != nil {
+			appGin.Error(e.Wrap(enabledErr, http.StatusInternalServerError, e.ERROR_CHECKING_IF_TOTP_IS_ENABLED))
+			return
+		}
+
+		if enabled {
+			if sendEmailCodeIfMissing(&appGin, email, data.OTP) {
+				return
+			}
+			ok, err = verifySecondFactor(ctx, email, data.OTP)
+		}
+	}
+
+	if err != nil || !ok {
+		logging.Error(c, err)
+		if err := cache.RegisterFailedLogin(ctx, email, c.ClientIP()); err != nil {
+			logging.Error(c, err)
+		}
+		appGin.Error(e.New(http.StatusUnauthorized, e.ERROR_REAUTHENTICATION_FAILED))
+		return
+	}
+
+	reauthToken, err := util.GenerateReauthToken(email, token, reauth.TTL())
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_AUTH_TOKEN))
+		return
+	}
+
+	util.SetCookie(c, reauth.CookieName, reauthToken, int(reauth.TTL().Seconds()))
+
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
+		"success":    "true",
+		"expires_in": strconv.Itoa(int(reauth.TTL().Seconds())),
+	})
+}
@@ -1,87 +1,60 @@
 package api
 
 import (
-	"context"
-	"log"
 	"net/http"
 
 	"github.com/astaxie/beego/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/urento/shoppinglist/models"
 	"github.com/urento/shoppinglist/pkg/app"
-	"github.com/urento/shoppinglist/pkg/cache"
 	"github.com/urento/shoppinglist/pkg/e"
-	"github.com/urento/shoppinglist/pkg/util"
 )
 
 type ResetPassword struct {
 	Email string `valid:"Required"`
 }
 
-type VerifyId struct {
-	Email          string `valid:"Required"`
-	VerificationId string `valid:"Required"`
+type VerifyResetToken struct {
+	Email string `json:"email" valid:"Required"`
+	Token string `json:"token" valid:"Required"`
 }
 
+type ResetPasswordWithTokenRequest struct {
+	Email    string `json:"email" valid:"Required"`
+	Token    string `json:"token" valid:"Required"`
+	Password string `json:"password" valid:"Required"`
+}
+
+// SendResetPassword doesn't require a session because it's used by users who forgot their password
 func SendResetPassword(c *gin.Context) {
 	appGin := app.Gin{C: c}
 	valid := validation.Validation{}
 
-	token, err := util.GetCookie(c)
-	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
-		return
-	}
-
 	var resetPassword ResetPassword
 
 	if err := c.BindJSON(&resetPassword); err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA, nil)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
 	email := resetPassword.Email
 
-	emailByJwt, err := cache.GetEmailByJWT(token)
-	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
-		return
-	}
-
-	if emailByJwt != email {
-		appGin.Response(http.StatusBadRequest, e.ERROR_NOT_AUTHORIZED, map[string]string{
-			"message": "wrong email",
-			"success": "false",
-		})
-		return
-	}
-
 	ok, err := valid.Valid(&ResetPassword{Email: email})
 	if !ok {
-		log.Print(err)
 		app.MarkErrors(valid.Errors)
-		appGin.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
-	err = models.CreateResetPassword(email)
+	err = models.CreateResetPassword(c.Request.Context(), email)
 	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_SENDING_RESET_PASSWORD_EMAIL, map[string]string{
-			"message": "error while sending reset password email",
-			"success": "false",
-		})
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_SENDING_RESET_PASSWORD_EMAIL))
 		return
 	}
 
+	//the response is the same for unknown emails
 	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
-		"message": "successfully sent the reset password email",
+		"message": "if the account exists, a reset password email was sent",
 		"success": "true",
 	})
 }
@@ -90,72 +63,68 @@ func VerifyVerificationId(c *gin.Context) {
 	appGin := app.Gin{C: c}
 	valid := validation.Validation{}
 
-	token, err := util.GetCookie(c)
-	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
-		return
-	}
-
-	var resetPassword VerifyId
+	var data VerifyResetToken
 
-	if err := c.BindJSON(&resetPassword); err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA, nil)
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
-	email := resetPassword.Email
-	verificationId := resetPassword.VerificationId
-
-	emailByJwt, err := cache.GetEmailByJWT(token)
-	if err != nil {
-		log.Print(err)
-		appGin.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+	ok, err := valid.Valid(&data)
+	if !ok {
+		app.MarkErrors(valid.Errors)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
-	if emailByJwt != email {
-		appGin.Response(http.StatusBadRequest, e.ERROR_NOT_AUTHORIZED, map[string]string{
-			"message": "Wrong Email",
-			"success": "false",
-		})
+	correct, err := models.VerifyResetToken(c.Request.Context(), data.Email, data.Token)
+	if err != nil || !correct {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_VERIFYING_VERIFICATION_ID))
 		return
 	}
 
-	resetPwdObj := VerifyId{
-		Email:          email,
-		VerificationId: verificationId,
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
+		"success": "true",
+		"message": "Token is correct",
+	})
+}
+
+func ResetPasswordWithToken(c *gin.Context) {
+	appGin := app.Gin{C: c}
+	valid := validation.Validation{}
+
+	var data ResetPasswordWithTokenRequest
+
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
+		return
 	}
 
-	ok, err := valid.Valid(&resetPwdObj)
+	ok, err := valid.Valid(&data)
 	if !ok {
-		log.Print(err)
 		app.MarkErrors(valid.Errors)
-		appGin.Response(http.StatusBadRequest, e.INVALID_PARAMS, nil)
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
-	correct, err := models.VerifyVerificationID(email, verificationId)
-	if err != nil || !correct {
-		log.Print(err)
-		app.MarkErrors(valid.Errors)
-		appGin.Response(http.StatusBadRequest, e.ERROR_VERIFYING_VERIFICATION_ID, nil)
+	err = models.ResetPasswordWithToken(c.Request.Context(), data.Email, data.Token, data.Password)
+	if err != nil {
+		if PasswordPolicyResponse(&appGin, err) {
+			return
+		}
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_VERIFYING_VERIFICATION_ID))
 		return
 	}
 
 	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
 		"success": "true",
-		"message": "Verification ID is correct",
+		"message": "password was changed",
 	})
 }
 
 type ChangePasswordRequest struct {
-	Code     string `json:"code"`
 	Owner    string `json:"owner"`
+	Token    string `json:"token"`
 	Password string `json:"password"`
 }
 
@@ -164,63 +133,17 @@ func ChangePassword(c *gin.Context) {
 	var form ChangePasswordRequest
 
 	if err := c.BindJSON(&form); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA, map[string]string{
-			"success":  "false",
-			"verified": "false",
-			"ok":       "false",
-		})
-		return
-	}
-
-	ok, err := models.VerifyCode(form.Owner, form.Code)
-	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while verifying backupcode",
-			"success": "false",
-			"ok":      "false",
-		})
-		return
-	}
-
-	if !ok {
-		appG.Response(http.StatusOK, e.SUCCESS, map[string]string{
-			"error":   "code is incorrect",
-			"success": "false",
-			"ok":      "false",
-		})
-		return
-	}
-
-	canReset, err := cache.CanResetPassword(context.Background(), form.Owner)
-	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while checking if the user can reset password",
-			"ok":      "false",
-		})
-		return
-	}
-
-	if !canReset {
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "you can't reset your password",
-			"ok":      "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA).WithDetail("verified", "false").WithDetail("ok", "false"))
 		return
 	}
 
-	err = models.ResetPasswordFromUser(form.Owner, form.Password, "", false)
+	//the token is returned by VerifyBackupCode once the backup code was used up and can only be used once
+	err := models.ResetPasswordWithToken(c.Request.Context(), form.Owner, form.Token, form.Password)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while resetting password from user without old password",
-			"ok":      "true",
-		})
+		if PasswordPolicyResponse(&appG, err) {
+			return
+		}
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_VERIFYING_VERIFICATION_ID).WithDetail("ok", "false"))
 		return
 	}
 
@@ -1,7 +1,6 @@
 package v1
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -23,49 +22,36 @@ func AddParticipant(c *gin.Context) {
 	var f AddParticipantRequest
 
 	if err := c.BindJSON(&f); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
 	isValid := util.IsEmailValid(f.Email)
 	if !isValid {
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "email is not valid",
-			"success": "false",
-		})
+		appG.Error(e.New(http.StatusBadRequest, e.ERROR_EMAIL_INVALID))
 		return
 	}
 
-	included, err := models.IsParticipantAlreadyIncluded(f.Email, f.ParentListId)
-	if err != nil || included {
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "participant is already included",
-			"success": "false",
-		})
+	included, err := stores.Participants.IsParticipantAlreadyIncluded(c.Request.Context(), f.Email, f.ParentListId)
+	if err != nil {
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_ADDING_PARTICIPANT))
+		return
+	}
+
+	if included {
+		appG.Error(e.New(http.StatusConflict, e.ERROR_PARTICIPANT_ALREADY_INCLUDED))
 		return
 	}
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
@@ -76,12 +62,9 @@ func AddParticipant(c *gin.Context) {
 		RequestFrom:  owner,
 	}
 
-	participant, err := models.AddParticipant(p)
+	participant, err := stores.Participants.AddParticipant(c.Request.Context(), p)
 	if err != nil {
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while adding participant",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_ADDING_PARTICIPANT))
 		return
 	}
 
@@ -93,31 +76,19 @@ func GetPendingRequests(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	requests, err := models.GetPendingRequests(owner)
+	requests, err := stores.Participants.GetPendingRequests(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while getting pending requests",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_PARTICIPANTS))
 		return
 	}
 
@@ -133,41 +104,25 @@ func AcceptRequest(c *gin.Context) {
 	var f AcceptRequestRequest
 
 	if err := c.BindJSON(&f); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while getting email by jwt",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	err = models.AcceptRequest(f.ID, owner)
+	err = stores.Participants.AcceptRequest(c.Request.Context(), f.ID, owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while accepting the request",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_UPDATING_PARTICIPANT))
 		return
 	}
 
@@ -186,48 +141,30 @@ func DeleteRequest(c *gin.Context) {
 	var f DeleteRequestRequest
 
 	if err := c.BindJSON(&f); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
 	if owner != f.Email {
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "ja ja ja",
-		})
+		appG.Error(e.New(http.StatusForbidden, e.ERROR_NOT_AUTHORIZED))
 		return
 	}
 
-	err = models.DeleteRequest(f.ID, f.Email)
+	err = stores.Participants.DeleteRequest(c.Request.Context(), f.ID, f.Email)
 	if err != nil {
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while deleting the request",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_UPDATING_PARTICIPANT))
 		return
 	}
 
@@ -242,31 +179,19 @@ func GetGetPendingRequestsFromShoppinglist(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	requests, err := models.GetPendingRequestsFromShoppinglist(owner, id)
+	requests, err := stores.Participants.GetPendingRequestsFromShoppinglist(c.Request.Context(), owner, id)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while getting pending requests",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_PARTICIPANTS))
 		return
 	}
 
@@ -279,41 +204,30 @@ func GetParticipants(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
+		return
+	}
+
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
+	if err != nil {
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	belongs, err := stores.Shoppinglists.BelongsShoppinglistToEmail(c.Request.Context(), owner, id)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while getting email by jwt",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_CHECK_EXIST_LIST_FAIL))
 		return
 	}
 
-	belongs, err := models.BelongsShoppinglistToEmail(owner, id)
-	if err != nil || !belongs {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "shoppinglist doesn't belong to the given jwt token",
-		})
+	if !belongs {
+		appG.Error(models.ErrListNotOwned)
 		return
 	}
 
-	participants, err := models.GetParticipants(id)
+	participants, err := stores.Participants.GetParticipants(c.Request.Context(), id)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while getting participants",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_PARTICIPANTS))
 		return
 	}
 
@@ -327,41 +241,30 @@ func DeleteParticipant(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
+		return
+	}
+
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
+	if err != nil {
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	belongs, err := stores.Shoppinglists.BelongsShoppinglistToEmail(c.Request.Context(), owner, parentListId)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while getting email by jwt",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_CHECK_EXIST_LIST_FAIL))
 		return
 	}
 
-	belongs, err := models.BelongsShoppinglistToEmail(owner, parentListId)
-	if err != nil || !belongs {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "shoppinglist doesn't belong to the given jwt token",
-		})
+	if !belongs {
+		appG.Error(models.ErrListNotOwned)
 		return
 	}
 
-	err = models.RemoveParticipant(parentListId, id)
+	err = stores.Participants.RemoveParticipant(c.Request.Context(), parentListId, id)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while deleting participant",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_UPDATING_PARTICIPANT))
 		return
 	}
 
@@ -373,31 +276,19 @@ func DenyAllRequests(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while getting email by jwt",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	err = models.DeleteAll(owner)
+	err = stores.Participants.DeleteAll(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while deleting all requests",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_UPDATING_PARTICIPANT))
 		return
 	}
 
@@ -413,41 +304,25 @@ func LeaveShoppinglsit(c *gin.Context) {
 	var f LeaveShoppinglistRequest
 
 	if err := c.BindJSON(&f); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while getting email by jwt",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	err = models.LeaveShoppinglist(f.ID, owner)
+	err = stores.Participants.LeaveShoppinglist(c.Request.Context(), f.ID, owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while leaving shopppinglist",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_UPDATING_PARTICIPANT))
 		return
 	}
 
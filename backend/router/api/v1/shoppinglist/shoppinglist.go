@@ -2,7 +2,6 @@ package v1
 
 import (
 	"errors"
-	"log"
 	"net/http"
 	"strconv"
 
@@ -16,6 +15,11 @@ import (
 	"github.com/urento/shoppinglist/pkg/util"
 )
 
+type ShoppinglistResponse struct {
+	*models.Shoppinglist
+	IsParticipant bool `json:"is_participant"`
+}
+
 func GetShoppinglist(c *gin.Context) {
 	appG := app.Gin{C: c}
 	id := com.StrTo(c.Param("id")).MustInt()
@@ -24,81 +28,48 @@ func GetShoppinglist(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
 	if valid.HasErrors() {
 		app.MarkErrors(valid.Errors)
-		appG.Response(http.StatusBadRequest, e.INVALID_PARAMS, map[string]string{
-			"success": "false",
-		})
+		appG.Error(e.New(http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
-	isParticipant, err := models.IsParticipantAlreadyIncluded(owner, id)
+	isParticipant, err := stores.Participants.IsParticipantAlreadyIncluded(c.Request.Context(), owner, id)
 	if err != nil {
-		appG.Response(http.StatusBadRequest, e.ERROR_LIST_DOES_NOT_BELONG_TO_TOKEN, map[string]string{
-			"error":   "error while loading list",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GET_LIST_FAIL))
 		return
 	}
 
-	if !isParticipant {
-		list, err := models.GetList(id, owner)
-		if err != nil {
-			log.Print(err)
-			appG.Response(http.StatusInternalServerError, e.ERROR_GET_LIST_FAIL, map[string]string{
-				"success": "false",
-			})
-			return
-		}
-
-		if list.Owner != owner {
-			appG.Response(http.StatusBadRequest, e.ERROR_LIST_DOES_NOT_BELONG_TO_TOKEN, map[string]string{
-				"error":   "list does not belong to reuqest maker",
-				"success": "false",
-			})
-			return
-		}
-
-		c.JSON(200, gin.H{
-			"code":           200,
-			"message":        "success",
-			"data":           list,
-			"is_participant": isParticipant,
-		})
+	var list *models.Shoppinglist
+	if isParticipant {
+		list, err = stores.Shoppinglists.GetListWithoutOwner(c.Request.Context(), id)
 	} else {
-		list, err := models.GetListWithoutOwner(id)
-		if err != nil {
-			log.Print(err)
-			appG.Response(http.StatusInternalServerError, e.ERROR_GET_LIST_FAIL, map[string]string{
-				"success": "false",
-			})
-			return
-		}
+		list, err = stores.Shoppinglists.GetList(c.Request.Context(), id, owner)
+	}
+	if err != nil {
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GET_LIST_FAIL))
+		return
+	}
 
-		c.JSON(200, gin.H{
-			"code":           200,
-			"message":        "success",
-			"data":           list,
-			"is_participant": isParticipant,
-		})
+	if !isParticipant && list.Owner != owner {
+		appG.Error(models.ErrListNotOwned)
+		return
 	}
+
+	appG.Response(http.StatusOK, e.SUCCESS, ShoppinglistResponse{
+		Shoppinglist:  list,
+		IsParticipant: isParticipant,
+	})
 }
 
 func GetShoppinglists(c *gin.Context) {
@@ -111,11 +82,7 @@ func GetShoppinglists(c *gin.Context) {
 	} else {
 		offsetToInt, err := strconv.Atoi(offset)
 		if err != nil {
-			log.Print(err)
-			appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-				"error":   err.Error(),
-				"success": "false",
-			})
+			appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_OFFSET_INVALID))
 			return
 		}
 		o = offsetToInt
@@ -123,23 +90,19 @@ func GetShoppinglists(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	email, err := cache.GetEmailByJWT(token)
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	lists, err := models.GetListByEmail(email, o)
+	lists, err := stores.Shoppinglists.GetListByEmail(c.Request.Context(), email, o)
 	if err != nil {
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_LISTS_BY_OWNER, nil)
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_LISTS_BY_OWNER))
 		return
 	}
 
@@ -151,28 +114,19 @@ func GetShoppinglistsByParticipation(c *gin.Context) {
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	email, err := cache.GetEmailByJWT(token)
+	email, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	lists, err := models.GetListsByParticipant(email)
+	lists, err := stores.Shoppinglists.GetListsByParticipant(c.Request.Context(), email)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_LISTS_BY_OWNER, map[string]string{
-			"success": "false",
-			"error":   "error while getting lists by participation",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_LISTS_BY_OWNER))
 		return
 	}
 
@@ -188,67 +142,49 @@ func CreateShoppinglist(c *gin.Context) {
 	var f CreateShoppinglistForm
 
 	if err := c.BindJSON(&f); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
 	//TODO: Validate data some other way
 
 	/*if f.Participants == nil {
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.New(http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}*/
 
 	if f.Title == "" {
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.New(http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
 	token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}
 
-	owner, err := cache.GetEmailByJWT(token)
+	owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	userId, err := models.GetUserIDByEmail(owner)
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
 		return
 	}
 
 	lists := models.Shoppinglist{
-		ID:    util.RandomIntWithLength(9000000),
-		Title: f.Title,
-		Owner: owner,
+		ID:      util.RandomIntWithLength(9000000),
+		Title:   f.Title,
+		OwnerID: userId,
+		Owner:   owner,
 	}
 
-	if err := models.CreateList(lists, userId, true); err != nil {
-		appG.Response(http.StatusInternalServerError, e.ERROR_ADD_LIST_FAIL, map[string]string{
-			"success": "false",
-			"message": "Error while creating Shoppinglist",
-		})
+	if err := stores.Shoppinglists.CreateList(c.Request.Context(), lists, userId, true); err != nil {
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_ADD_LIST_FAIL))
 		return
 	}
 
@@ -266,29 +202,19 @@ func EditShoppinglist(c *gin.Context) {
 
 	var form EditShoppinglistForm
 	if err := c.BindJSON(&form); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
 	//TODO: Validate data some other way
 
 	if form.Owner == "" {
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.New(http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
 	if form.Title == "" {
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.New(http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
@@ -298,10 +224,9 @@ func EditShoppinglist(c *gin.Context) {
 		Owner: form.Owner,
 	}
 
-	err := models.EditList(id, list)
+	err := stores.Shoppinglists.EditList(c.Request.Context(), id, list)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_EDIT_LIST_FAIL, map[string]string{"success": "false"})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_EDIT_LIST_FAIL))
 		return
 	}
 
@@ -316,62 +241,42 @@ func DeleteShoppinglist(c *gin.Context) {
 
 	if valid.HasErrors() {
 		app.MarkErrors(valid.Errors)
-		appG.Response(http.StatusOK, e.INVALID_PARAMS, map[string]string{
-			"success": "false",
-			"message": "validation error",
-		})
+		appG.Error(e.New(http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
 	/*token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}*/
 
-	exists, err := models.ExistByID(id)
+	exists, err := stores.Shoppinglists.ExistByID(c.Request.Context(), id)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_CHECK_EXIST_LIST_FAIL, map[string]string{
-			"success": "false",
-			"message": "list does not exist",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_CHECK_EXIST_LIST_FAIL))
 		return
 	}
 
 	if !exists {
-		appG.Response(http.StatusOK, e.ERROR_LIST_DOES_NOT_EXIST, map[string]string{
-			"success": "false",
-			"message": "list does not exist",
-		})
+		appG.Error(models.ErrListNotFound)
 		return
 	}
 
-	/*owner, err := cache.GetEmailByJWT(token)
+	/*owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}
 
-	userId, err := models.GetUserIDByEmail(owner)
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), owner)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, nil)
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
 		return
 	}*/
 
-	err = models.DeleteList(id)
+	err = stores.Shoppinglists.DeleteList(c.Request.Context(), id)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_DELETE_LIST_FAIL, map[string]string{
-			"success": "false",
-			"message": "error deleting the list",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_DELETE_LIST_FAIL))
 		return
 	}
 
@@ -391,39 +296,25 @@ func AddItem(c *gin.Context) {
 
 	/*token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}*/
 
 	var form ItemRequest
 
 	if err := c.BindJSON(&form); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
 	if form.Title == "" {
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "title can't be empty",
-			"success": "false",
-		})
+		appG.Error(e.New(http.StatusBadRequest, e.INVALID_PARAMS))
 		return
 	}
 
-	/*owner, err := cache.GetEmailByJWT(token)
+	/*owner, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}*/
 
@@ -437,13 +328,9 @@ func AddItem(c *gin.Context) {
 		Bought:       false,
 	}
 
-	item, err := models.AddItem(*item)
+	item, err := stores.Items.AddItem(c.Request.Context(), *item)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while adding item",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_ADDING_ITEM))
 		return
 	}
 
@@ -463,11 +350,7 @@ func UpdateItem(c *gin.Context) {
 
 	var form UpdateItemRequest
 	if err := c.BindJSON(&form); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
@@ -479,13 +362,9 @@ func UpdateItem(c *gin.Context) {
 		Bought:       form.Bought,
 	}
 
-	err := models.UpdateItem(item)
+	err := stores.Items.UpdateItem(c.Request.Context(), item)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while updating item",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_EDITING_ITEM))
 		return
 	}
 
@@ -502,21 +381,13 @@ func UpdateItems(c *gin.Context) {
 	var form UpdateItemsRequest
 
 	if err := c.BindJSON(&form); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
-	err := models.UpdateItems(form.ParentListID, form.Items)
+	err := stores.Items.UpdateItems(c.Request.Context(), form.ParentListID, form.Items)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while updating items",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_EDITING_ITEM))
 		return
 	}
 
@@ -533,21 +404,13 @@ func DeleteItem(c *gin.Context) {
 	var form DeleteItemRequest
 
 	if err := c.BindJSON(&form); err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   "error while binding json to struct",
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
 		return
 	}
 
-	err := models.DeleteItem(form.ParentListId, form.ID)
+	err := stores.Items.DeleteItem(c.Request.Context(), form.ParentListId, form.ID)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-			"error":   "error while deleting item",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_DELETING_ITEM))
 		return
 	}
 
@@ -563,29 +426,19 @@ func GetListItems(c *gin.Context) {
 
 	/*token, err := util.GetCookie(c)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE, map[string]string{
-			"error":   err.Error(),
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_HTTPONLY_COOKIE))
 		return
 	}*/
 
-	/*email, err := cache.GetEmailByJWT(token)
+	/*email, err := cache.GetEmailByJWT(c.Request.Context(), token)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT, map[string]string{
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_EMAIL_BY_JWT))
 		return
 	}*/
 
-	items, err := models.GetItems(id)
+	items, err := stores.Items.GetItems(c.Request.Context(), id)
 	if err != nil {
-		log.Print(err)
-		appG.Response(http.StatusInternalServerError, e.ERROR_GETTING_LISTS_BY_OWNER, map[string]string{
-			"success": "false",
-		})
+		appG.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_LISTS_BY_OWNER))
 		return
 	}
 
@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/stretchr/testify/assert"
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/models/memory"
+	"github.com/urento/shoppinglist/pkg/app"
+	"github.com/urento/shoppinglist/pkg/e"
+)
+
+type itemsResponse struct {
+	Code int           `json:"code"`
+	Data []models.Item `json:"data"`
+}
+
+func TestItems(t *testing.T) {
+	ctx := context.Background()
+
+	gin.SetMode(gin.TestMode)
+
+	stores := memory.NewStores()
+	UseStores(stores)
+
+	email := "owner@gmail.com"
+	err := stores.Auth.CreateAccount(ctx, email, "owner", "WbHu9+kLr!3xZpQa", "")
+	if err != nil {
+		t.Fatalf("Error while creating account: %s", err)
+	}
+
+	userId, _ := stores.Auth.GetUserIDByEmail(ctx, email)
+	err = stores.Shoppinglists.CreateList(ctx, models.Shoppinglist{ID: 1, Title: "Groceries"}, userId, false)
+	if err != nil {
+		t.Fatalf("Error while creating shoppinglist: %s", err)
+	}
+
+	r := gin.New()
+	r.Use(app.Errors())
+	r.POST("/list/items", AddItem)
+	r.PUT("/item/:id", UpdateItem)
+	r.GET("/list/items/:id", GetListItems)
+	r.DELETE("/item", DeleteItem)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	getItems := func() []models.Item {
+		w := request(http.MethodGet, "/list/items/1", "")
+		Equal(t, http.StatusOK, w.Code)
+
+		var response itemsResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Error while parsing response: %s", err)
+		}
+		return response.Data
+	}
+
+	t.Run("TestAddItem", func(t *testing.T) {
+		w := request(http.MethodPost, "/list/items", `{"id": 1, "title": "Milk", "position": 1}`)
+
+		Equal(t, http.StatusOK, w.Code)
+
+		items := getItems()
+		Equal(t, 1, len(items))
+		Equal(t, "Milk", items[0].Title)
+		False(t, items[0].Bought)
+	})
+
+	t.Run("TestAddItemToMissingList", func(t *testing.T) {
+		w := request(http.MethodPost, "/list/items", `{"id": 2, "title": "Milk"}`)
+
+		Equal(t, http.StatusNotFound, w.Code)
+		Contains(t, w.Body.String(), `"code":`+strconv.Itoa(e.ERROR_LIST_DOES_NOT_EXIST))
+	})
+
+	t.Run("TestUpdateItem", func(t *testing.T) {
+		itemId := getItems()[0].ItemID
+
+		w := request(http.MethodPut, "/item/"+strconv.Itoa(itemId), `{"parentListId": 1, "title": "Oat Milk", "bought": true}`)
+
+		Equal(t, http.StatusOK, w.Code)
+
+		items := getItems()
+		Equal(t, "Oat Milk", items[0].Title)
+		True(t, items[0].Bought)
+	})
+
+	t.Run("TestDeleteItem", func(t *testing.T) {
+		itemId := getItems()[0].ItemID
+
+		w := request(http.MethodDelete, "/item", `{"id": `+strconv.Itoa(itemId)+`, "parent_list_id": 1}`)
+
+		Equal(t, http.StatusOK, w.Code)
+		Equal(t, 0, len(getItems()))
+	})
+}
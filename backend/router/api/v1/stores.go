@@ -0,0 +1,10 @@
+package api
+
+import "github.com/urento/shoppinglist/models"
+
+var stores = models.NewGormStores()
+
+// UseStores sets the stores the handlers read and write through, e.g. the in-memory ones in tests
+func UseStores(s models.Stores) {
+	stores = s
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/pkg/app"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/e"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"github.com/urento/shoppinglist/pkg/metrics"
+	"github.com/urento/shoppinglist/pkg/util"
+	"github.com/urento/shoppinglist/pkg/webauthn"
+)
+
+type WebAuthnRegistrationRequest struct {
+	Name       string          `json:"name"`
+	Credential json.RawMessage `json:"credential"`
+}
+
+type WebAuthnLoginRequest struct {
+	Email        string `json:"email"`
+	Passwordless bool   `json:"passwordless"`
+}
+
+type WebAuthnAssertionRequest struct {
+	Email      string          `json:"email"`
+	Credential json.RawMessage `json:"credential"`
+}
+
+func BeginWebAuthnRegistration(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	email, ok := emailFromCookie(&appGin)
+	if !ok {
+		return
+	}
+
+	options, err := webauthn.BeginRegistration(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_BEGINNING_WEBAUTHN_REGISTRATION))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, options)
+}
+
+func FinishWebAuthnRegistration(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	email, ok := emailFromCookie(&appGin)
+	if !ok {
+		return
+	}
+
+	var data WebAuthnRegistrationRequest
+
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
+		return
+	}
+
+	credential, err := webauthn.FinishRegistration(c.Request.Context(), email, data.Name, bytes.NewReader(data.Credential))
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_FINISHING_WEBAUTHN_REGISTRATION))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, credential)
+}
+
+func GetWebAuthnCredentials(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	email, ok := emailFromCookie(&appGin)
+	if !ok {
+		return
+	}
+
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
+		return
+	}
+
+	credentials, err := models.GetWebAuthnCredentials(c.Request.Context(), userId)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_GETTING_WEBAUTHN_CREDENTIALS))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, credentials)
+}
+
+func DeleteWebAuthnCredential(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.INVALID_PARAMS))
+		return
+	}
+
+	email, ok := emailFromCookie(&appGin)
+	if !ok {
+		return
+	}
+
+	userId, err := stores.Auth.GetUserIDByEmail(c.Request.Context(), email)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_RETRIEVING_USER_DATA))
+		return
+	}
+
+	err = models.DeleteWebAuthnCredential(c.Request.Context(), userId, id)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_DELETING_WEBAUTHN_CREDENTIAL))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{"success": "true"})
+}
+
+// BeginWebAuthnLogin starts the assertion either as second factor after Login or as passwordless login
+func BeginWebAuthnLogin(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	var data WebAuthnLoginRequest
+
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
+		return
+	}
+
+	if !data.Passwordless {
+		pending, err := cache.IsMFAPending(c.Request.Context(), data.Email)
+		if err != nil || !pending {
+			appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED))
+			return
+		}
+	}
+
+	options, err := webauthn.BeginLogin(c.Request.Context(), data.Email, data.Passwordless)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BEGINNING_WEBAUTHN_LOGIN))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, options)
+}
+
+func FinishWebAuthnLogin(c *gin.Context) {
+	appGin := app.Gin{C: c}
+
+	var data WebAuthnAssertionRequest
+
+	if err := c.BindJSON(&data); err != nil {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_BINDING_JSON_DATA))
+		return
+	}
+
+	email := data.Email
+
+	passwordless, err := webauthn.FinishLogin(c.Request.Context(), email, bytes.NewReader(data.Credential))
+	if err == nil && !passwordless {
+		pending, pendingErr := cache.ConsumeMFAPending(c.Request.Context(), email)
+		if pendingErr != nil || !pending {
+			appGin.Error(e.Wrap(pendingErr, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED))
+			return
+		}
+	}
+
+	metrics.ObserveLogin(models.LoginMethodWebAuthn, err == nil)
+	if recordErr := models.RecordLogin(c.Request.Context(), email, c.ClientIP(), c.Request.UserAgent(), models.LoginMethodWebAuthn, err == nil); recordErr != nil {
+		logging.Error(c, recordErr)
+	}
+
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_VERIFYING_WEBAUTHN_ASSERTION).WithDetail("verified", "false"))
+		return
+	}
+
+	//deactivated accounts can only be reactivated with the password
+	deactivated, err := stores.Auth.IsDeactivated(c.Request.Context(), email)
+	if err != nil || deactivated {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_AUTH).WithDetail("verified", "false"))
+		return
+	}
+
+	token, err := util.GenerateToken(c.Request.Context(), email, false)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_AUTH_TOKEN))
+		return
+	}
+
+	err = SetCookie(c, token)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusInternalServerError, e.ERROR_SETTING_SESSION_TOKEN).WithDetail("verified", "false"))
+		return
+	}
+
+	appGin.Response(http.StatusOK, e.SUCCESS, map[string]string{
+		"success":  "true",
+		"verified": "true",
+		"token":    token,
+	})
+}
+
+func emailFromCookie(appGin *app.Gin) (string, bool) {
+	token, err := GetCookie(appGin.C)
+	if err != nil {
+		appGin.Error(e.Wrap(err, http.StatusUnauthorized, e.ERROR_NOT_AUTHORIZED))
+		return "", false
+	}
+
+	email, err := cache.GetEmailByJWT(appGin.C.Request.Context(), token)
+	if err != nil || len(email) <= 0 {
+		appGin.Error(e.Wrap(err, http.StatusBadRequest, e.ERROR_GETTING_EMAIL_BY_JWT))
+		return "", false
+	}
+
+	return email, true
+}
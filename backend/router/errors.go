@@ -0,0 +1,15 @@
+package routers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/pkg/app"
+	"github.com/urento/shoppinglist/pkg/e"
+)
+
+// errorCodes lists every code with its name and message, so the frontend can localize the messages
+func errorCodes(c *gin.Context) {
+	appG := app.Gin{C: c}
+	appG.Response(http.StatusOK, e.SUCCESS, e.Registry())
+}
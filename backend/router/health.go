@@ -0,0 +1,62 @@
+package routers
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// draining is set once the server shuts down, accessed atomically
+var draining int32
+
+// Drain makes readyz fail from now on, so no new traffic is routed to the instance while it shuts down
+func Drain() {
+	atomic.StoreInt32(&draining, 1)
+}
+
+// check pings one of the dependencies of the server
+type check func(ctx context.Context) error
+
+// healthz only reports that the process is able to serve requests, a dependency being down must not restart it
+func healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readyz reports 503 while one of the checks fails or the server shuts down, so no traffic is routed to the instance.
+// The errors of the checks are only logged, they can contain addresses of the dependencies
+func readyz(checks map[string]check) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if atomic.LoadInt32(&draining) == 1 {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting down"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		status := http.StatusOK
+		results := make(map[string]string, len(checks))
+		for name, check := range checks {
+			if err := check(ctx); err != nil {
+				status = http.StatusServiceUnavailable
+				results[name] = "unavailable"
+				logging.FromGin(c).Warn("readiness check failed", zap.String("check", name), zap.Error(err))
+				continue
+			}
+			results[name] = "ok"
+		}
+
+		if status == http.StatusOK {
+			c.JSON(status, gin.H{"status": "ok", "checks": results})
+			return
+		}
+		c.JSON(status, gin.H{"status": "unavailable", "checks": results})
+	}
+}
@@ -0,0 +1,62 @@
+package routers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ok := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	request := func(handler gin.HandlerFunc) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.GET("/", handler)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w
+	}
+
+	t.Run("TestHealthz", func(t *testing.T) {
+		w := request(healthz)
+
+		Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("TestReady", func(t *testing.T) {
+		w := request(readyz(map[string]check{"postgres": ok, "redis": ok}))
+
+		Equal(t, http.StatusOK, w.Code)
+		Contains(t, w.Body.String(), `"redis":"ok"`)
+	})
+
+	t.Run("TestNotReady", func(t *testing.T) {
+		w := request(readyz(map[string]check{"postgres": ok, "redis": down}))
+
+		Equal(t, http.StatusServiceUnavailable, w.Code)
+		Contains(t, w.Body.String(), `"postgres":"ok"`)
+		Contains(t, w.Body.String(), `"redis":"unavailable"`)
+		NotContains(t, w.Body.String(), "connection refused")
+	})
+
+	t.Run("TestDraining", func(t *testing.T) {
+		t.Cleanup(func() {
+			atomic.StoreInt32(&draining, 0)
+		})
+		Drain()
+
+		w := request(readyz(map[string]check{"postgres": ok, "redis": ok}))
+
+		Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
@@ -5,41 +5,83 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/urento/shoppinglist/middleware/deadline"
 	"github.com/urento/shoppinglist/middleware/jwt"
 	"github.com/urento/shoppinglist/middleware/ratelimiter"
+	"github.com/urento/shoppinglist/middleware/reauth"
+	"github.com/urento/shoppinglist/middleware/requestid"
+	"github.com/urento/shoppinglist/models"
+	"github.com/urento/shoppinglist/pkg/app"
+	"github.com/urento/shoppinglist/pkg/cache"
+	"github.com/urento/shoppinglist/pkg/config"
+	"github.com/urento/shoppinglist/pkg/logging"
+	"github.com/urento/shoppinglist/pkg/metrics"
+	"github.com/urento/shoppinglist/pkg/tracing"
 	"github.com/urento/shoppinglist/router/api/v1"
 	notifications_v1 "github.com/urento/shoppinglist/router/api/v1/notifications"
 	v1 "github.com/urento/shoppinglist/router/api/v1/shoppinglist"
 )
 
-func InitRouter() *gin.Engine {
+func InitRouter(cfg *config.Config, stores models.Stores) *gin.Engine {
+	api.UseStores(stores)
+	v1.UseStores(stores)
+	notifications_v1.UseStores(stores)
+
 	gin.SetMode(gin.DebugMode)
 	r := gin.New()
-	// maybe remove because gin attaches them automatically
-	r.Use(gin.Logger())
-	r.Use(gin.Recovery())
+	r.Use(requestid.RequestID())
+	r.Use(logging.Recovery())
+
+	// registered before the rate limiter, so probes are never limited
+	r.GET("/healthz", healthz)
+	r.GET("/readyz", readyz(map[string]check{
+		"postgres": models.Ping,
+		"redis":    cache.Ping,
+	}))
 
-	r.Use(ratelimiter.Ratelimiter())
+	r.Use(tracing.Middleware(cfg.Tracing.ServiceName)...)
+	r.Use(logging.Middleware())
+	r.Use(metrics.Middleware())
+	r.Use(deadline.Deadline(cfg.Server.RequestTimeout()))
+	r.Use(app.Errors())
+
+	r.Use(ratelimiter.Ratelimiter(int64(cfg.RateLimit.Requests), cfg.RateLimit.Window()))
 	r.Use(cors.New(cors.Config{
 		AllowMethods:     []string{"OPTIONS", "PUT", "GET", "POST", "DELETE", "PATCH"},
-		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type"},
+		AllowOrigins:     cfg.CORS.AllowOrigins,
+		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", requestid.Header},
 		AllowCredentials: true,
 		MaxAge:           9 * time.Hour,
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", requestid.Header},
 	}))
 
+	r.GET("/api/errors", errorCodes)
+
 	r.POST("/api/auth", api.Login)
 	r.POST("/api/auth/register", api.CreateAccount)
+	r.POST("/api/auth/unlock/request", api.RequestUnlock)
+	r.POST("/api/auth/unlock", api.Unlock)
 
 	apiv1 := r.Group("/api/v1")
 	apiv1.Use(jwt.JWT())
+	apiv1.Use(reauth.RecentAuth())
 
 	apiv1.POST("/auth/check", api.Check)
+	apiv1.POST("/auth/reauth", api.Reauthenticate)
 	apiv1.POST("/auth/resetpassword", api.ResetPasswordFromUser)
 	apiv1.GET("/auth/user", api.GetUser)
 	apiv1.POST("/auth/logout", api.Logout)
 	apiv1.POST("/auth/update", api.UpdateUser)
+	apiv1.POST("/auth/email", api.RequestEmailChange)
+	r.POST("/api/v1/auth/email/confirm", api.ConfirmEmailChange)
+	apiv1.POST("/auth/deactivate", api.DeactivateAccount)
+	apiv1.GET("/auth/logins", api.GetLoginEvents)
+	apiv1.POST("/auth/webauthn/register/begin", api.BeginWebAuthnRegistration)
+	apiv1.POST("/auth/webauthn/register/finish", api.FinishWebAuthnRegistration)
+	apiv1.GET("/auth/webauthn/credentials", api.GetWebAuthnCredentials)
+	apiv1.DELETE("/auth/webauthn/credentials/:id", api.DeleteWebAuthnCredential)
+	r.POST("/api/v1/auth/webauthn/login/begin", api.BeginWebAuthnLogin)
+	r.POST("/api/v1/auth/webauthn/login/finish", api.FinishWebAuthnLogin)
 	//apiv1.POST("/auth/invalidate", api.InvalidateSpecificJWTToken) //TODO: Test this and add this to the frontend
 
 	apiv1.GET("/lists", v1.GetShoppinglists)
@@ -63,10 +105,13 @@ func InitRouter() *gin.Engine {
 	apiv1.DELETE("/participant/requests/denyall", v1.DeleteParticipant)
 	apiv1.POST("/participant/list/leave", v1.LeaveShoppinglsit)
 
-	apiv1.POST("/resetpassword/verifyid", api.VerifyVerificationId)
-	apiv1.POST("/resetpassword", api.SendResetPassword)
+	r.POST("/api/v1/resetpassword/verifyid", api.VerifyVerificationId)
+	r.POST("/api/v1/resetpassword", api.SendResetPassword)
+	r.POST("/api/v1/resetpassword/reset", api.ResetPasswordWithToken)
 	apiv1.POST("/resetpassword/changepassword", api.ChangePassword)
 
+	apiv1.GET("/admin/hashreport", api.GetHashParamsReport)
+
 	apiv1.GET("/notifications/n/hasunread", notifications_v1.HasUnreadNotifications)
 	apiv1.GET("/notifications", notifications_v1.GetNotifications)
 	apiv1.GET("/notification/:notification_id", notifications_v1.GetNotification)
@@ -80,7 +125,16 @@ func InitRouter() *gin.Engine {
 	apiv1.POST("/backupcodes/generate", api.GenerateCodes)
 
 	apiv1.POST("/twofactorauthentication", api.UpdateTwoFactorAuthentication)
+	apiv1.POST("/twofactorauthentication/confirm", api.ConfirmTwoFactorAuthentication)
 	r.POST("/twofactorauthentication/verify", api.VerifyTwoFactorAuthentication)
 
 	return r
 }
+
+// InitMetricsRouter serves /metrics on the internal listen address, the api router doesn't expose it
+func InitMetricsRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(logging.Recovery())
+	r.GET("/metrics", metrics.Handler())
+	return r
+}